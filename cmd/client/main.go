@@ -41,6 +41,12 @@ var (
 	blocksPerPartition = flag.Uint64("blocks_per_partition", 100, "number of blocks per partition")
 	blocksPerRecord    = flag.Uint64("blocks_per_record", 10, "number of blocks per record")
 	table              = flag.String("table", "", "table name")
+	compression        = flag.String("compression", "", "one of none, lz4_frame, or zstd")
+	columns            = flag.String("columns", "", "comma-separated list of columns, e.g. hash,receipt.status")
+	planningMode       = flag.String("planning_mode", "", "one of blocks, rows, or bytes")
+	rowsPerPartition   = flag.Uint64("rows_per_partition", 0, "number of rows per partition when planning_mode is rows")
+	bytesPerPartition  = flag.Uint64("bytes_per_partition", 0, "number of bytes per partition when planning_mode is bytes")
+	partitionStrategy  = flag.String("partition_strategy", "", "one of height, sequence, day, or hour")
 
 	logger *zap.Logger
 )
@@ -143,7 +149,10 @@ func main() {
 					EndSequence:        int64(*end),
 					EventsPerPartition: *blocksPerPartition,
 					EventsPerRecord:    *blocksPerRecord,
+					Compression:        *compression,
 					Table:              *table,
+					Columns:            parseColumns(*columns),
+					PartitionStrategy:  *partitionStrategy,
 				},
 			},
 		}
@@ -155,7 +164,13 @@ func main() {
 					EndHeight:          *end,
 					BlocksPerPartition: *blocksPerPartition,
 					BlocksPerRecord:    *blocksPerRecord,
+					Compression:        *compression,
 					Table:              *table,
+					Columns:            parseColumns(*columns),
+					PlanningMode:       *planningMode,
+					RowsPerPartition:   *rowsPerPartition,
+					BytesPerPartition:  *bytesPerPartition,
+					PartitionStrategy:  *partitionStrategy,
 				},
 			},
 		}
@@ -240,3 +255,11 @@ func newFlightDescriptor(cmd proto.Message) (*flight.FlightDescriptor, error) {
 		Cmd:  data,
 	}, nil
 }
+
+func parseColumns(columns string) []string {
+	if columns == "" {
+		return nil
+	}
+
+	return strings.Split(columns, ",")
+}
@@ -0,0 +1,445 @@
+package chainstorage
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hashicorp/golang-lru/v2/simplelru"
+	"github.com/uber-go/tally/v4"
+	"golang.org/x/sync/singleflight"
+	"golang.org/x/xerrors"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+	"github.com/coinbase/chainstorage/sdk"
+
+	"github.com/coinbase/chainsformer/internal/config"
+)
+
+type (
+	// cachedSession is a Session whose client and parser share a cache of the raw and parsed blocks,
+	// so that the tables reading the same blocks only fetch and parse them once.
+	// Only the blocks of irreversible heights are cached.
+	cachedSession struct {
+		Session
+		client *cachedClient
+		parser *cachedParser
+	}
+
+	cachedClient struct {
+		sdk.Client
+		cache *blockCache
+	}
+
+	cachedParser struct {
+		sdk.Parser
+		cache *blockCache
+	}
+
+	blockCache struct {
+		session Session
+		raw     *sizedCache[blockKey, *chainstorageapi.Block]
+		parsed  *sizedCache[parsedBlockKey, proto.Message]
+
+		// canonicalMu guards canonicalHashes, which indexes the hash of the canonical raw blocks by tag and height,
+		// so that the blocks fetched by range or without hash are looked up by their full key.
+		// An entry is removed once its raw block is evicted.
+		canonicalMu     sync.Mutex
+		canonicalHashes map[heightKey]string
+
+		// mu only guards the cached tip, which is refreshed outside the lock.
+		mu sync.Mutex
+		// tipHeight is the irreversible tip, i.e. the tip minus the irreversible distance.
+		tipHeight          uint64
+		tipHeightUpdatedAt time.Time
+		tipHeightGroup     singleflight.Group
+	}
+
+	blockKey struct {
+		tag    uint32
+		height uint64
+		hash   string
+	}
+
+	heightKey struct {
+		tag    uint32
+		height uint64
+	}
+
+	parsedBlockKey struct {
+		blockKey
+		format parsedBlockFormat
+	}
+
+	parsedBlockFormat int
+
+	// sizedCache is a thread-safe LRU cache bounded by the total size of its values.
+	sizedCache[K comparable, V any] struct {
+		mu              sync.Mutex
+		lru             *simplelru.LRU[K, sizedValue[V]]
+		onEvict         func(key K)
+		size            int64
+		maxSize         int64
+		counterHit      tally.Counter
+		counterMiss     tally.Counter
+		counterEviction tally.Counter
+		gaugeSize       tally.Gauge
+	}
+
+	sizedValue[V any] struct {
+		value V
+		size  int64
+	}
+)
+
+const (
+	parsedBlockFormatNative parsedBlockFormat = iota
+	parsedBlockFormatRosetta
+)
+
+const (
+	// tipHeightRefreshInterval bounds how often the irreversible tip is refreshed
+	// while looking up the blocks above it.
+	tipHeightRefreshInterval = time.Second
+	tipHeightGroupKey        = "tip_height"
+)
+
+func newCachedSession(session Session, cfg *config.BlockCacheConfig, metrics tally.Scope) (*cachedSession, error) {
+	scope := metrics.SubScope("block_cache")
+	cache := &blockCache{
+		session:         session,
+		canonicalHashes: make(map[heightKey]string),
+	}
+
+	raw, err := newSizedCache[blockKey, *chainstorageapi.Block](cfg.GetMaxRawBlockBytes(), scope.Tagged(map[string]string{"cache": "raw"}), cache.removeCanonicalHash)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create raw block cache: %w", err)
+	}
+
+	parsed, err := newSizedCache[parsedBlockKey, proto.Message](cfg.GetMaxParsedBlockBytes(), scope.Tagged(map[string]string{"cache": "parsed"}), nil)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create parsed block cache: %w", err)
+	}
+
+	cache.raw = raw
+	cache.parsed = parsed
+
+	return &cachedSession{
+		Session: session,
+		client: &cachedClient{
+			Client: session.Client(),
+			cache:  cache,
+		},
+		parser: &cachedParser{
+			Parser: session.Parser(),
+			cache:  cache,
+		},
+	}, nil
+}
+
+func (s *cachedSession) Client() sdk.Client {
+	return s.client
+}
+
+func (s *cachedSession) Parser() sdk.Parser {
+	return s.parser
+}
+
+func (c *cachedClient) GetBlock(ctx context.Context, height uint64, hash string) (*chainstorageapi.Block, error) {
+	return c.cache.getBlock(ctx, blockKey{tag: c.GetTag(), height: height, hash: hash}, func() (*chainstorageapi.Block, error) {
+		return c.Client.GetBlock(ctx, height, hash)
+	})
+}
+
+func (c *cachedClient) GetBlockWithTag(ctx context.Context, tag uint32, height uint64, hash string) (*chainstorageapi.Block, error) {
+	return c.cache.getBlock(ctx, blockKey{tag: tag, height: height, hash: hash}, func() (*chainstorageapi.Block, error) {
+		return c.Client.GetBlockWithTag(ctx, tag, height, hash)
+	})
+}
+
+func (c *cachedClient) GetBlocksByRange(ctx context.Context, startHeight uint64, endHeight uint64) ([]*chainstorageapi.Block, error) {
+	return c.cache.getBlocksByRange(ctx, c.GetTag(), startHeight, endHeight, func(startHeight uint64) ([]*chainstorageapi.Block, error) {
+		return c.Client.GetBlocksByRange(ctx, startHeight, endHeight)
+	})
+}
+
+func (c *cachedClient) GetBlocksByRangeWithTag(ctx context.Context, tag uint32, startHeight uint64, endHeight uint64) ([]*chainstorageapi.Block, error) {
+	return c.cache.getBlocksByRange(ctx, tag, startHeight, endHeight, func(startHeight uint64) ([]*chainstorageapi.Block, error) {
+		return c.Client.GetBlocksByRangeWithTag(ctx, tag, startHeight, endHeight)
+	})
+}
+
+func (p *cachedParser) ParseNativeBlock(ctx context.Context, rawBlock *chainstorageapi.Block) (*chainstorageapi.NativeBlock, error) {
+	res, err := p.cache.getParsedBlock(ctx, rawBlock, parsedBlockFormatNative, func() (proto.Message, error) {
+		return p.Parser.ParseNativeBlock(ctx, rawBlock)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*chainstorageapi.NativeBlock), nil
+}
+
+func (p *cachedParser) ParseRosettaBlock(ctx context.Context, rawBlock *chainstorageapi.Block) (*chainstorageapi.RosettaBlock, error) {
+	res, err := p.cache.getParsedBlock(ctx, rawBlock, parsedBlockFormatRosetta, func() (proto.Message, error) {
+		return p.Parser.ParseRosettaBlock(ctx, rawBlock)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*chainstorageapi.RosettaBlock), nil
+}
+
+// getBlock returns the block of the key, which is the canonical block of its height if its hash is empty.
+func (c *blockCache) getBlock(ctx context.Context, key blockKey, fetch func() (*chainstorageapi.Block, error)) (*chainstorageapi.Block, error) {
+	if !c.isIrreversible(ctx, key.height) {
+		return fetch()
+	}
+
+	if block, ok := c.getCachedBlock(key); ok {
+		return block, nil
+	}
+
+	block, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	c.addBlock(key.tag, block, key.hash == "")
+	return block, nil
+}
+
+// getBlocksByRange returns the blocks in [startHeight, endHeight).
+// The blocks following the longest cached prefix of the range are fetched from fetch(startHeight).
+func (c *blockCache) getBlocksByRange(
+	ctx context.Context,
+	tag uint32,
+	startHeight uint64,
+	endHeight uint64,
+	fetch func(startHeight uint64) ([]*chainstorageapi.Block, error),
+) ([]*chainstorageapi.Block, error) {
+	if endHeight <= startHeight || !c.isIrreversible(ctx, endHeight-1) {
+		return fetch(startHeight)
+	}
+
+	blocks := make([]*chainstorageapi.Block, 0, endHeight-startHeight)
+	for height := startHeight; height < endHeight; height++ {
+		block, ok := c.getCachedBlock(blockKey{tag: tag, height: height})
+		if !ok {
+			break
+		}
+
+		blocks = append(blocks, block)
+	}
+
+	if len(blocks) == int(endHeight-startHeight) {
+		return blocks, nil
+	}
+
+	fetched, err := fetch(startHeight + uint64(len(blocks)))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, block := range fetched {
+		c.addBlock(tag, block, true)
+	}
+
+	return append(blocks, fetched...), nil
+}
+
+// getCachedBlock returns the cached block of the key.
+// The blocks without hash are looked up by the hash of the canonical block of their height.
+func (c *blockCache) getCachedBlock(key blockKey) (*chainstorageapi.Block, bool) {
+	if key.hash == "" {
+		c.canonicalMu.Lock()
+		hash, ok := c.canonicalHashes[heightKey{tag: key.tag, height: key.height}]
+		c.canonicalMu.Unlock()
+		if !ok {
+			c.raw.counterMiss.Inc(1)
+			return nil, false
+		}
+
+		key.hash = hash
+	}
+
+	return c.raw.get(key)
+}
+
+// addBlock caches the block by the hash of its metadata.
+// If canonical is set, the block is also indexed as the canonical block of its height.
+func (c *blockCache) addBlock(tag uint32, block *chainstorageapi.Block, canonical bool) {
+	metadata := block.GetMetadata()
+	key := blockKey{tag: tag, height: metadata.GetHeight(), hash: metadata.GetHash()}
+	if !canonical {
+		c.raw.add(key, block, int64(proto.Size(block)))
+		return
+	}
+
+	// The block is indexed before it is cached, so that its eviction, which may happen as soon as it is cached,
+	// always removes the index. Indexing it after it is cached could otherwise leave a stale index behind.
+	c.canonicalMu.Lock()
+	c.canonicalHashes[heightKey{tag: key.tag, height: key.height}] = key.hash
+	c.canonicalMu.Unlock()
+
+	// The block is not indexed if it is too large to be cached.
+	if !c.raw.add(key, block, int64(proto.Size(block))) {
+		c.removeCanonicalHash(key)
+	}
+}
+
+// removeCanonicalHash removes the canonical hash indexing the evicted raw block, if any.
+func (c *blockCache) removeCanonicalHash(key blockKey) {
+	c.canonicalMu.Lock()
+	defer c.canonicalMu.Unlock()
+
+	k := heightKey{tag: key.tag, height: key.height}
+	if hash, ok := c.canonicalHashes[k]; ok && hash == key.hash {
+		delete(c.canonicalHashes, k)
+	}
+}
+
+func (c *blockCache) getParsedBlock(ctx context.Context, rawBlock *chainstorageapi.Block, format parsedBlockFormat, parse func() (proto.Message, error)) (proto.Message, error) {
+	metadata := rawBlock.GetMetadata()
+	if metadata == nil || !c.isIrreversible(ctx, metadata.GetHeight()) {
+		return parse()
+	}
+
+	key := parsedBlockKey{
+		blockKey: blockKey{
+			tag:    metadata.GetTag(),
+			height: metadata.GetHeight(),
+			hash:   metadata.GetHash(),
+		},
+		format: format,
+	}
+	if res, ok := c.parsed.get(key); ok {
+		return res, nil
+	}
+
+	res, err := parse()
+	if err != nil {
+		return nil, err
+	}
+
+	c.parsed.add(key, res, int64(proto.Size(res)))
+	return res, nil
+}
+
+// isIrreversible returns whether the height is below the irreversible tip.
+// Since the irreversible tip only moves forward, it is only refreshed for the heights above it.
+func (c *blockCache) isIrreversible(ctx context.Context, height uint64) bool {
+	c.mu.Lock()
+	tipHeight, tipHeightUpdatedAt := c.tipHeight, c.tipHeightUpdatedAt
+	c.mu.Unlock()
+
+	if height < tipHeight {
+		return true
+	}
+
+	if time.Since(tipHeightUpdatedAt) < tipHeightRefreshInterval {
+		return false
+	}
+
+	tipHeight, err := c.refreshTipHeight(ctx)
+	if err != nil {
+		// Caching is best effort: the blocks are fetched without the cache until the tip is refreshed.
+		return false
+	}
+
+	return height < tipHeight
+}
+
+// refreshTipHeight fetches the irreversible tip without holding the lock.
+// The concurrent refreshes share the same fetch.
+func (c *blockCache) refreshTipHeight(ctx context.Context) (uint64, error) {
+	res, err, _ := c.tipHeightGroup.Do(tipHeightGroupKey, func() (interface{}, error) {
+		tipHeight, err := c.session.GetTipHeight(ctx)
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.tipHeightUpdatedAt = time.Now()
+		if err != nil {
+			return nil, err
+		}
+
+		if tipHeight > c.tipHeight {
+			c.tipHeight = tipHeight
+		}
+
+		return c.tipHeight, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return res.(uint64), nil
+}
+
+// newSizedCache creates a cache of maxSize bytes. If set, onEvict is called with the key of every removed value.
+func newSizedCache[K comparable, V any](maxSize int64, scope tally.Scope, onEvict func(key K)) (*sizedCache[K, V], error) {
+	c := &sizedCache[K, V]{
+		maxSize:         maxSize,
+		onEvict:         onEvict,
+		counterHit:      scope.Counter("hit"),
+		counterMiss:     scope.Counter("miss"),
+		counterEviction: scope.Counter("eviction"),
+		gaugeSize:       scope.Gauge("bytes"),
+	}
+
+	// The number of entries is unbounded, since the cache is bounded by size instead.
+	lru, err := simplelru.NewLRU[K, sizedValue[V]](math.MaxInt, func(key K, value sizedValue[V]) {
+		c.size -= value.size
+		if c.onEvict != nil {
+			c.onEvict(key)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.lru = lru
+	return c, nil
+}
+
+func (c *sizedCache[K, V]) get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	res, ok := c.lru.Get(key)
+	if !ok {
+		c.counterMiss.Inc(1)
+		return res.value, false
+	}
+
+	c.counterHit.Inc(1)
+	return res.value, true
+}
+
+// add adds the value unless it is larger than the cache, evicting the least recently used values to make room for it.
+// It returns whether the value was added.
+func (c *sizedCache[K, V]) add(key K, value V, size int64) bool {
+	if size > c.maxSize {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// The replaced value is not evicted.
+	if old, ok := c.lru.Peek(key); ok {
+		c.size -= old.size
+	}
+	c.lru.Add(key, sizedValue[V]{value: value, size: size})
+	c.size += size
+	for c.size > c.maxSize {
+		c.lru.RemoveOldest()
+		c.counterEviction.Inc(1)
+	}
+
+	c.gaugeSize.Update(float64(c.size))
+	return true
+}
@@ -0,0 +1,315 @@
+package chainstorage
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally/v4"
+	"go.uber.org/mock/gomock"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+	sdkmocks "github.com/coinbase/chainstorage/sdk/mocks"
+
+	"github.com/coinbase/chainsformer/internal/config"
+	"github.com/coinbase/chainsformer/internal/utils/testutil"
+)
+
+type (
+	// blockingTipSession is a Session whose GetTipHeight blocks until released.
+	blockingTipSession struct {
+		Session
+		started chan struct{}
+		release chan struct{}
+	}
+
+	blockCacheTestSuite struct {
+		suite.Suite
+		ctrl       *gomock.Controller
+		session    *cachedSession
+		sdkSession *sdkmocks.MockSession
+		client     *sdkmocks.MockClient
+		parser     *sdkmocks.MockParser
+		scope      tally.TestScope
+	}
+)
+
+const (
+	// testTipHeight is the irreversible tip of the test chain.
+	testTipHeight = uint64(100)
+)
+
+func TestBlockCacheTestSuite(t *testing.T) {
+	suite.Run(t, new(blockCacheTestSuite))
+}
+
+func (s *blockCacheTestSuite) SetupTest() {
+	s.ctrl = gomock.NewController(s.T())
+	s.sdkSession = sdkmocks.NewMockSession(s.ctrl)
+	s.client = sdkmocks.NewMockClient(s.ctrl)
+	s.parser = sdkmocks.NewMockParser(s.ctrl)
+	s.sdkSession.EXPECT().Client().Return(s.client).AnyTimes()
+	s.sdkSession.EXPECT().Parser().Return(s.parser).AnyTimes()
+	s.client.EXPECT().GetTag().Return(uint32(1)).AnyTimes()
+	s.client.EXPECT().GetStaticChainMetadata(gomock.Any(), gomock.Any()).
+		Return(&chainstorageapi.GetChainMetadataResponse{IrreversibleDistance: 10}, nil).AnyTimes()
+	s.client.EXPECT().GetLatestBlock(gomock.Any()).Return(testTipHeight+10, nil).AnyTimes()
+
+	s.scope = tally.NewTestScope("", nil)
+	s.session = s.newCachedSession(&config.BlockCacheConfig{})
+}
+
+func (s *blockCacheTestSuite) TearDownTest() {
+	s.ctrl.Finish()
+}
+
+func (s *blockCacheTestSuite) TestGetBlocksByRange_Irreversible() {
+	require := testutil.Require(s.T())
+
+	s.client.EXPECT().GetBlocksByRange(gomock.Any(), uint64(10), uint64(15)).
+		Return(testBlocks(10, 15), nil)
+	s.client.EXPECT().GetBlocksByRange(gomock.Any(), uint64(15), uint64(20)).
+		Return(testBlocks(15, 20), nil)
+
+	blocks, err := s.session.Client().GetBlocksByRange(context.Background(), 10, 15)
+	require.NoError(err)
+	require.Equal(5, len(blocks))
+
+	// Only the blocks following the cached ones are fetched.
+	blocks, err = s.session.Client().GetBlocksByRange(context.Background(), 10, 20)
+	require.NoError(err)
+	require.Equal(10, len(blocks))
+	for i, block := range blocks {
+		require.Equal(uint64(10+i), block.GetMetadata().GetHeight())
+	}
+
+	blocks, err = s.session.Client().GetBlocksByRange(context.Background(), 12, 18)
+	require.NoError(err)
+	require.Equal(6, len(blocks))
+	require.Equal(uint64(12), blocks[0].GetMetadata().GetHeight())
+	require.Equal(int64(5+6), s.counter("hit", "raw"))
+}
+
+func (s *blockCacheTestSuite) TestGetBlocksByRange_Reversible() {
+	require := testutil.Require(s.T())
+
+	s.client.EXPECT().GetBlocksByRange(gomock.Any(), testTipHeight-1, testTipHeight+1).
+		Return(testBlocks(testTipHeight-1, testTipHeight+1), nil).
+		Times(2)
+
+	for i := 0; i < 2; i++ {
+		blocks, err := s.session.Client().GetBlocksByRange(context.Background(), testTipHeight-1, testTipHeight+1)
+		require.NoError(err)
+		require.Equal(2, len(blocks))
+	}
+	require.Equal(int64(0), s.counter("hit", "raw"))
+}
+
+func (s *blockCacheTestSuite) TestGetBlockWithTag() {
+	require := testutil.Require(s.T())
+
+	block := testBlock(10)
+	s.client.EXPECT().GetBlockWithTag(gomock.Any(), uint32(2), uint64(10), "0xa").Return(block, nil)
+
+	for i := 0; i < 2; i++ {
+		actual, err := s.session.Client().GetBlockWithTag(context.Background(), 2, 10, "0xa")
+		require.NoError(err)
+		require.Equal(block, actual)
+	}
+	require.Equal(int64(1), s.counter("hit", "raw"))
+	require.Equal(int64(1), s.counter("miss", "raw"))
+}
+
+func (s *blockCacheTestSuite) TestGetBlock_SharedWithRange() {
+	require := testutil.Require(s.T())
+
+	s.client.EXPECT().GetBlocksByRange(gomock.Any(), uint64(10), uint64(12)).
+		Return(testBlocks(10, 12), nil)
+	orphan := testBlock(10)
+	orphan.Metadata.Hash = "0xb"
+	s.client.EXPECT().GetBlockWithTag(gomock.Any(), uint32(1), uint64(10), "0xb").Return(orphan, nil)
+	s.client.EXPECT().GetBlock(gomock.Any(), uint64(12), "").Return(testBlock(12), nil)
+
+	_, err := s.session.Client().GetBlocksByRange(context.Background(), 10, 12)
+	require.NoError(err)
+
+	// The blocks fetched by range are cached by hash, and shared with the lookups by hash or canonical height.
+	block, err := s.session.Client().GetBlockWithTag(context.Background(), 1, 10, "0xa")
+	require.NoError(err)
+	require.Equal("0xa", block.GetMetadata().GetHash())
+	block, err = s.session.Client().GetBlock(context.Background(), 11, "")
+	require.NoError(err)
+	require.Equal(uint64(11), block.GetMetadata().GetHeight())
+
+	// The lookups of another hash are not served from the canonical block.
+	block, err = s.session.Client().GetBlockWithTag(context.Background(), 1, 10, "0xb")
+	require.NoError(err)
+	require.Equal("0xb", block.GetMetadata().GetHash())
+
+	// The canonical blocks fetched without hash are shared with the range lookups.
+	_, err = s.session.Client().GetBlock(context.Background(), 12, "")
+	require.NoError(err)
+	blocks, err := s.session.Client().GetBlocksByRange(context.Background(), 10, 13)
+	require.NoError(err)
+	require.Equal(3, len(blocks))
+	require.Equal("0xa", blocks[0].GetMetadata().GetHash())
+	require.Equal(int64(5), s.counter("hit", "raw"))
+}
+
+func (s *blockCacheTestSuite) TestParseNativeBlock() {
+	require := testutil.Require(s.T())
+
+	nativeBlock := &chainstorageapi.NativeBlock{Height: 10}
+	rosettaBlock := &chainstorageapi.RosettaBlock{}
+	s.parser.EXPECT().ParseNativeBlock(gomock.Any(), gomock.Any()).Return(nativeBlock, nil)
+	s.parser.EXPECT().ParseRosettaBlock(gomock.Any(), gomock.Any()).Return(rosettaBlock, nil)
+
+	for i := 0; i < 2; i++ {
+		actualNative, err := s.session.Parser().ParseNativeBlock(context.Background(), testBlock(10))
+		require.NoError(err)
+		require.Equal(nativeBlock, actualNative)
+
+		actualRosetta, err := s.session.Parser().ParseRosettaBlock(context.Background(), testBlock(10))
+		require.NoError(err)
+		require.Equal(rosettaBlock, actualRosetta)
+	}
+	require.Equal(int64(2), s.counter("hit", "parsed"))
+}
+
+func (s *blockCacheTestSuite) TestEviction() {
+	require := testutil.Require(s.T())
+
+	// Room for two blocks only.
+	s.session = s.newCachedSession(&config.BlockCacheConfig{
+		MaxRawBlockBytes: int64(2*proto.Size(testBlock(10)) + 1),
+	})
+	s.client.EXPECT().GetBlocksByRange(gomock.Any(), uint64(10), uint64(13)).
+		Return(testBlocks(10, 13), nil)
+	s.client.EXPECT().GetBlocksByRange(gomock.Any(), uint64(10), uint64(11)).
+		Return(testBlocks(10, 11), nil)
+
+	_, err := s.session.Client().GetBlocksByRange(context.Background(), 10, 13)
+	require.NoError(err)
+	require.Equal(int64(1), s.counter("eviction", "raw"))
+
+	_, err = s.session.Client().GetBlocksByRange(context.Background(), 11, 13)
+	require.NoError(err)
+	_, err = s.session.Client().GetBlocksByRange(context.Background(), 10, 11)
+	require.NoError(err)
+	require.Equal(int64(2), s.counter("hit", "raw"))
+
+	// The evicted blocks are no longer indexed by height.
+	require.Equal(2, len(s.session.client.cache.canonicalHashes))
+}
+
+func (s *blockCacheTestSuite) TestEviction_Concurrent() {
+	require := testutil.Require(s.T())
+
+	// Room for two blocks only.
+	s.session = s.newCachedSession(&config.BlockCacheConfig{
+		MaxRawBlockBytes: int64(2*proto.Size(testBlock(10)) + 1),
+	})
+	cache := s.session.client.cache
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for height := uint64(i * 100); height < uint64(i*100+100); height++ {
+				cache.addBlock(1, testBlock(height), true)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Only the cached blocks are indexed by height.
+	require.LessOrEqual(len(cache.canonicalHashes), 2)
+	for k, hash := range cache.canonicalHashes {
+		require.True(cache.raw.lru.Contains(blockKey{tag: k.tag, height: k.height, hash: hash}))
+	}
+}
+
+func (s *blockCacheTestSuite) TestEviction_TooLarge() {
+	require := testutil.Require(s.T())
+
+	s.session = s.newCachedSession(&config.BlockCacheConfig{
+		MaxRawBlockBytes: 1,
+	})
+	cache := s.session.client.cache
+
+	cache.addBlock(1, testBlock(10), true)
+	require.Empty(cache.canonicalHashes)
+}
+
+func (s *blockCacheTestSuite) TestIsIrreversible_RefreshWithoutLock() {
+	require := testutil.Require(s.T())
+
+	session := &blockingTipSession{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	cache := &blockCache{
+		session:   session,
+		tipHeight: 50,
+	}
+
+	refreshed := make(chan bool)
+	go func() {
+		refreshed <- cache.isIrreversible(context.Background(), 60)
+	}()
+	<-session.started
+
+	// The heights below the cached tip do not wait for the refresh.
+	require.True(cache.isIrreversible(context.Background(), 10))
+
+	close(session.release)
+	require.True(<-refreshed)
+	require.False(cache.isIrreversible(context.Background(), testTipHeight))
+}
+
+func (s *blockCacheTestSuite) newCachedSession(cfg *config.BlockCacheConfig) *cachedSession {
+	session, err := newSession(s.sdkSession)
+	s.Require().NoError(err)
+
+	cached, err := newCachedSession(session, cfg, s.scope)
+	s.Require().NoError(err)
+	return cached
+}
+
+func (s *blockCacheTestSuite) counter(name string, cache string) int64 {
+	for _, counter := range s.scope.Snapshot().Counters() {
+		if counter.Name() == "block_cache."+name && counter.Tags()["cache"] == cache {
+			return counter.Value()
+		}
+	}
+
+	return 0
+}
+
+func testBlock(height uint64) *chainstorageapi.Block {
+	return &chainstorageapi.Block{
+		Metadata: &chainstorageapi.BlockMetadata{
+			Tag:    1,
+			Height: height,
+			Hash:   "0xa",
+		},
+	}
+}
+
+func testBlocks(startHeight uint64, endHeight uint64) []*chainstorageapi.Block {
+	var blocks []*chainstorageapi.Block
+	for height := startHeight; height < endHeight; height++ {
+		blocks = append(blocks, testBlock(height))
+	}
+
+	return blocks
+}
+
+func (s *blockingTipSession) GetTipHeight(ctx context.Context) (uint64, error) {
+	close(s.started)
+	<-s.release
+	return testTipHeight, nil
+}
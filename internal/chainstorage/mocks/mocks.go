@@ -12,6 +12,7 @@ package chainstoragemocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	chainstorage "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
 	sdk "github.com/coinbase/chainstorage/sdk"
@@ -55,6 +56,21 @@ func (mr *MockSessionMockRecorder) Client() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Client", reflect.TypeOf((*MockSession)(nil).Client))
 }
 
+// GetEventBySequence mocks base method.
+func (m *MockSession) GetEventBySequence(arg0 context.Context, arg1 int64) (*chainstorage.BlockchainEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEventBySequence", arg0, arg1)
+	ret0, _ := ret[0].(*chainstorage.BlockchainEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEventBySequence indicates an expected call of GetEventBySequence.
+func (mr *MockSessionMockRecorder) GetEventBySequence(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEventBySequence", reflect.TypeOf((*MockSession)(nil).GetEventBySequence), arg0, arg1)
+}
+
 // GetEventSequenceByPosition mocks base method.
 func (m *MockSession) GetEventSequenceByPosition(arg0 context.Context, arg1 string) (int64, error) {
 	m.ctrl.T.Helper()
@@ -70,6 +86,36 @@ func (mr *MockSessionMockRecorder) GetEventSequenceByPosition(arg0, arg1 any) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEventSequenceByPosition", reflect.TypeOf((*MockSession)(nil).GetEventSequenceByPosition), arg0, arg1)
 }
 
+// GetFirstEventByHeight mocks base method.
+func (m *MockSession) GetFirstEventByHeight(arg0 context.Context, arg1 uint64) (*chainstorage.BlockchainEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFirstEventByHeight", arg0, arg1)
+	ret0, _ := ret[0].(*chainstorage.BlockchainEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFirstEventByHeight indicates an expected call of GetFirstEventByHeight.
+func (mr *MockSessionMockRecorder) GetFirstEventByHeight(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFirstEventByHeight", reflect.TypeOf((*MockSession)(nil).GetFirstEventByHeight), arg0, arg1)
+}
+
+// GetHeightAtTime mocks base method.
+func (m *MockSession) GetHeightAtTime(arg0 context.Context, arg1 time.Time) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHeightAtTime", arg0, arg1)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHeightAtTime indicates an expected call of GetHeightAtTime.
+func (mr *MockSessionMockRecorder) GetHeightAtTime(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHeightAtTime", reflect.TypeOf((*MockSession)(nil).GetHeightAtTime), arg0, arg1)
+}
+
 // GetStartHeight mocks base method.
 func (m *MockSession) GetStartHeight(arg0 context.Context) (uint64, error) {
 	m.ctrl.T.Helper()
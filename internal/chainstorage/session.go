@@ -2,13 +2,18 @@ package chainstorage
 
 import (
 	"context"
+	"math"
+	"strconv"
+	"time"
 
+	lru "github.com/hashicorp/golang-lru/v2"
 	"go.uber.org/fx"
 	"golang.org/x/xerrors"
 
 	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
 	"github.com/coinbase/chainstorage/sdk"
 
+	"github.com/coinbase/chainsformer/internal/errors"
 	"github.com/coinbase/chainsformer/internal/utils/fxparams"
 )
 
@@ -22,6 +27,15 @@ type (
 		GetStartHeight(ctx context.Context) (uint64, error)
 		GetEventSequenceByPosition(ctx context.Context, eventPosition string) (int64, error)
 
+		// GetEventBySequence returns the event with the given sequence number.
+		GetEventBySequence(ctx context.Context, sequence int64) (*chainstorageapi.BlockchainEvent, error)
+		// GetFirstEventByHeight returns the first event of the block at the given height.
+		GetFirstEventByHeight(ctx context.Context, height uint64) (*chainstorageapi.BlockchainEvent, error)
+
+		// GetHeightAtTime returns the height of the first irreversible block whose timestamp is not before t,
+		// or the tip height plus one if there is no such block yet.
+		GetHeightAtTime(ctx context.Context, t time.Time) (uint64, error)
+
 		// Fetch the static chain metadata from ChainStorage. Calling this instead of GetChainMetadata can avoid
 		// the impact of configuration changes made at ChainStorage on the fly.
 		GetStaticChainMetadata(ctx context.Context, req *chainstorageapi.GetChainMetadataRequest) (*chainstorageapi.GetChainMetadataResponse, error)
@@ -36,12 +50,19 @@ type (
 	// This struct interacts with ChainStorage.
 	sessionImpl struct {
 		sdkSession sdk.Session
+		// heightAtTime caches the heights resolved by GetHeightAtTime, keyed by unix seconds.
+		heightAtTime *lru.Cache[int64, uint64]
 	}
 )
 
 const (
 	EarliestEventPosition = "EARLIEST"
 	LatestEventPosition   = "LATEST"
+
+	heightAtTimeCacheSize = 4096
+	// maxTimestampEvents is the number of events read to find the timestamp of a height,
+	// which must cover the consecutive skipped blocks following it.
+	maxTimestampEvents = 100
 )
 
 func NewSession(params Params) (Session, error) {
@@ -51,8 +72,27 @@ func NewSession(params Params) (Session, error) {
 		return nil, xerrors.Errorf("failed to create chainstorage session {%+v}: %w", cfg, err)
 	}
 
+	res, err := newSession(session)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create session: %w", err)
+	}
+
+	if params.Config.BlockCache.Disabled {
+		return res, nil
+	}
+
+	return newCachedSession(res, &params.Config.BlockCache, params.Metrics)
+}
+
+func newSession(sdkSession sdk.Session) (*sessionImpl, error) {
+	heightAtTime, err := lru.New[int64, uint64](heightAtTimeCacheSize)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create height cache: %w", err)
+	}
+
 	return &sessionImpl{
-		sdkSession: session,
+		sdkSession:   sdkSession,
+		heightAtTime: heightAtTime,
 	}, nil
 }
 
@@ -99,6 +139,158 @@ func (s *sessionImpl) GetEventSequenceByPosition(ctx context.Context, eventPosit
 	return events[0].SequenceNum, nil
 }
 
+func (s *sessionImpl) GetEventBySequence(ctx context.Context, sequence int64) (*chainstorageapi.BlockchainEvent, error) {
+	events, err := s.sdkSession.Client().GetChainEvents(ctx, &chainstorageapi.GetChainEventsRequest{
+		SequenceNum:  sequence - 1,
+		MaxNumEvents: 1,
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get chain events: %w", err)
+	}
+
+	if len(events) != 1 || events[0].GetSequenceNum() != sequence {
+		return nil, xerrors.Errorf("event(sequence=%d): %w", sequence, errors.ErrNotFound)
+	}
+
+	return events[0], nil
+}
+
+func (s *sessionImpl) GetFirstEventByHeight(ctx context.Context, height uint64) (*chainstorageapi.BlockchainEvent, error) {
+	// ChainStorage starts the stream at the first event of the block when the initial position is a height.
+	events, err := s.sdkSession.Client().GetChainEvents(ctx, &chainstorageapi.GetChainEventsRequest{
+		InitialPositionInStream: strconv.FormatUint(height, 10),
+		MaxNumEvents:            1,
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get chain events: %w", err)
+	}
+
+	if len(events) != 1 || events[0].GetBlock().GetHeight() != height {
+		return nil, xerrors.Errorf("event(height=%d): %w", height, errors.ErrNotFound)
+	}
+
+	return events[0], nil
+}
+
 func (s *sessionImpl) GetStaticChainMetadata(ctx context.Context, req *chainstorageapi.GetChainMetadataRequest) (*chainstorageapi.GetChainMetadataResponse, error) {
 	return s.sdkSession.Client().GetStaticChainMetadata(ctx, req)
 }
+
+func (s *sessionImpl) GetHeightAtTime(ctx context.Context, t time.Time) (uint64, error) {
+	// Block timestamps have a granularity of seconds.
+	target := t.Unix()
+	if t.Nanosecond() > 0 {
+		target += 1
+	}
+
+	if height, ok := s.heightAtTime.Get(target); ok {
+		return height, nil
+	}
+
+	startHeight, err := s.GetStartHeight(ctx)
+	if err != nil {
+		return 0, xerrors.Errorf("failed to get start height: %w", err)
+	}
+
+	tipHeight, err := s.GetTipHeight(ctx)
+	if err != nil {
+		return 0, xerrors.Errorf("failed to get tip height: %w", err)
+	}
+
+	// Binary search the first block in [startHeight, tipHeight] whose timestamp is not before the target.
+	// This assumes the block timestamps are non-decreasing, which only approximately holds for some chains (e.g. bitcoin).
+	low, high := startHeight, tipHeight+1
+	for low < high {
+		mid := low + (high-low)/2
+		timestamp, err := s.getBlockTimestamp(ctx, mid, tipHeight)
+		if err != nil {
+			return 0, xerrors.Errorf("failed to get timestamp of block (height=%d): %w", mid, err)
+		}
+
+		if timestamp < target {
+			low = mid + 1
+		} else {
+			high = mid
+		}
+	}
+
+	// Heights past the tip may still change as new blocks become irreversible.
+	if low <= tipHeight {
+		s.heightAtTime.Add(target, low)
+	}
+
+	return low, nil
+}
+
+// getBlockTimestamp returns the timestamp, in unix seconds, of the block at the given height.
+// The timestamp is read from the chain events of the block, and from the block itself if the events have none.
+// Skipped blocks take the timestamp of the next block that is not skipped, or math.MaxInt64 if there is none up to maxHeight.
+// At most maxTimestampEvents events are read, which bounds the number of consecutive skipped blocks.
+func (s *sessionImpl) getBlockTimestamp(ctx context.Context, height uint64, maxHeight uint64) (int64, error) {
+	events, err := s.sdkSession.Client().GetChainEvents(ctx, &chainstorageapi.GetChainEventsRequest{
+		InitialPositionInStream: strconv.FormatUint(height, 10),
+		MaxNumEvents:            maxTimestampEvents,
+	})
+	if err != nil {
+		return 0, xerrors.Errorf("failed to get chain events: %w", err)
+	}
+
+	for _, event := range events {
+		block := event.GetBlock()
+		if event.GetType() != chainstorageapi.BlockchainEvent_BLOCK_ADDED || block.GetHeight() < height {
+			continue
+		}
+
+		if block.GetHeight() > maxHeight {
+			return math.MaxInt64, nil
+		}
+
+		if block.GetSkipped() {
+			continue
+		}
+
+		if timestamp := block.GetTimestamp().GetSeconds(); timestamp > 0 {
+			return timestamp, nil
+		}
+
+		// The timestamp is only available in the events of recent blocks.
+		timestamp, err := s.getTimestampFromBlock(ctx, block)
+		if err != nil {
+			return 0, xerrors.Errorf("failed to get timestamp from block(height=%d): %w", block.GetHeight(), err)
+		}
+
+		return timestamp, nil
+	}
+
+	if len(events) < maxTimestampEvents {
+		// The stream ends before maxHeight.
+		return math.MaxInt64, nil
+	}
+
+	return 0, xerrors.Errorf("no block with a timestamp within %d events from height %d: %w", maxTimestampEvents, height, errors.ErrNotFound)
+}
+
+// getTimestampFromBlock returns the timestamp, in unix seconds, of the identified block.
+func (s *sessionImpl) getTimestampFromBlock(ctx context.Context, blockID *chainstorageapi.BlockIdentifier) (int64, error) {
+	block, err := s.sdkSession.Client().GetBlockWithTag(ctx, blockID.GetTag(), blockID.GetHeight(), blockID.GetHash())
+	if err != nil {
+		return 0, xerrors.Errorf("failed to get block: %w", err)
+	}
+
+	if timestamp := block.GetMetadata().GetTimestamp().GetSeconds(); timestamp > 0 {
+		return timestamp, nil
+	}
+
+	// Older blocks may not have the timestamp in their metadata.
+	nativeBlock, err := s.sdkSession.Parser().ParseNativeBlock(ctx, block)
+	if err != nil {
+		return 0, xerrors.Errorf("failed to parse block: %w", err)
+	}
+
+	timestamp := nativeBlock.GetTimestamp().GetSeconds()
+	if timestamp <= 0 {
+		return 0, xerrors.Errorf("block has no timestamp: %w", errors.ErrNotFound)
+	}
+
+	return timestamp, nil
+}
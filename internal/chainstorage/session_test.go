@@ -3,14 +3,19 @@ package chainstorage
 import (
 	"context"
 	"errors"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/mock/gomock"
 	"golang.org/x/xerrors"
 
+	"google.golang.org/protobuf/types/known/timestamppb"
+
 	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
 
+	internalerrors "github.com/coinbase/chainsformer/internal/errors"
 	"github.com/coinbase/chainsformer/internal/utils/testutil"
 
 	sdkmocks "github.com/coinbase/chainstorage/sdk/mocks"
@@ -35,9 +40,9 @@ func (s *sessionTestSuite) SetupTest() {
 	s.sdkSession = sdkmocks.NewMockSession(s.ctrl)
 	s.client = sdkmocks.NewMockClient(s.ctrl)
 
-	s.session = &sessionImpl{
-		sdkSession: s.sdkSession,
-	}
+	session, err := newSession(s.sdkSession)
+	s.Require().NoError(err)
+	s.session = session
 
 	// Use the mocked client for ChainStorage requests.
 	s.sdkSession.EXPECT().Client().Return(s.client).AnyTimes()
@@ -174,3 +179,217 @@ func (s *sessionTestSuite) TestGetChainMetadata_Success() {
 	require.NoError(err)
 	require.Equal(chainMeta, res)
 }
+
+func (s *sessionTestSuite) TestGetHeightAtTime() {
+	require := testutil.Require(s.T())
+
+	// Blocks [100, 200] are irreversible, with block i produced at 10*i seconds.
+	s.client.EXPECT().GetStaticChainMetadata(gomock.Any(), &chainstorageapi.GetChainMetadataRequest{}).Return(&chainstorageapi.GetChainMetadataResponse{
+		BlockStartHeight:     100,
+		IrreversibleDistance: 10,
+	}, nil).AnyTimes()
+	s.client.EXPECT().GetLatestBlock(gomock.Any()).Return(uint64(210), nil).AnyTimes()
+	numGetChainEvents := s.expectChainEvents(250, func(height uint64) *chainstorageapi.BlockIdentifier {
+		return &chainstorageapi.BlockIdentifier{
+			Height:    height,
+			Timestamp: timestamppb.New(time.Unix(int64(height*10), 0)),
+		}
+	})
+
+	tests := []struct {
+		time     time.Time
+		expected uint64
+	}{
+		{time: time.Unix(0, 0), expected: 100},
+		{time: time.Unix(1500, 0), expected: 150},
+		{time: time.Unix(1501, 0), expected: 151},
+		{time: time.Unix(1500, 1), expected: 151},
+		{time: time.Unix(2000, 0), expected: 200},
+		{time: time.Unix(3000, 0), expected: 201},
+	}
+	for _, test := range tests {
+		height, err := s.session.GetHeightAtTime(context.Background(), test.time)
+		require.NoError(err)
+		require.Equal(test.expected, height, test.time)
+	}
+
+	// The resolved heights within the irreversible range are cached.
+	*numGetChainEvents = 0
+	height, err := s.session.GetHeightAtTime(context.Background(), time.Unix(1500, 0))
+	require.NoError(err)
+	require.Equal(uint64(150), height)
+	require.Equal(0, *numGetChainEvents)
+}
+
+func (s *sessionTestSuite) TestGetHeightAtTime_SkippedBlocks() {
+	require := testutil.Require(s.T())
+
+	// Blocks [0, 10] are irreversible, the even ones are skipped, and block i is produced at 10*i seconds.
+	s.client.EXPECT().GetStaticChainMetadata(gomock.Any(), &chainstorageapi.GetChainMetadataRequest{}).Return(&chainstorageapi.GetChainMetadataResponse{}, nil).AnyTimes()
+	s.client.EXPECT().GetLatestBlock(gomock.Any()).Return(uint64(10), nil).AnyTimes()
+	s.expectChainEvents(20, func(height uint64) *chainstorageapi.BlockIdentifier {
+		if height%2 == 0 {
+			return &chainstorageapi.BlockIdentifier{Height: height, Skipped: true}
+		}
+
+		return &chainstorageapi.BlockIdentifier{
+			Height:    height,
+			Timestamp: timestamppb.New(time.Unix(int64(height*10), 0)),
+		}
+	})
+
+	height, err := s.session.GetHeightAtTime(context.Background(), time.Unix(45, 0))
+	require.NoError(err)
+	require.Equal(uint64(4), height)
+
+	height, err = s.session.GetHeightAtTime(context.Background(), time.Unix(95, 0))
+	require.NoError(err)
+	require.Equal(uint64(10), height)
+}
+
+func (s *sessionTestSuite) TestGetHeightAtTime_NoEventTimestamps() {
+	require := testutil.Require(s.T())
+
+	// Blocks [0, 100] are irreversible, and block i is produced at 10*i seconds.
+	// The events have no timestamp, the blocks from 50 have it in their metadata, and the older ones only in their native block.
+	s.client.EXPECT().GetStaticChainMetadata(gomock.Any(), &chainstorageapi.GetChainMetadataRequest{}).Return(&chainstorageapi.GetChainMetadataResponse{}, nil).AnyTimes()
+	s.client.EXPECT().GetLatestBlock(gomock.Any()).Return(uint64(100), nil).AnyTimes()
+	s.expectChainEvents(100, func(height uint64) *chainstorageapi.BlockIdentifier {
+		return &chainstorageapi.BlockIdentifier{Tag: 1, Height: height, Hash: strconv.FormatUint(height, 16)}
+	})
+	s.client.EXPECT().GetBlockWithTag(gomock.Any(), uint32(1), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, tag uint32, height uint64, hash string) (*chainstorageapi.Block, error) {
+		require.Equal(strconv.FormatUint(height, 16), hash)
+		metadata := &chainstorageapi.BlockMetadata{Tag: tag, Height: height, Hash: hash}
+		if height >= 50 {
+			metadata.Timestamp = timestamppb.New(time.Unix(int64(height*10), 0))
+		}
+
+		return &chainstorageapi.Block{Metadata: metadata}, nil
+	}).AnyTimes()
+	parser := sdkmocks.NewMockParser(s.ctrl)
+	s.sdkSession.EXPECT().Parser().Return(parser).AnyTimes()
+	parser.EXPECT().ParseNativeBlock(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, block *chainstorageapi.Block) (*chainstorageapi.NativeBlock, error) {
+		require.Less(block.GetMetadata().GetHeight(), uint64(50))
+		return &chainstorageapi.NativeBlock{
+			Height:    block.GetMetadata().GetHeight(),
+			Timestamp: timestamppb.New(time.Unix(int64(block.GetMetadata().GetHeight()*10), 0)),
+		}, nil
+	}).AnyTimes()
+
+	height, err := s.session.GetHeightAtTime(context.Background(), time.Unix(205, 0))
+	require.NoError(err)
+	require.Equal(uint64(21), height)
+
+	height, err = s.session.GetHeightAtTime(context.Background(), time.Unix(750, 0))
+	require.NoError(err)
+	require.Equal(uint64(75), height)
+}
+
+func (s *sessionTestSuite) TestGetHeightAtTime_TooManySkippedBlocks() {
+	require := testutil.Require(s.T())
+
+	s.client.EXPECT().GetStaticChainMetadata(gomock.Any(), &chainstorageapi.GetChainMetadataRequest{}).Return(&chainstorageapi.GetChainMetadataResponse{}, nil).AnyTimes()
+	s.client.EXPECT().GetLatestBlock(gomock.Any()).Return(uint64(1000), nil)
+	s.expectChainEvents(1000, func(height uint64) *chainstorageapi.BlockIdentifier {
+		return &chainstorageapi.BlockIdentifier{Height: height, Skipped: true}
+	})
+
+	_, err := s.session.GetHeightAtTime(context.Background(), time.Unix(45, 0))
+	require.True(errors.Is(err, internalerrors.ErrNotFound))
+}
+
+func (s *sessionTestSuite) TestGetHeightAtTime_Failed_GetChainEvents() {
+	require := testutil.Require(s.T())
+
+	s.client.EXPECT().GetStaticChainMetadata(gomock.Any(), &chainstorageapi.GetChainMetadataRequest{}).Return(&chainstorageapi.GetChainMetadataResponse{}, nil).AnyTimes()
+	s.client.EXPECT().GetLatestBlock(gomock.Any()).Return(uint64(10), nil)
+	errorGetChainEvents := xerrors.New("failed to get chain events")
+	s.client.EXPECT().GetChainEvents(gomock.Any(), gomock.Any()).Return(nil, errorGetChainEvents)
+
+	_, err := s.session.GetHeightAtTime(context.Background(), time.Unix(45, 0))
+	require.True(errors.Is(err, errorGetChainEvents))
+}
+
+func (s *sessionTestSuite) TestGetEventBySequence() {
+	require := testutil.Require(s.T())
+
+	event := &chainstorageapi.BlockchainEvent{
+		SequenceNum: 100,
+		Block:       &chainstorageapi.BlockIdentifier{Height: 12345},
+	}
+	s.client.EXPECT().GetChainEvents(gomock.Any(), &chainstorageapi.GetChainEventsRequest{
+		SequenceNum:  99,
+		MaxNumEvents: 1,
+	}).Return([]*chainstorageapi.BlockchainEvent{event}, nil)
+
+	res, err := s.session.GetEventBySequence(context.Background(), 100)
+	require.NoError(err)
+	require.Equal(event, res)
+}
+
+func (s *sessionTestSuite) TestGetEventBySequence_NotFound() {
+	require := testutil.Require(s.T())
+
+	s.client.EXPECT().GetChainEvents(gomock.Any(), &chainstorageapi.GetChainEventsRequest{
+		SequenceNum:  99,
+		MaxNumEvents: 1,
+	}).Return(nil, nil)
+
+	_, err := s.session.GetEventBySequence(context.Background(), 100)
+	require.True(errors.Is(err, internalerrors.ErrNotFound))
+}
+
+func (s *sessionTestSuite) TestGetFirstEventByHeight() {
+	require := testutil.Require(s.T())
+
+	event := &chainstorageapi.BlockchainEvent{
+		SequenceNum: 100,
+		Block:       &chainstorageapi.BlockIdentifier{Height: 12345},
+	}
+	s.client.EXPECT().GetChainEvents(gomock.Any(), &chainstorageapi.GetChainEventsRequest{
+		InitialPositionInStream: "12345",
+		MaxNumEvents:            1,
+	}).Return([]*chainstorageapi.BlockchainEvent{event}, nil)
+
+	res, err := s.session.GetFirstEventByHeight(context.Background(), 12345)
+	require.NoError(err)
+	require.Equal(event, res)
+}
+
+func (s *sessionTestSuite) TestGetFirstEventByHeight_Failed_GetChainEvents() {
+	require := testutil.Require(s.T())
+
+	errorGetChainEvents := xerrors.New("failed to get chain events")
+	s.client.EXPECT().GetChainEvents(gomock.Any(), &chainstorageapi.GetChainEventsRequest{
+		InitialPositionInStream: "12345",
+		MaxNumEvents:            1,
+	}).Return(nil, errorGetChainEvents)
+
+	_, err := s.session.GetFirstEventByHeight(context.Background(), 12345)
+	require.True(errors.Is(err, errorGetChainEvents))
+}
+
+// expectChainEvents mocks the chain events from a height, with one BLOCK_ADDED event per block up to maxHeight.
+// It returns the number of calls to GetChainEvents.
+func (s *sessionTestSuite) expectChainEvents(maxHeight uint64, block func(height uint64) *chainstorageapi.BlockIdentifier) *int {
+	numCalls := 0
+	s.client.EXPECT().GetChainEvents(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, req *chainstorageapi.GetChainEventsRequest) ([]*chainstorageapi.BlockchainEvent, error) {
+		numCalls += 1
+		startHeight, err := strconv.ParseUint(req.InitialPositionInStream, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		var events []*chainstorageapi.BlockchainEvent
+		for height := startHeight; height <= maxHeight && len(events) < int(req.MaxNumEvents); height++ {
+			events = append(events, &chainstorageapi.BlockchainEvent{
+				Type:  chainstorageapi.BlockchainEvent_BLOCK_ADDED,
+				Block: block(height),
+			})
+		}
+
+		return events, nil
+	}).AnyTimes()
+
+	return &numCalls
+}
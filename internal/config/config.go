@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/mitchellh/mapstructure"
@@ -28,6 +29,8 @@ type (
 		Table           TableConfig           `mapstructure:"table" validate:"required"`
 		Server          ServerConfig          `mapstructure:"server"`
 		ChainStorageSDK ChainStorageSDKConfig `mapstructure:"chainstorage_sdk" validate:"required"`
+		BlockCache      BlockCacheConfig      `mapstructure:"block_cache"`
+		ABIRegistry     ABIRegistryConfig     `mapstructure:"abi_registry"`
 		StatsD          *StatsDConfig         `mapstructure:"statsd"`
 
 		env Env
@@ -43,14 +46,42 @@ type (
 	}
 
 	TableConfig struct {
-		SupportedFormats []string          `mapstructure:"supported_formats" validate:"required"`
-		StreamTable      StreamTableConfig `mapstructure:"stream_table"`
+		SupportedFormats []string           `mapstructure:"supported_formats" validate:"required"`
+		StreamTable      StreamTableConfig  `mapstructure:"stream_table"`
+		BatchTable       BatchTableConfig   `mapstructure:"batch_table"`
+		Rosetta          RosettaTableConfig `mapstructure:"rosetta"`
 	}
 
 	StreamTableConfig struct {
 		Parallelism int `mapstructure:"parallelism"`
 	}
 
+	BatchTableConfig struct {
+		// Parallelism is the maximum number of chunks of blocks fetched concurrently.
+		Parallelism int `mapstructure:"parallelism"`
+		// PrefetchDepth is the maximum number of chunks fetched ahead of the chunk being transformed.
+		PrefetchDepth int `mapstructure:"prefetch_depth"`
+		// EstimateTimeout bounds the time spent sampling blocks to estimate the size of a query.
+		EstimateTimeout time.Duration `mapstructure:"estimate_timeout"`
+	}
+
+	// RosettaTableConfig configures the rosetta tables of the typed encoding,
+	// whose metadata is a map<string, string> instead of a JSON string.
+	RosettaTableConfig struct {
+		// BlockMetadataColumns are the well-known keys of the block metadata promoted to typed columns.
+		BlockMetadataColumns []MetadataColumnConfig `mapstructure:"block_metadata_columns" validate:"dive"`
+		// TransactionMetadataColumns are the well-known keys of the transaction metadata promoted to typed columns.
+		TransactionMetadataColumns []MetadataColumnConfig `mapstructure:"transaction_metadata_columns" validate:"dive"`
+	}
+
+	MetadataColumnConfig struct {
+		Key string `mapstructure:"key" validate:"required"`
+		// Name is the name of the column, which defaults to metadata_<key>.
+		Name string `mapstructure:"name"`
+		// Type is the type of the column. The column is null if the key is missing or its value cannot be converted.
+		Type string `mapstructure:"type" validate:"required,oneof=string int64 uint64 float64 bool decimal"`
+	}
+
 	ServerConfig struct {
 		BindAddress string `mapstructure:"bind_address" validate:"required"`
 	}
@@ -59,6 +90,21 @@ type (
 		sdk.Config `mapstructure:",squash"`
 	}
 
+	// BlockCacheConfig configures the in-process cache of the irreversible blocks shared by the tables.
+	BlockCacheConfig struct {
+		Disabled bool `mapstructure:"disabled"`
+		// MaxRawBlockBytes is the memory limit of the cached raw blocks.
+		MaxRawBlockBytes int64 `mapstructure:"max_raw_block_bytes"`
+		// MaxParsedBlockBytes is the memory limit of the cached native and rosetta blocks.
+		MaxParsedBlockBytes int64 `mapstructure:"max_parsed_block_bytes"`
+	}
+
+	ABIRegistryConfig struct {
+		// Dir is the directory of the JSON ABI files used to decode the ethereum logs and calls.
+		// Nothing is decoded if it is not set.
+		Dir string `mapstructure:"dir"`
+	}
+
 	StatsDConfig struct {
 		Address string `mapstructure:"address" validate:"required"`
 		Prefix  string `mapstructure:"prefix"`
@@ -106,7 +152,22 @@ const (
 	tagNetwork    = "network"
 	tagTier       = "tier"
 
-	defaultStreamParallelism = 10
+	defaultStreamParallelism    = 10
+	defaultBatchParallelism     = 4
+	defaultBatchPrefetchDepth   = 8
+	defaultBatchEstimateTimeout = 10 * time.Second
+
+	defaultMaxRawBlockBytes    = 256 << 20
+	defaultMaxParsedBlockBytes = 512 << 20
+
+	MetadataColumnTypeString  = "string"
+	MetadataColumnTypeInt64   = "int64"
+	MetadataColumnTypeUint64  = "uint64"
+	MetadataColumnTypeFloat64 = "float64"
+	MetadataColumnTypeBool    = "bool"
+	MetadataColumnTypeDecimal = "decimal"
+
+	metadataColumnNamePrefix = "metadata_"
 )
 
 var (
@@ -142,6 +203,9 @@ func New(opts ...ConfigOption) (*Config, error) {
 	v.AllowEmptyEnv(true)
 	v.SetEnvPrefix("CHAINSFORMER")
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	// The keys missing from the config files are only bound to the environment variables once they have a default.
+	// The abi registry is empty unless configured, e.g. by CHAINSFORMER_ABI_REGISTRY_DIR.
+	v.SetDefault("abi_registry.dir", "")
 
 	cfg := Config{
 		env: configOpts.Env,
@@ -409,6 +473,54 @@ func (c *StreamTableConfig) GetParallelism() int {
 	return c.Parallelism
 }
 
+func (c *BatchTableConfig) GetParallelism() int {
+	if c.Parallelism < 1 {
+		return defaultBatchParallelism
+	}
+
+	return c.Parallelism
+}
+
+func (c *BatchTableConfig) GetPrefetchDepth() int {
+	if c.PrefetchDepth < 1 {
+		return defaultBatchPrefetchDepth
+	}
+
+	return c.PrefetchDepth
+}
+
+func (c *BatchTableConfig) GetEstimateTimeout() time.Duration {
+	if c.EstimateTimeout <= 0 {
+		return defaultBatchEstimateTimeout
+	}
+
+	return c.EstimateTimeout
+}
+
+func (c *BlockCacheConfig) GetMaxRawBlockBytes() int64 {
+	if c.MaxRawBlockBytes < 1 {
+		return defaultMaxRawBlockBytes
+	}
+
+	return c.MaxRawBlockBytes
+}
+
+func (c *BlockCacheConfig) GetMaxParsedBlockBytes() int64 {
+	if c.MaxParsedBlockBytes < 1 {
+		return defaultMaxParsedBlockBytes
+	}
+
+	return c.MaxParsedBlockBytes
+}
+
+func (c *MetadataColumnConfig) GetName() string {
+	if c.Name == "" {
+		return metadataColumnNamePrefix + c.Key
+	}
+
+	return c.Name
+}
+
 func (c *ChainStorageSDKConfig) DeriveConfig(cfg *Config) {
 	c.Config.Blockchain = cfg.Blockchain()
 	c.Config.Network = cfg.Network()
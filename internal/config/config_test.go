@@ -31,7 +31,7 @@ func TestConfig(t *testing.T) {
 			}
 		}{
 			"bitcoin-mainnet": {
-				supportedFormats: []string{"native"},
+				supportedFormats: []string{"rosetta", "native"},
 				streamTable: struct {
 					parallelism int
 				}{
@@ -127,6 +127,34 @@ func TestConfig(t *testing.T) {
 					bindAddress: ":9090",
 				},
 			},
+
+			"solana-mainnet": {
+				supportedFormats: []string{"native"},
+				streamTable: struct {
+					parallelism int
+				}{
+					parallelism: 10,
+				},
+				server: struct {
+					bindAddress string
+				}{
+					bindAddress: ":9090",
+				},
+			},
+
+			"aptos-mainnet": {
+				supportedFormats: []string{"native"},
+				streamTable: struct {
+					parallelism int
+				}{
+					parallelism: 10,
+				},
+				server: struct {
+					bindAddress string
+				}{
+					bindAddress: ":9090",
+				},
+			},
 		}
 
 		expectedMapConfig, ok := expectedMapConfigs[cfg.ConfigName]
@@ -152,3 +180,44 @@ func TestConfigOverrideConfigPath(t *testing.T) {
 	require.Equal(common.Blockchain_BLOCKCHAIN_ETHEREUM, cfg.Blockchain())
 	require.Equal(common.Network_NETWORK_ETHEREUM_GOERLI, cfg.Network())
 }
+
+func TestABIRegistryConfigOverrideEnv(t *testing.T) {
+	require := testutil.Require(t)
+
+	cfg, err := config.New()
+	require.NoError(err)
+	require.Empty(cfg.ABIRegistry.Dir)
+
+	err = os.Setenv("CHAINSFORMER_ABI_REGISTRY_DIR", "/etc/chainsformer/abi")
+	require.NoError(err)
+	defer os.Unsetenv("CHAINSFORMER_ABI_REGISTRY_DIR")
+
+	cfg, err = config.New()
+	require.NoError(err)
+	require.Equal("/etc/chainsformer/abi", cfg.ABIRegistry.Dir)
+}
+
+func TestBatchTableConfigDefaults(t *testing.T) {
+	require := testutil.Require(t)
+
+	var cfg config.BatchTableConfig
+	require.Equal(4, cfg.GetParallelism())
+	require.Equal(8, cfg.GetPrefetchDepth())
+
+	cfg = config.BatchTableConfig{
+		Parallelism:   2,
+		PrefetchDepth: 3,
+	}
+	require.Equal(2, cfg.GetParallelism())
+	require.Equal(3, cfg.GetPrefetchDepth())
+}
+
+func TestMetadataColumnConfigDefaults(t *testing.T) {
+	require := testutil.Require(t)
+
+	cfg := config.MetadataColumnConfig{Key: "gas_limit"}
+	require.Equal("metadata_gas_limit", cfg.GetName())
+
+	cfg.Name = "gas"
+	require.Equal("gas", cfg.GetName())
+}
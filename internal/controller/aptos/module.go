@@ -0,0 +1,15 @@
+package aptos
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/coinbase/chainsformer/internal/controller/aptos/tables"
+)
+
+var Module = fx.Options(
+	fx.Provide(fx.Annotated{
+		Name:   "aptos",
+		Target: NewController,
+	}),
+	tables.Module,
+)
@@ -0,0 +1,56 @@
+package tables
+
+import (
+	"context"
+
+	"golang.org/x/xerrors"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+	"github.com/coinbase/chainstorage/sdk"
+
+	"github.com/coinbase/chainsformer/internal/controller/internal"
+	"github.com/coinbase/chainsformer/internal/controller/internal/constant"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+type (
+	blocksTable struct{}
+)
+
+func NewBlocksTable(params internal.CommonTableParams) internal.Table {
+	return internal.NewBatchTable(
+		&params,
+		internal.NewTableAttributes(internal.TableNameBlocks, internal.WithEstimateMode(constant.EstimateModeBlocks)),
+		newBlockSchema(),
+		blocksTable{},
+	)
+}
+
+func (t blocksTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
+	aptosBlock, err := parseAptosBlock(ctx, block, parser)
+	if err != nil {
+		return xerrors.Errorf("failed to parse aptos block: %w", err)
+	}
+
+	if err := transformBlocks(recordBuilder, aptosBlock, partitioner); err != nil {
+		return xerrors.Errorf("failed to transform blocks: %w", err)
+	}
+
+	return nil
+}
+
+// parseAptosBlock parses the raw block into the aptos block of the native format.
+func parseAptosBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser) (*chainstorageapi.AptosBlock, error) {
+	nativeBlock, err := parser.ParseNativeBlock(ctx, block)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to parse raw block to native block: %w", err)
+	}
+
+	aptosBlock := nativeBlock.GetAptos()
+	if aptosBlock == nil {
+		return nil, xerrors.New("failed to extract aptos block from native block")
+	}
+
+	return aptosBlock, nil
+}
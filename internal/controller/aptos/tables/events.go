@@ -0,0 +1,40 @@
+package tables
+
+import (
+	"context"
+
+	"golang.org/x/xerrors"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+	"github.com/coinbase/chainstorage/sdk"
+
+	"github.com/coinbase/chainsformer/internal/controller/internal"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+type (
+	eventsTable struct{}
+)
+
+func NewEventsTable(params internal.CommonTableParams) internal.Table {
+	return internal.NewBatchTable(
+		&params,
+		internal.NewTableAttributes(internal.TableNameEvents),
+		newEventSchema(),
+		eventsTable{},
+	)
+}
+
+func (t eventsTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
+	aptosBlock, err := parseAptosBlock(ctx, block, parser)
+	if err != nil {
+		return xerrors.Errorf("failed to parse aptos block: %w", err)
+	}
+
+	if err := transformEvents(recordBuilder, aptosBlock, partitioner); err != nil {
+		return xerrors.Errorf("failed to transform events: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,20 @@
+package tables
+
+import (
+	"go.uber.org/fx"
+)
+
+var Module = fx.Options(
+	fx.Provide(fx.Annotated{
+		Group:  "aptos",
+		Target: NewBlocksTable,
+	}),
+	fx.Provide(fx.Annotated{
+		Group:  "aptos",
+		Target: NewTransactionsTable,
+	}),
+	fx.Provide(fx.Annotated{
+		Group:  "aptos",
+		Target: NewEventsTable,
+	}),
+)
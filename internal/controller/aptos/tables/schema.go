@@ -0,0 +1,75 @@
+package tables
+
+import (
+	"github.com/apache/arrow/go/v10/arrow"
+
+	"github.com/coinbase/chainsformer/internal/controller/internal"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+const (
+	// The blocks are range partitioned by the block height,
+	// while the transactions and events are range partitioned by the transaction version.
+	blockRepartitionByRangeDescription   = internal.RepartitionByRangeDescription + ", i.e. the block height"
+	versionRepartitionByRangeDescription = internal.RepartitionByRangeDescription + ", i.e. the transaction version"
+)
+
+func newBlockSchema() *arrow.Schema {
+	f := xarrow.NewSchemaFactory()
+	return internal.NewTableSchema(
+		blockRepartitionByRangeDescription,
+		f.NewField("block_height", arrow.PrimitiveTypes.Uint64, "The height of the block"),
+		f.NewField("hash", arrow.BinaryTypes.String, "Hash of the block"),
+		f.NewField("timestamp", arrow.PrimitiveTypes.Uint64, "The unix timestamp for when the block was produced"),
+		f.NewField("first_version", arrow.PrimitiveTypes.Uint64, "The version of the first transaction in the block, null if the block has no transaction"),
+		f.NewField("last_version", arrow.PrimitiveTypes.Uint64, "The version of the last transaction in the block, null if the block has no transaction"),
+		f.NewField("transaction_count", arrow.PrimitiveTypes.Uint64, "The number of transactions in the block"),
+	)
+}
+
+func newTransactionSchema() *arrow.Schema {
+	f := xarrow.NewSchemaFactory()
+	return internal.NewTableSchema(
+		versionRepartitionByRangeDescription,
+		f.NewField("version", arrow.PrimitiveTypes.Uint64, "The version of the transaction, which is the sequential number of the transaction across all blocks"),
+		f.NewField("transaction_index", arrow.PrimitiveTypes.Uint64, "Zero-based index of the transaction in the block"),
+		f.NewField("hash", arrow.BinaryTypes.String, "Hash of the transaction"),
+		f.NewField("type", arrow.BinaryTypes.String, "The type of the transaction, i.e. GENESIS, BLOCK_METADATA, STATE_CHECKPOINT or USER"),
+		f.NewField("success", arrow.FixedWidthTypes.Boolean, "True if the transaction succeeded"),
+		f.NewField("vm_status", arrow.BinaryTypes.String, "The status of the transaction returned by the Move VM"),
+		f.NewField("gas_used", arrow.PrimitiveTypes.Uint64, "The amount of gas used by the transaction"),
+		f.NewField("state_change_hash", arrow.BinaryTypes.String, "Hash of the state changes made by the transaction"),
+		f.NewField("event_root_hash", arrow.BinaryTypes.String, "Root hash of the events emitted by the transaction"),
+		f.NewField("accumulator_root_hash", arrow.BinaryTypes.String, "Root hash of the transaction accumulator after the transaction"),
+		f.NewField("sender", arrow.BinaryTypes.String, "Address of the sender, null for the non-user transactions"),
+		f.NewField("sequence_number", arrow.PrimitiveTypes.Uint64, "The sequence number of the sender account, null for the non-user transactions"),
+		f.NewField("max_gas_amount", arrow.PrimitiveTypes.Uint64, "The maximum amount of gas allowed for the transaction, null for the non-user transactions"),
+		f.NewField("gas_unit_price", arrow.PrimitiveTypes.Uint64, "The gas unit price in octas, null for the non-user transactions"),
+		f.NewField("expiration_timestamp", arrow.PrimitiveTypes.Uint64, "The unix timestamp after which the transaction expires, null for the non-user transactions"),
+		f.NewField("payload_type", arrow.BinaryTypes.String, "The type of the payload, e.g. ENTRY_FUNCTION_PAYLOAD, null for the non-user transactions"),
+		f.NewField("entry_function", arrow.BinaryTypes.String, "The entry function called by the transaction, e.g. 0x1::coin::transfer, null for the other payloads"),
+		f.NewField("event_count", arrow.PrimitiveTypes.Uint64, "The number of events emitted by the transaction"),
+		f.NewField("change_count", arrow.PrimitiveTypes.Uint64, "The number of state changes made by the transaction"),
+		f.NewField("block_height", arrow.PrimitiveTypes.Uint64, "The height of the block where this transaction was in"),
+		f.NewField("block_hash", arrow.BinaryTypes.String, "Hash of the block where this transaction was in"),
+		f.NewField("block_timestamp", arrow.PrimitiveTypes.Uint64, "The unix timestamp for when the block was produced"),
+	)
+}
+
+func newEventSchema() *arrow.Schema {
+	f := xarrow.NewSchemaFactory()
+	return internal.NewTableSchema(
+		versionRepartitionByRangeDescription,
+		f.NewField("transaction_version", arrow.PrimitiveTypes.Uint64, "The version of the transaction emitting the event"),
+		f.NewField("transaction_hash", arrow.BinaryTypes.String, "Hash of the transaction emitting the event"),
+		f.NewField("event_index", arrow.PrimitiveTypes.Uint64, "Zero-based index of the event in the transaction"),
+		f.NewField("account_address", arrow.BinaryTypes.String, "Address of the account owning the event handle"),
+		f.NewField("creation_number", arrow.PrimitiveTypes.Uint64, "The creation number of the event handle"),
+		f.NewField("sequence_number", arrow.PrimitiveTypes.Uint64, "The sequence number of the event in the event handle"),
+		f.NewField("type", arrow.BinaryTypes.String, "The Move type of the event, e.g. 0x1::coin::DepositEvent"),
+		f.NewField("data", arrow.BinaryTypes.String, "The JSON data of the event"),
+		f.NewField("block_height", arrow.PrimitiveTypes.Uint64, "The height of the block where this event was in"),
+		f.NewField("block_hash", arrow.BinaryTypes.String, "Hash of the block where this event was in"),
+		f.NewField("block_timestamp", arrow.PrimitiveTypes.Uint64, "The unix timestamp for when the block was produced"),
+	)
+}
@@ -0,0 +1,144 @@
+package tables
+
+import (
+	"fmt"
+
+	"golang.org/x/xerrors"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+// The block tables are range partitioned by the block height,
+// while the transaction and event tables are range partitioned by the transaction version,
+// which increases sequentially across all blocks.
+
+func transformBlocks(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.AptosBlock, partitioner *partition.Partitioner) error {
+	header := block.GetHeader()
+	if header == nil {
+		return xerrors.New("header is required")
+	}
+
+	transactions := block.GetTransactions()
+	ra := xarrow.NewRecordAppender(recordBuilder).
+		AppendUint64(header.BlockHeight).
+		AppendString(header.BlockHash).
+		AppendUint64(uint64(header.GetBlockTime().GetSeconds()))
+	if len(transactions) > 0 {
+		ra.AppendUint64(transactions[0].Version).
+			AppendUint64(transactions[len(transactions)-1].Version)
+	} else {
+		ra.AppendNull().
+			AppendNull()
+	}
+
+	ra.AppendUint64(uint64(len(transactions))).
+		AppendUint64(partitioner.GetPartitionBy(header.BlockHeight, 0, header.GetBlockTime().AsTime())).
+		AppendUint64(header.BlockHeight).
+		Build()
+
+	return nil
+}
+
+func transformTransactions(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.AptosBlock, partitioner *partition.Partitioner) error {
+	header := block.GetHeader()
+	if header == nil {
+		return xerrors.New("header is required")
+	}
+
+	partitionBy := partitioner.GetPartitionBy(header.BlockHeight, 0, header.GetBlockTime().AsTime())
+	for transactionIndex, transaction := range block.GetTransactions() {
+		info := transaction.GetInfo()
+		ra := xarrow.NewRecordAppender(recordBuilder).
+			AppendUint64(transaction.Version).
+			AppendUint64(uint64(transactionIndex)).
+			AppendString(info.GetHash()).
+			AppendString(transaction.Type.String()).
+			AppendBool(info.GetSuccess()).
+			AppendString(info.GetVmStatus()).
+			AppendUint64(info.GetGasUsed()).
+			AppendString(info.GetStateChangeHash()).
+			AppendString(info.GetEventRootHash()).
+			AppendString(info.GetAccumulatorRootHash())
+
+		if request := transaction.GetUser().GetRequest(); request != nil {
+			ra.AppendString(request.Sender).
+				AppendUint64(request.SequenceNumber).
+				AppendUint64(request.MaxGasAmount).
+				AppendUint64(request.GasUnitPrice).
+				AppendUint64(uint64(request.GetExpirationTimestampSecs().GetSeconds())).
+				AppendString(request.GetPayload().GetType().String())
+			if function := request.GetPayload().GetEntryFunctionPayload().GetFunction(); function != nil {
+				ra.AppendString(fmt.Sprintf("%v::%v::%v", function.GetModule().GetAddress(), function.GetModule().GetName(), function.FunctionName))
+			} else {
+				ra.AppendNull()
+			}
+		} else {
+			ra.AppendNull().
+				AppendNull().
+				AppendNull().
+				AppendNull().
+				AppendNull().
+				AppendNull().
+				AppendNull()
+		}
+
+		ra.AppendUint64(uint64(len(getEvents(transaction)))).
+			AppendUint64(uint64(len(info.GetChanges()))).
+			AppendUint64(header.BlockHeight).
+			AppendString(header.BlockHash).
+			AppendUint64(uint64(header.GetBlockTime().GetSeconds())).
+			AppendUint64(partitionBy).
+			AppendUint64(transaction.Version).
+			Build()
+	}
+
+	return nil
+}
+
+func transformEvents(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.AptosBlock, partitioner *partition.Partitioner) error {
+	header := block.GetHeader()
+	if header == nil {
+		return xerrors.New("header is required")
+	}
+
+	partitionBy := partitioner.GetPartitionBy(header.BlockHeight, 0, header.GetBlockTime().AsTime())
+	for _, transaction := range block.GetTransactions() {
+		for eventIndex, event := range getEvents(transaction) {
+			xarrow.NewRecordAppender(recordBuilder).
+				AppendUint64(transaction.Version).
+				AppendString(transaction.GetInfo().GetHash()).
+				AppendUint64(uint64(eventIndex)).
+				AppendString(event.GetKey().GetAccountAddress()).
+				AppendUint64(event.GetKey().GetCreationNumber()).
+				AppendUint64(event.SequenceNumber).
+				AppendString(event.Type).
+				AppendString(event.Data).
+				AppendUint64(header.BlockHeight).
+				AppendString(header.BlockHash).
+				AppendUint64(uint64(header.GetBlockTime().GetSeconds())).
+				AppendUint64(partitionBy).
+				AppendUint64(transaction.Version).
+				Build()
+		}
+	}
+
+	return nil
+}
+
+// getEvents returns the events emitted by the transaction.
+// The state checkpoint transactions do not emit any event.
+func getEvents(transaction *chainstorageapi.AptosTransaction) []*chainstorageapi.AptosEvent {
+	switch data := transaction.GetTxnData().(type) {
+	case *chainstorageapi.AptosTransaction_BlockMetadata:
+		return data.BlockMetadata.GetEvents()
+	case *chainstorageapi.AptosTransaction_Genesis:
+		return data.Genesis.GetEvents()
+	case *chainstorageapi.AptosTransaction_User:
+		return data.User.GetEvents()
+	default:
+		return nil
+	}
+}
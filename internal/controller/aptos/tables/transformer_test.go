@@ -0,0 +1,210 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v10/arrow"
+	"github.com/apache/arrow/go/v10/arrow/array"
+	"github.com/apache/arrow/go/v10/arrow/memory"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+func TestTransformBlocks(t *testing.T) {
+	require := require.New(t)
+
+	block := newTestBlock()
+	schema := newBlockSchema()
+	recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), schema, nil)
+	defer recordBuilder.Release()
+
+	require.NoError(transformBlocks(recordBuilder, block, partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+	rec := recordBuilder.NewRecord()
+	defer rec.Release()
+	require.Equal(int64(1), rec.NumRows())
+	require.Equal(uint64(100), rec.Column(schema.FieldIndices("block_height")[0]).(*array.Uint64).Value(0))
+	require.Equal("0xabc", rec.Column(schema.FieldIndices("hash")[0]).(*array.String).Value(0))
+	require.Equal(uint64(1000), rec.Column(schema.FieldIndices("first_version")[0]).(*array.Uint64).Value(0))
+	require.Equal(uint64(1002), rec.Column(schema.FieldIndices("last_version")[0]).(*array.Uint64).Value(0))
+	require.Equal(uint64(3), rec.Column(schema.FieldIndices("transaction_count")[0]).(*array.Uint64).Value(0))
+	require.Equal(uint64(100), rec.Column(schema.FieldIndices("_repartition_by_range")[0]).(*array.Uint64).Value(0))
+}
+
+func TestTransformBlocks_NoTransactions(t *testing.T) {
+	require := require.New(t)
+
+	block := newTestBlock()
+	block.Transactions = nil
+	schema := newBlockSchema()
+	recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), schema, nil)
+	defer recordBuilder.Release()
+
+	require.NoError(transformBlocks(recordBuilder, block, partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+	rec := recordBuilder.NewRecord()
+	defer rec.Release()
+	require.True(rec.Column(schema.FieldIndices("first_version")[0]).IsNull(0))
+	require.True(rec.Column(schema.FieldIndices("last_version")[0]).IsNull(0))
+}
+
+func TestTransformTransactions(t *testing.T) {
+	require := require.New(t)
+
+	block := newTestBlock()
+	schema := newTransactionSchema()
+	recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), schema, nil)
+	defer recordBuilder.Release()
+
+	require.NoError(transformTransactions(recordBuilder, block, partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+	rec := recordBuilder.NewRecord()
+	defer rec.Release()
+	require.Equal(int64(3), rec.NumRows())
+
+	expectedVersion := []uint64{1000, 1001, 1002}
+	expectedType := []string{"BLOCK_METADATA", "USER", "STATE_CHECKPOINT"}
+	expectedEventCount := []uint64{1, 2, 0}
+	for i := range expectedVersion {
+		require.Equal(expectedVersion[i], rec.Column(schema.FieldIndices("version")[0]).(*array.Uint64).Value(i))
+		require.Equal(uint64(i), rec.Column(schema.FieldIndices("transaction_index")[0]).(*array.Uint64).Value(i))
+		require.Equal(expectedType[i], rec.Column(schema.FieldIndices("type")[0]).(*array.String).Value(i))
+		require.Equal(expectedEventCount[i], rec.Column(schema.FieldIndices("event_count")[0]).(*array.Uint64).Value(i))
+		require.Equal(uint64(100), rec.Column(schema.FieldIndices("block_height")[0]).(*array.Uint64).Value(i))
+		require.Equal(expectedVersion[i], rec.Column(schema.FieldIndices("_repartition_by_range")[0]).(*array.Uint64).Value(i))
+	}
+
+	sender := rec.Column(schema.FieldIndices("sender")[0]).(*array.String)
+	require.True(sender.IsNull(0))
+	require.Equal("0xsender", sender.Value(1))
+	require.Equal(uint64(7), rec.Column(schema.FieldIndices("sequence_number")[0]).(*array.Uint64).Value(1))
+	require.Equal("ENTRY_FUNCTION_PAYLOAD", rec.Column(schema.FieldIndices("payload_type")[0]).(*array.String).Value(1))
+	require.Equal("0x1::coin::transfer", rec.Column(schema.FieldIndices("entry_function")[0]).(*array.String).Value(1))
+	require.True(rec.Column(schema.FieldIndices("entry_function")[0]).IsNull(2))
+}
+
+func TestTransformEvents(t *testing.T) {
+	require := require.New(t)
+
+	block := newTestBlock()
+	schema := newEventSchema()
+	recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), schema, nil)
+	defer recordBuilder.Release()
+
+	require.NoError(transformEvents(recordBuilder, block, partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+	rec := recordBuilder.NewRecord()
+	defer rec.Release()
+	require.Equal(int64(3), rec.NumRows())
+
+	expectedVersion := []uint64{1000, 1001, 1001}
+	expectedEventIndex := []uint64{0, 0, 1}
+	expectedType := []string{"0x1::block::NewBlockEvent", "0x1::coin::WithdrawEvent", "0x1::coin::DepositEvent"}
+	for i := range expectedVersion {
+		require.Equal(expectedVersion[i], rec.Column(schema.FieldIndices("transaction_version")[0]).(*array.Uint64).Value(i))
+		require.Equal(expectedEventIndex[i], rec.Column(schema.FieldIndices("event_index")[0]).(*array.Uint64).Value(i))
+		require.Equal(expectedType[i], rec.Column(schema.FieldIndices("type")[0]).(*array.String).Value(i))
+		require.Equal(expectedVersion[i], rec.Column(schema.FieldIndices("_repartition_by_range")[0]).(*array.Uint64).Value(i))
+	}
+	require.Equal("0xsender", rec.Column(schema.FieldIndices("account_address")[0]).(*array.String).Value(1))
+	require.Equal(`{"amount":"10"}`, rec.Column(schema.FieldIndices("data")[0]).(*array.String).Value(1))
+}
+
+func TestSchemas_RepartitionByRangeDescription(t *testing.T) {
+	require := require.New(t)
+
+	for _, tc := range []struct {
+		name     string
+		schema   *arrow.Schema
+		expected string
+	}{
+		{name: "blocks", schema: newBlockSchema(), expected: "Records will be range partitioned based on the _repartition_by_range column, i.e. the block height"},
+		{name: "transactions", schema: newTransactionSchema(), expected: "Records will be range partitioned based on the _repartition_by_range column, i.e. the transaction version"},
+		{name: "events", schema: newEventSchema(), expected: "Records will be range partitioned based on the _repartition_by_range column, i.e. the transaction version"},
+	} {
+		field := tc.schema.Field(tc.schema.FieldIndices("_repartition_by_range")[0])
+		require.Equal(tc.expected, field.Metadata.Values()[field.Metadata.FindKey("description")], tc.name)
+	}
+}
+
+func newTestBlock() *chainstorageapi.AptosBlock {
+	blockTime := &timestamppb.Timestamp{Seconds: 1700000000}
+	return &chainstorageapi.AptosBlock{
+		Header: &chainstorageapi.AptosHeader{
+			BlockHeight: 100,
+			BlockHash:   "0xabc",
+			BlockTime:   blockTime,
+		},
+		Transactions: []*chainstorageapi.AptosTransaction{
+			{
+				Version:     1000,
+				BlockHeight: 100,
+				Timestamp:   blockTime,
+				Info:        &chainstorageapi.AptosTransactionInfo{Hash: "0x1", Success: true},
+				Type:        chainstorageapi.AptosTransaction_BLOCK_METADATA,
+				TxnData: &chainstorageapi.AptosTransaction_BlockMetadata{
+					BlockMetadata: &chainstorageapi.AptosBlockMetadataTransaction{
+						Events: []*chainstorageapi.AptosEvent{
+							newTestEvent("0x1", "0x1::block::NewBlockEvent", `{}`),
+						},
+					},
+				},
+			},
+			{
+				Version:     1001,
+				BlockHeight: 100,
+				Timestamp:   blockTime,
+				Info:        &chainstorageapi.AptosTransactionInfo{Hash: "0x2", Success: true, GasUsed: 10},
+				Type:        chainstorageapi.AptosTransaction_USER,
+				TxnData: &chainstorageapi.AptosTransaction_User{
+					User: &chainstorageapi.AptosUserTransaction{
+						Request: &chainstorageapi.AptosUserTransactionRequest{
+							Sender:         "0xsender",
+							SequenceNumber: 7,
+							MaxGasAmount:   2000,
+							GasUnitPrice:   100,
+							Payload: &chainstorageapi.AptosTransactionPayload{
+								Type: chainstorageapi.AptosTransactionPayload_ENTRY_FUNCTION_PAYLOAD,
+								Payload: &chainstorageapi.AptosTransactionPayload_EntryFunctionPayload{
+									EntryFunctionPayload: &chainstorageapi.AptosEntryFunctionPayload{
+										Function: &chainstorageapi.AptosEntryFunctionId{
+											Module:       &chainstorageapi.AptosMoveModuleId{Address: "0x1", Name: "coin"},
+											FunctionName: "transfer",
+										},
+									},
+								},
+							},
+						},
+						Events: []*chainstorageapi.AptosEvent{
+							newTestEvent("0xsender", "0x1::coin::WithdrawEvent", `{"amount":"10"}`),
+							newTestEvent("0xreceiver", "0x1::coin::DepositEvent", `{"amount":"10"}`),
+						},
+					},
+				},
+			},
+			{
+				Version:     1002,
+				BlockHeight: 100,
+				Timestamp:   blockTime,
+				Info:        &chainstorageapi.AptosTransactionInfo{Hash: "0x3", Success: true},
+				Type:        chainstorageapi.AptosTransaction_STATE_CHECKPOINT,
+				TxnData: &chainstorageapi.AptosTransaction_StateCheckpoint{
+					StateCheckpoint: &chainstorageapi.AptosStateCheckpointTransaction{},
+				},
+			},
+		},
+	}
+}
+
+func newTestEvent(accountAddress string, eventType string, data string) *chainstorageapi.AptosEvent {
+	return &chainstorageapi.AptosEvent{
+		Key:  &chainstorageapi.AptosEventKey{AccountAddress: accountAddress},
+		Type: eventType,
+		Data: data,
+	}
+}
@@ -3,7 +3,6 @@ package tables
 import (
 	"context"
 
-	"github.com/apache/arrow/go/v10/arrow/array"
 	"golang.org/x/xerrors"
 
 	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
@@ -11,18 +10,25 @@ import (
 
 	"github.com/coinbase/chainsformer/internal/config"
 	"github.com/coinbase/chainsformer/internal/controller/internal"
+	"github.com/coinbase/chainsformer/internal/controller/internal/constant"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
 )
 
 type (
 	blocksTable struct {
 		config *config.Config
 	}
+
+	nativeStreamedBlocksTable struct {
+		config *config.Config
+	}
 )
 
 func NewBlocksTable(params internal.CommonTableParams) internal.Table {
 	return internal.NewBatchTable(
 		&params,
-		internal.NewTableAttributes(internal.TableNameBlocks),
+		internal.NewTableAttributes(internal.TableNameBlocks, internal.WithEstimateMode(constant.EstimateModeBlocks)),
 		newBlockSchema(),
 		blocksTable{
 			params.Config,
@@ -30,7 +36,7 @@ func NewBlocksTable(params internal.CommonTableParams) internal.Table {
 	)
 }
 
-func (t blocksTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *array.RecordBuilder, partitionBySize uint64) error {
+func (t blocksTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
 	nativeBlock, err := parser.ParseNativeBlock(ctx, block)
 	if err != nil {
 		return xerrors.Errorf("failed to parse raw block to native block: %w", err)
@@ -41,7 +47,37 @@ func (t blocksTable) TransformBlock(ctx context.Context, block *chainstorageapi.
 		return xerrors.New("failed to extract bitcoin block from native block")
 	}
 
-	if err := t.transformBlocks(recordBuilder, bitcoinBlock, partitionBySize); err != nil {
+	if err := t.transformBlocks(recordBuilder, bitcoinBlock, partitioner); err != nil {
+		return xerrors.Errorf("failed to transform blocks: %w", err)
+	}
+
+	return nil
+}
+
+func NewNativeStreamedBlocksTable(params internal.CommonTableParams) internal.Table {
+	return internal.NewStreamTable(
+		&params,
+		internal.NewTableAttributes(internal.TableNameStreamedBlocks),
+		newStreamedBlockSchema(),
+		nativeStreamedBlocksTable{
+			params.Config,
+		},
+		params.Params.Config.Table.StreamTable,
+	)
+}
+
+func (t nativeStreamedBlocksTable) TransformBlock(ctx context.Context, blockAndEvent *internal.BlockAndEvent, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
+	nativeBlock, err := parser.ParseNativeBlock(ctx, blockAndEvent.Block)
+	if err != nil {
+		return xerrors.Errorf("failed to parse raw block to native block: %w", err)
+	}
+
+	bitcoinBlock := nativeBlock.GetBitcoin()
+	if bitcoinBlock == nil {
+		return xerrors.New("failed to extract bitcoin block from native block")
+	}
+
+	if err := t.transformStreamedBlocks(recordBuilder, bitcoinBlock, blockAndEvent.BlockChainEvent, partitioner); err != nil {
 		return xerrors.Errorf("failed to transform blocks: %w", err)
 	}
 
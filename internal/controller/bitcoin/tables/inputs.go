@@ -0,0 +1,45 @@
+package tables
+
+import (
+	"context"
+
+	"golang.org/x/xerrors"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+	"github.com/coinbase/chainstorage/sdk"
+
+	"github.com/coinbase/chainsformer/internal/controller/internal"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+type (
+	inputsTable struct{}
+)
+
+func NewInputsTable(params internal.CommonTableParams) internal.Table {
+	return internal.NewBatchTable(
+		&params,
+		internal.NewTableAttributes(internal.TableNameInputs),
+		newInputSchema(),
+		inputsTable{},
+	)
+}
+
+func (t inputsTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
+	nativeBlock, err := parser.ParseNativeBlock(ctx, block)
+	if err != nil {
+		return xerrors.Errorf("failed to parse raw block to native block: %w", err)
+	}
+
+	bitcoinBlock := nativeBlock.GetBitcoin()
+	if bitcoinBlock == nil {
+		return xerrors.New("failed to extract bitcoin block from native block")
+	}
+
+	if err := t.transformInputs(recordBuilder, bitcoinBlock, partitioner); err != nil {
+		return xerrors.Errorf("failed to transform inputs: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v10/arrow/array"
+	"github.com/apache/arrow/go/v10/arrow/memory"
+	"github.com/stretchr/testify/require"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+const (
+	testAddress = "bc1qxy2kgdygjrsqtzq2n0yrf2493p83kkfjhx0wlh"
+)
+
+func TestTransformInputsAndOutputs(t *testing.T) {
+	require := require.New(t)
+
+	block := &chainstorageapi.BitcoinBlock{
+		Header: &chainstorageapi.BitcoinHeader{
+			Hash:   "0xabc",
+			Height: 100,
+			Time:   1700000000,
+		},
+		Transactions: []*chainstorageapi.BitcoinTransaction{
+			{
+				TransactionId: "tx0",
+				IsCoinbase:    true,
+				Inputs: []*chainstorageapi.BitcoinTransactionInput{
+					{Coinbase: "03a0860100"},
+				},
+				Outputs: []*chainstorageapi.BitcoinTransactionOutput{
+					{Index: 0, Value: 625000000, ScriptPublicKey: &chainstorageapi.BitcoinScriptPublicKey{Address: testAddress, Type: "witness_v0_keyhash"}},
+				},
+			},
+			{
+				TransactionId: "tx1",
+				Index:         1,
+				Inputs: []*chainstorageapi.BitcoinTransactionInput{
+					{
+						TransactionId:   "tx0",
+						FromOutputIndex: 0,
+						FromOutput: &chainstorageapi.BitcoinTransactionOutput{
+							Value:           625000000,
+							ScriptPublicKey: &chainstorageapi.BitcoinScriptPublicKey{Address: testAddress, Type: "witness_v0_keyhash"},
+						},
+					},
+				},
+				Outputs: []*chainstorageapi.BitcoinTransactionOutput{
+					{Index: 0, Value: 600000000},
+					{Index: 1, Value: 24000000},
+				},
+			},
+		},
+	}
+
+	inputSchema := newInputSchema()
+	inputBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), inputSchema, nil)
+	defer inputBuilder.Release()
+	require.NoError(inputsTable{}.transformInputs(inputBuilder, block, partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+	inputs := inputBuilder.NewRecord()
+	defer inputs.Release()
+	require.Equal(int64(2), inputs.NumRows())
+	require.Equal("03a0860100", inputs.Column(inputSchema.FieldIndices("coinbase")[0]).(*array.String).Value(0))
+	require.Equal("tx1", inputs.Column(inputSchema.FieldIndices("transaction_hash")[0]).(*array.String).Value(1))
+	require.Equal("tx0", inputs.Column(inputSchema.FieldIndices("spent_transaction_hash")[0]).(*array.String).Value(1))
+	require.Equal(testAddress, inputs.Column(inputSchema.FieldIndices("address")[0]).(*array.String).Value(1))
+	require.Equal(uint64(625000000), inputs.Column(inputSchema.FieldIndices("value")[0]).(*array.Uint64).Value(1))
+	require.Equal(uint64(1700000000), inputs.Column(inputSchema.FieldIndices("block_timestamp")[0]).(*array.Uint64).Value(1))
+
+	outputSchema := newOutputSchema()
+	outputBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), outputSchema, nil)
+	defer outputBuilder.Release()
+	require.NoError(outputsTable{}.transformOutputs(outputBuilder, block, partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+	outputs := outputBuilder.NewRecord()
+	defer outputs.Release()
+	require.Equal(int64(3), outputs.NumRows())
+	expectedTransactionHash := []string{"tx0", "tx1", "tx1"}
+	expectedValue := []uint64{625000000, 600000000, 24000000}
+	for i := range expectedValue {
+		require.Equal(expectedTransactionHash[i], outputs.Column(outputSchema.FieldIndices("transaction_hash")[0]).(*array.String).Value(i))
+		require.Equal(expectedValue[i], outputs.Column(outputSchema.FieldIndices("value")[0]).(*array.Uint64).Value(i))
+		require.Equal(uint64(100), outputs.Column(outputSchema.FieldIndices("block_number")[0]).(*array.Uint64).Value(i))
+	}
+}
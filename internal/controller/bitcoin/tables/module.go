@@ -13,4 +13,36 @@ var Module = fx.Options(
 		Group:  "bitcoin",
 		Target: NewBlocksTable,
 	}),
+	fx.Provide(fx.Annotated{
+		Group:  "bitcoin",
+		Target: NewInputsTable,
+	}),
+	fx.Provide(fx.Annotated{
+		Group:  "bitcoin",
+		Target: NewOutputsTable,
+	}),
+	fx.Provide(fx.Annotated{
+		Group:  "bitcoin",
+		Target: NewNativeStreamedTransactionsTable,
+	}),
+	fx.Provide(fx.Annotated{
+		Group:  "bitcoin",
+		Target: NewNativeStreamedBlocksTable,
+	}),
+	fx.Provide(fx.Annotated{
+		Group:  "bitcoin",
+		Target: NewRawNativeTransactionsTable,
+	}),
+	fx.Provide(fx.Annotated{
+		Group:  "bitcoin",
+		Target: NewRawNativeStreamedTransactionsTable,
+	}),
+	fx.Provide(fx.Annotated{
+		Group:  "bitcoin",
+		Target: NewRawNativeBlocksTable,
+	}),
+	fx.Provide(fx.Annotated{
+		Group:  "bitcoin",
+		Target: NewRawNativeStreamedBlocksTable,
+	}),
 )
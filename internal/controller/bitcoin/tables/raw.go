@@ -0,0 +1,136 @@
+package tables
+
+import (
+	"context"
+
+	"golang.org/x/xerrors"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+	"github.com/coinbase/chainstorage/sdk"
+
+	"github.com/coinbase/chainsformer/internal/controller/internal"
+	"github.com/coinbase/chainsformer/internal/controller/internal/constant"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+type (
+	rawNativeTransactionsTable         struct{}
+	rawNativeStreamedTransactionsTable struct{}
+	rawNativeBlocksTable               struct{}
+	rawNativeStreamedBlocksTable       struct{}
+)
+
+func NewRawNativeTransactionsTable(params internal.CommonTableParams) internal.Table {
+	return internal.NewBatchTable(
+		&params,
+		internal.NewTableAttributes(internal.TableNameTransactions,
+			internal.WithFormat(constant.TableFormatNative),
+			internal.WithEncoding(constant.EncodingRaw),
+			internal.WithEstimateMode(constant.EstimateModeTransactions)),
+		newRawTransactionSchema(),
+		rawNativeTransactionsTable{},
+	)
+}
+
+func (t rawNativeTransactionsTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
+	bitcoinBlock, err := parseBitcoinBlock(ctx, block, parser)
+	if err != nil {
+		return err
+	}
+
+	if err := t.transformRawTransactions(recordBuilder, bitcoinBlock, partitioner); err != nil {
+		return xerrors.Errorf("failed to transform transactions: %w", err)
+	}
+
+	return nil
+}
+
+func NewRawNativeStreamedTransactionsTable(params internal.CommonTableParams) internal.Table {
+	return internal.NewStreamTable(
+		&params,
+		internal.NewTableAttributes(internal.TableNameStreamedTransactions,
+			internal.WithFormat(constant.TableFormatNative),
+			internal.WithEncoding(constant.EncodingRaw)),
+		newRawStreamedTransactionSchema(),
+		rawNativeStreamedTransactionsTable{},
+		params.Params.Config.Table.StreamTable,
+	)
+}
+
+func (t rawNativeStreamedTransactionsTable) TransformBlock(ctx context.Context, blockAndEvent *internal.BlockAndEvent, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
+	bitcoinBlock, err := parseBitcoinBlock(ctx, blockAndEvent.Block, parser)
+	if err != nil {
+		return err
+	}
+
+	if err := t.transformRawStreamedTransactions(recordBuilder, bitcoinBlock, blockAndEvent.BlockChainEvent, partitioner); err != nil {
+		return xerrors.Errorf("failed to transform transactions: %w", err)
+	}
+
+	return nil
+}
+
+func NewRawNativeBlocksTable(params internal.CommonTableParams) internal.Table {
+	return internal.NewBatchTable(
+		&params,
+		internal.NewTableAttributes(internal.TableNameBlocks,
+			internal.WithFormat(constant.TableFormatNative),
+			internal.WithEncoding(constant.EncodingRaw),
+			internal.WithEstimateMode(constant.EstimateModeBlocks)),
+		newRawBlockSchema(),
+		rawNativeBlocksTable{},
+	)
+}
+
+func (t rawNativeBlocksTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
+	bitcoinBlock, err := parseBitcoinBlock(ctx, block, parser)
+	if err != nil {
+		return err
+	}
+
+	if err := t.transformRawBlocks(recordBuilder, bitcoinBlock, partitioner); err != nil {
+		return xerrors.Errorf("failed to transform blocks: %w", err)
+	}
+
+	return nil
+}
+
+func NewRawNativeStreamedBlocksTable(params internal.CommonTableParams) internal.Table {
+	return internal.NewStreamTable(
+		&params,
+		internal.NewTableAttributes(internal.TableNameStreamedBlocks,
+			internal.WithFormat(constant.TableFormatNative),
+			internal.WithEncoding(constant.EncodingRaw)),
+		newRawStreamedBlockSchema(),
+		rawNativeStreamedBlocksTable{},
+		params.Params.Config.Table.StreamTable,
+	)
+}
+
+func (t rawNativeStreamedBlocksTable) TransformBlock(ctx context.Context, blockAndEvent *internal.BlockAndEvent, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
+	bitcoinBlock, err := parseBitcoinBlock(ctx, blockAndEvent.Block, parser)
+	if err != nil {
+		return err
+	}
+
+	if err := t.transformRawStreamedBlocks(recordBuilder, bitcoinBlock, blockAndEvent.BlockChainEvent, partitioner); err != nil {
+		return xerrors.Errorf("failed to transform blocks: %w", err)
+	}
+
+	return nil
+}
+
+func parseBitcoinBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser) (*chainstorageapi.BitcoinBlock, error) {
+	nativeBlock, err := parser.ParseNativeBlock(ctx, block)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to parse raw block to native block: %w", err)
+	}
+
+	bitcoinBlock := nativeBlock.GetBitcoin()
+	if bitcoinBlock == nil {
+		return nil, xerrors.New("failed to extract bitcoin block from native block")
+	}
+
+	return bitcoinBlock, nil
+}
@@ -0,0 +1,68 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v10/arrow/array"
+	"github.com/apache/arrow/go/v10/arrow/memory"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+func TestTransformRawTransactionsAndBlocks(t *testing.T) {
+	require := require.New(t)
+
+	block := &chainstorageapi.BitcoinBlock{
+		Header: &chainstorageapi.BitcoinHeader{
+			Hash:              "0xabc",
+			PreviousBlockHash: "0xabb",
+			Height:            100,
+			Time:              1700000000,
+		},
+		Transactions: []*chainstorageapi.BitcoinTransaction{
+			{TransactionId: "tx0", IsCoinbase: true},
+			{TransactionId: "tx1", Index: 1, Fee: 1000},
+		},
+	}
+
+	transactionSchema := newRawTransactionSchema()
+	transactionBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), transactionSchema, nil)
+	defer transactionBuilder.Release()
+	require.NoError(rawNativeTransactionsTable{}.transformRawTransactions(transactionBuilder, block, partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+	transactions := transactionBuilder.NewRecord()
+	defer transactions.Release()
+	require.Equal(int64(2), transactions.NumRows())
+	for i, expected := range block.Transactions {
+		require.Equal(expected.TransactionId, transactions.Column(transactionSchema.FieldIndices("transaction_hash")[0]).(*array.String).Value(i))
+
+		var actual chainstorageapi.BitcoinTransaction
+		require.NoError(proto.Unmarshal(transactions.Column(transactionSchema.FieldIndices("transaction_data")[0]).(*array.Binary).Value(i), &actual))
+		require.True(proto.Equal(expected, &actual))
+	}
+
+	event := &chainstorageapi.BlockchainEvent{
+		SequenceNum: 42,
+		Type:        chainstorageapi.BlockchainEvent_BLOCK_ADDED,
+	}
+	blockSchema := newRawStreamedBlockSchema()
+	blockBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), blockSchema, nil)
+	defer blockBuilder.Release()
+	require.NoError(rawNativeStreamedBlocksTable{}.transformRawStreamedBlocks(blockBuilder, block, event, partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+	blocks := blockBuilder.NewRecord()
+	defer blocks.Release()
+	require.Equal(int64(1), blocks.NumRows())
+	require.Equal("BLOCK_ADDED", blocks.Column(blockSchema.FieldIndices("_event_type")[0]).(*array.String).Value(0))
+	require.Equal("0xabb", blocks.Column(blockSchema.FieldIndices("previous_block_hash")[0]).(*array.String).Value(0))
+
+	var actual chainstorageapi.BitcoinBlock
+	require.NoError(proto.Unmarshal(blocks.Column(blockSchema.FieldIndices("block_data")[0]).(*array.Binary).Value(0), &actual))
+	require.True(proto.Equal(block, &actual))
+	require.Len(actual.Transactions, 2)
+}
@@ -3,6 +3,7 @@ package tables
 import (
 	"github.com/apache/arrow/go/v10/arrow"
 
+	"github.com/coinbase/chainsformer/internal/controller/internal"
 	"github.com/coinbase/chainsformer/internal/utils/xarrow"
 )
 
@@ -31,6 +32,101 @@ func newTransactionSchema() *arrow.Schema {
 	return transaction
 }
 
+func newStreamedTransactionSchema() *arrow.Schema {
+	transactionSchema := newTransactionSchema()
+	f := xarrow.NewSchemaFactory()
+
+	metadataFields := []arrow.Field{
+		f.NewField("_sequence_number", arrow.PrimitiveTypes.Int64, "Monotonically increasing event sequence number"),
+		f.NewField("_event_type", arrow.BinaryTypes.String, "Event type UNKNOWN, BLOCK_ADDED, BLOCK_REMOVED"),
+	}
+
+	return f.NewSchema(
+		append(metadataFields, transactionSchema.Fields()...)...,
+	)
+}
+
+func newRawTransactionSchema() *arrow.Schema {
+	f := xarrow.NewSchemaFactory()
+	return f.NewSchema(
+		f.NewField("transaction_hash", arrow.BinaryTypes.String, "The transaction hash"),
+		f.NewField("transaction_index", arrow.PrimitiveTypes.Uint64, "The transaction index"),
+		f.NewField("block_hash", arrow.BinaryTypes.String, "The block hash"),
+		f.NewField("block_number", arrow.PrimitiveTypes.Uint64, "The block height or number"),
+		f.NewField("block_timestamp", arrow.PrimitiveTypes.Uint64, "The block creation time expressed in UNIX epoch time"),
+		f.NewField("transaction_data", arrow.BinaryTypes.Binary, "The native transaction data in protobuf format"),
+		f.NewField("_partition_by", arrow.PrimitiveTypes.Uint64, "Records with the same _partition_by value will be stored in the same s3 directory"),
+		f.NewField("_repartition_by_range", arrow.PrimitiveTypes.Uint64, "Records will be range partitioned base on the _repartition_by_range column"),
+	)
+}
+
+func newRawStreamedTransactionSchema() *arrow.Schema {
+	transactionSchema := newRawTransactionSchema()
+	f := xarrow.NewSchemaFactory()
+
+	metadataFields := []arrow.Field{
+		f.NewField("_sequence_number", arrow.PrimitiveTypes.Int64, "Monotonically increasing event sequence number"),
+		f.NewField("_event_type", arrow.BinaryTypes.String, "Event type UNKNOWN, BLOCK_ADDED, BLOCK_REMOVED"),
+	}
+
+	return f.NewSchema(
+		append(metadataFields, transactionSchema.Fields()...)...,
+	)
+}
+
+func newRawBlockSchema() *arrow.Schema {
+	f := xarrow.NewSchemaFactory()
+	return f.NewSchema(
+		f.NewField("block_hash", arrow.BinaryTypes.String, "The block hash"),
+		f.NewField("block_number", arrow.PrimitiveTypes.Uint64, "The block height or number"),
+		f.NewField("block_timestamp", arrow.PrimitiveTypes.Uint64, "The block creation time expressed in UNIX epoch time"),
+		f.NewField("previous_block_hash", arrow.BinaryTypes.String, "The hash of the previous block"),
+		f.NewField("block_data", arrow.BinaryTypes.Binary, "The native block, i.e. the header and the transactions, in protobuf format"),
+		f.NewField("_partition_by", arrow.PrimitiveTypes.Uint64, "Records with the same _partition_by value will be stored in the same s3 directory"),
+		f.NewField("_repartition_by_range", arrow.PrimitiveTypes.Uint64, "Records will be range partitioned base on the _repartition_by_range column"),
+	)
+}
+
+func newRawStreamedBlockSchema() *arrow.Schema {
+	blockSchema := newRawBlockSchema()
+	f := xarrow.NewSchemaFactory()
+
+	metadataFields := []arrow.Field{
+		f.NewField("_sequence_number", arrow.PrimitiveTypes.Int64, "Monotonically increasing event sequence number"),
+		f.NewField("_event_type", arrow.BinaryTypes.String, "Event type UNKNOWN, BLOCK_ADDED, BLOCK_REMOVED"),
+	}
+
+	return f.NewSchema(
+		append(metadataFields, blockSchema.Fields()...)...,
+	)
+}
+
+func newInputSchema() *arrow.Schema {
+	f := xarrow.NewSchemaFactory()
+	fields := append(
+		newTransactionInputDataType().(*arrow.StructType).Fields(),
+		f.NewField("transaction_hash", arrow.BinaryTypes.String, "The hash of the transaction spending the output"),
+		f.NewField("transaction_index", arrow.PrimitiveTypes.Uint64, "The transaction index"),
+		f.NewField("block_hash", arrow.BinaryTypes.String, "The block hash"),
+		f.NewField("block_number", arrow.PrimitiveTypes.Uint64, "The block height or number"),
+		f.NewField("block_timestamp", arrow.PrimitiveTypes.Uint64, "The block creation time expressed in UNIX epoch time"),
+	)
+	return internal.NewTableSchema(internal.RepartitionByRangeDescription, fields...)
+}
+
+func newOutputSchema() *arrow.Schema {
+	f := xarrow.NewSchemaFactory()
+	fields := append(
+		newTransactionOutputDataType().(*arrow.StructType).Fields(),
+		f.NewField("transaction_hash", arrow.BinaryTypes.String, "The hash of the transaction creating the output"),
+		f.NewField("transaction_index", arrow.PrimitiveTypes.Uint64, "The transaction index"),
+		f.NewField("block_hash", arrow.BinaryTypes.String, "The block hash"),
+		f.NewField("block_number", arrow.PrimitiveTypes.Uint64, "The block height or number"),
+		f.NewField("block_timestamp", arrow.PrimitiveTypes.Uint64, "The block creation time expressed in UNIX epoch time"),
+	)
+	return internal.NewTableSchema(internal.RepartitionByRangeDescription, fields...)
+}
+
 func newBlockSchema() *arrow.Schema {
 	f := xarrow.NewSchemaFactory()
 	return f.NewSchema(
@@ -55,6 +151,20 @@ func newBlockSchema() *arrow.Schema {
 	)
 }
 
+func newStreamedBlockSchema() *arrow.Schema {
+	blockSchema := newBlockSchema()
+	f := xarrow.NewSchemaFactory()
+
+	metadataFields := []arrow.Field{
+		f.NewField("_sequence_number", arrow.PrimitiveTypes.Int64, "Monotonically increasing event sequence number"),
+		f.NewField("_event_type", arrow.BinaryTypes.String, "Event type UNKNOWN, BLOCK_ADDED, BLOCK_REMOVED"),
+	}
+
+	return f.NewSchema(
+		append(metadataFields, blockSchema.Fields()...)...,
+	)
+}
+
 func newBlockDataType() arrow.DataType {
 	f := xarrow.NewSchemaFactory()
 	return f.NewStruct(
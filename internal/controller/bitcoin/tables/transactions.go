@@ -3,7 +3,6 @@ package tables
 import (
 	"context"
 
-	"github.com/apache/arrow/go/v10/arrow/array"
 	"golang.org/x/xerrors"
 
 	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
@@ -11,18 +10,25 @@ import (
 
 	"github.com/coinbase/chainsformer/internal/config"
 	"github.com/coinbase/chainsformer/internal/controller/internal"
+	"github.com/coinbase/chainsformer/internal/controller/internal/constant"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
 )
 
 type (
 	transactionsTable struct {
 		config *config.Config
 	}
+
+	nativeStreamedTransactionsTable struct {
+		config *config.Config
+	}
 )
 
 func NewTransactionsTable(params internal.CommonTableParams) internal.Table {
 	return internal.NewBatchTable(
 		&params,
-		internal.NewTableAttributes(internal.TableNameTransactions),
+		internal.NewTableAttributes(internal.TableNameTransactions, internal.WithEstimateMode(constant.EstimateModeTransactions)),
 		newTransactionSchema(),
 		transactionsTable{
 			params.Config,
@@ -30,7 +36,7 @@ func NewTransactionsTable(params internal.CommonTableParams) internal.Table {
 	)
 }
 
-func (t transactionsTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *array.RecordBuilder, partitionBySize uint64) error {
+func (t transactionsTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
 	nativeBlock, err := parser.ParseNativeBlock(ctx, block)
 	if err != nil {
 		return xerrors.Errorf("failed to parse raw block to native block: %w", err)
@@ -41,7 +47,37 @@ func (t transactionsTable) TransformBlock(ctx context.Context, block *chainstora
 		return xerrors.New("failed to extract bitcoin block from native block")
 	}
 
-	if err := t.transformTransactions(recordBuilder, bitcoinBlock, partitionBySize); err != nil {
+	if err := t.transformTransactions(recordBuilder, bitcoinBlock, partitioner); err != nil {
+		return xerrors.Errorf("failed to transform transactions: %w", err)
+	}
+
+	return nil
+}
+
+func NewNativeStreamedTransactionsTable(params internal.CommonTableParams) internal.Table {
+	return internal.NewStreamTable(
+		&params,
+		internal.NewTableAttributes(internal.TableNameStreamedTransactions),
+		newStreamedTransactionSchema(),
+		nativeStreamedTransactionsTable{
+			params.Config,
+		},
+		params.Params.Config.Table.StreamTable,
+	)
+}
+
+func (t nativeStreamedTransactionsTable) TransformBlock(ctx context.Context, blockAndEvent *internal.BlockAndEvent, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
+	nativeBlock, err := parser.ParseNativeBlock(ctx, blockAndEvent.Block)
+	if err != nil {
+		return xerrors.Errorf("failed to parse raw block to native block: %w", err)
+	}
+
+	bitcoinBlock := nativeBlock.GetBitcoin()
+	if bitcoinBlock == nil {
+		return xerrors.New("failed to extract bitcoin block from native block")
+	}
+
+	if err := t.transformStreamedTransactions(recordBuilder, bitcoinBlock, blockAndEvent.BlockChainEvent, partitioner); err != nil {
 		return xerrors.Errorf("failed to transform transactions: %w", err)
 	}
 
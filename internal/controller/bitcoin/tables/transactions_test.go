@@ -0,0 +1,93 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v10/arrow/array"
+	"github.com/apache/arrow/go/v10/arrow/memory"
+	"github.com/stretchr/testify/require"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+func TestTransformStreamedTransactions(t *testing.T) {
+	block := &chainstorageapi.BitcoinBlock{
+		Header: &chainstorageapi.BitcoinHeader{
+			Hash:   "0xabc",
+			Height: 100,
+			Time:   1700000000,
+		},
+		Transactions: []*chainstorageapi.BitcoinTransaction{
+			{TransactionId: "tx0", IsCoinbase: true},
+			{TransactionId: "tx1", Index: 1},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		eventType chainstorageapi.BlockchainEvent_Type
+	}{
+		{name: "added", eventType: chainstorageapi.BlockchainEvent_BLOCK_ADDED},
+		{name: "removed", eventType: chainstorageapi.BlockchainEvent_BLOCK_REMOVED},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			event := &chainstorageapi.BlockchainEvent{
+				SequenceNum: 42,
+				Type:        test.eventType,
+			}
+			schema := newStreamedTransactionSchema()
+			recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), schema, nil)
+			defer recordBuilder.Release()
+
+			require.NoError(nativeStreamedTransactionsTable{}.transformStreamedTransactions(recordBuilder, block, event, partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+			rec := recordBuilder.NewRecord()
+			defer rec.Release()
+			require.Equal(int64(2), rec.NumRows())
+			for i, hash := range []string{"tx0", "tx1"} {
+				require.Equal(int64(42), rec.Column(schema.FieldIndices("_sequence_number")[0]).(*array.Int64).Value(i))
+				require.Equal(test.eventType.String(), rec.Column(schema.FieldIndices("_event_type")[0]).(*array.String).Value(i))
+				require.Equal(hash, rec.Column(schema.FieldIndices("hash")[0]).(*array.String).Value(i))
+				require.Equal(uint64(42), rec.Column(schema.FieldIndices("_repartition_by_range")[0]).(*array.Uint64).Value(i))
+			}
+		})
+	}
+}
+
+func TestTransformStreamedBlocks(t *testing.T) {
+	require := require.New(t)
+
+	block := &chainstorageapi.BitcoinBlock{
+		Header: &chainstorageapi.BitcoinHeader{
+			Hash:   "0xabc",
+			Height: 100,
+			Time:   1700000000,
+		},
+		Transactions: []*chainstorageapi.BitcoinTransaction{
+			{TransactionId: "tx0", IsCoinbase: true},
+		},
+	}
+	event := &chainstorageapi.BlockchainEvent{
+		SequenceNum: 43,
+		Type:        chainstorageapi.BlockchainEvent_BLOCK_REMOVED,
+	}
+
+	schema := newStreamedBlockSchema()
+	recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), schema, nil)
+	defer recordBuilder.Release()
+
+	require.NoError(nativeStreamedBlocksTable{}.transformStreamedBlocks(recordBuilder, block, event, partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+	rec := recordBuilder.NewRecord()
+	defer rec.Release()
+	require.Equal(int64(1), rec.NumRows())
+	require.Equal("BLOCK_REMOVED", rec.Column(schema.FieldIndices("_event_type")[0]).(*array.String).Value(0))
+	require.Equal("0xabc", rec.Column(schema.FieldIndices("hash")[0]).(*array.String).Value(0))
+	require.Equal(uint64(100), rec.Column(schema.FieldIndices("number")[0]).(*array.Uint64).Value(0))
+}
@@ -1,7 +1,9 @@
 package tables
 
 import (
-	"github.com/apache/arrow/go/v10/arrow/array"
+	"time"
+
+	"github.com/golang/protobuf/proto"
 	"golang.org/x/xerrors"
 
 	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
@@ -10,7 +12,7 @@ import (
 	"github.com/coinbase/chainsformer/internal/utils/xarrow"
 )
 
-func (t transactionsTable) transformTransactions(recordBuilder *array.RecordBuilder, block *chainstorageapi.BitcoinBlock, partitionBySize uint64) error {
+func (t transactionsTable) transformTransactions(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.BitcoinBlock, partitioner *partition.Partitioner) error {
 	header := block.GetHeader()
 	if header == nil {
 		return xerrors.New("header is required")
@@ -21,31 +23,11 @@ func (t transactionsTable) transformTransactions(recordBuilder *array.RecordBuil
 		return nil
 	}
 
+	partitionBy := partitioner.GetPartitionBy(header.Height, 0, time.Unix(int64(header.Time), 0))
 	for _, transaction := range transactions {
-		xarrow.NewRecordAppender(recordBuilder).
-			AppendString(transaction.TransactionId). // DO NOT USE transaction.Hash.
-			AppendUint64(transaction.Size).
-			AppendUint64(transaction.VirtualSize).
-			AppendUint64(transaction.Weight).
-			AppendUint64(transaction.Version).
-			AppendUint64(transaction.LockTime).
-			AppendBool(transaction.IsCoinbase).
-			AppendUint64(transaction.Index).
-			AppendStruct(func(sa *xarrow.StructAppender) {
-				transformBlock(sa, header)
-			}).
-			AppendList(func(la *xarrow.ListAppender) {
-				transformInputs(la, transaction.Inputs)
-			}).
-			AppendList(func(la *xarrow.ListAppender) {
-				transformOutputs(la, transaction.Outputs)
-			}).
-			AppendUint64(transaction.InputCount).
-			AppendUint64(transaction.OutputCount).
-			AppendUint64(transaction.InputValue).
-			AppendUint64(transaction.OutputValue).
-			AppendUint64(transaction.Fee).
-			AppendUint64(partition.GetPartitionByNumber(header.Height, partitionBySize)).
+		recordAppender := xarrow.NewRecordAppender(recordBuilder)
+		appendTransaction(recordAppender, header, transaction)
+		recordAppender.AppendUint64(partitionBy).
 			AppendUint64(header.Height).
 			Build()
 	}
@@ -53,14 +35,89 @@ func (t transactionsTable) transformTransactions(recordBuilder *array.RecordBuil
 	return nil
 }
 
-func (t blocksTable) transformBlocks(recordBuilder *array.RecordBuilder, block *chainstorageapi.BitcoinBlock, partitionBySize uint64) error {
+func (t nativeStreamedTransactionsTable) transformStreamedTransactions(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.BitcoinBlock, event *chainstorageapi.BlockchainEvent, partitioner *partition.Partitioner) error {
 	header := block.GetHeader()
 	if header == nil {
 		return xerrors.New("header is required")
 	}
 
-	xarrow.NewRecordAppender(recordBuilder).
-		AppendString(header.Hash).
+	// The transactions of a removed block are emitted again with the BLOCK_REMOVED event type,
+	// so that the consumers can retract the rows previously emitted by its BLOCK_ADDED event.
+	partitionBy := partitioner.GetPartitionBy(header.Height, event.GetSequenceNum(), time.Unix(int64(header.Time), 0))
+	for _, transaction := range block.GetTransactions() {
+		recordAppender := xarrow.NewRecordAppender(recordBuilder).
+			AppendInt64(event.GetSequenceNum()).
+			AppendString(event.GetType().String())
+		appendTransaction(recordAppender, header, transaction)
+		recordAppender.AppendUint64(partitionBy).
+			AppendUint64(uint64(event.GetSequenceNum())).
+			Build()
+	}
+
+	return nil
+}
+
+func appendTransaction(ra *xarrow.RecordAppender, header *chainstorageapi.BitcoinHeader, transaction *chainstorageapi.BitcoinTransaction) {
+	// DO NOT USE transaction.Hash.
+	ra.AppendString(transaction.TransactionId).
+		AppendUint64(transaction.Size).
+		AppendUint64(transaction.VirtualSize).
+		AppendUint64(transaction.Weight).
+		AppendUint64(transaction.Version).
+		AppendUint64(transaction.LockTime).
+		AppendBool(transaction.IsCoinbase).
+		AppendUint64(transaction.Index).
+		AppendStruct(func(sa *xarrow.StructAppender) {
+			transformBlock(sa, header)
+		}).
+		AppendList(func(la *xarrow.ListAppender) {
+			transformInputs(la, transaction.Inputs)
+		}).
+		AppendList(func(la *xarrow.ListAppender) {
+			transformOutputs(la, transaction.Outputs)
+		}).
+		AppendUint64(transaction.InputCount).
+		AppendUint64(transaction.OutputCount).
+		AppendUint64(transaction.InputValue).
+		AppendUint64(transaction.OutputValue).
+		AppendUint64(transaction.Fee)
+}
+
+func (t blocksTable) transformBlocks(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.BitcoinBlock, partitioner *partition.Partitioner) error {
+	header := block.GetHeader()
+	if header == nil {
+		return xerrors.New("header is required")
+	}
+
+	recordAppender := xarrow.NewRecordAppender(recordBuilder)
+	appendBlock(recordAppender, block)
+	recordAppender.AppendUint64(partitioner.GetPartitionBy(header.Height, 0, time.Unix(int64(header.Time), 0))).
+		AppendUint64(header.Height).
+		Build()
+
+	return nil
+}
+
+func (t nativeStreamedBlocksTable) transformStreamedBlocks(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.BitcoinBlock, event *chainstorageapi.BlockchainEvent, partitioner *partition.Partitioner) error {
+	header := block.GetHeader()
+	if header == nil {
+		return xerrors.New("header is required")
+	}
+
+	recordAppender := xarrow.NewRecordAppender(recordBuilder).
+		AppendInt64(event.GetSequenceNum()).
+		AppendString(event.GetType().String())
+	appendBlock(recordAppender, block)
+	recordAppender.AppendUint64(partitioner.GetPartitionBy(header.Height, event.GetSequenceNum(), time.Unix(int64(header.Time), 0))).
+		AppendUint64(uint64(event.GetSequenceNum())).
+		Build()
+
+	return nil
+}
+
+func appendBlock(ra *xarrow.RecordAppender, block *chainstorageapi.BitcoinBlock) {
+	header := block.GetHeader()
+	ra.AppendString(header.Hash).
 		AppendUint64(header.Size).
 		AppendUint64(header.StrippedSize).
 		AppendUint64(header.Weight).
@@ -79,10 +136,185 @@ func (t blocksTable) transformBlocks(recordBuilder *array.RecordBuilder, block *
 			for _, transaction := range block.Transactions {
 				la.AppendString(transaction.TransactionId)
 			}
-		}).
-		AppendUint64(partition.GetPartitionByNumber(header.Height, partitionBySize)).
+		})
+}
+
+func (t rawNativeTransactionsTable) transformRawTransactions(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.BitcoinBlock, partitioner *partition.Partitioner) error {
+	header := block.GetHeader()
+	if header == nil {
+		return xerrors.New("header is required")
+	}
+
+	partitionBy := partitioner.GetPartitionBy(header.Height, 0, time.Unix(int64(header.Time), 0))
+	for _, transaction := range block.GetTransactions() {
+		recordAppender := xarrow.NewRecordAppender(recordBuilder)
+		if err := appendRawTransaction(recordAppender, header, transaction); err != nil {
+			return err
+		}
+
+		recordAppender.AppendUint64(partitionBy).
+			AppendUint64(header.Height).
+			Build()
+	}
+
+	return nil
+}
+
+func (t rawNativeStreamedTransactionsTable) transformRawStreamedTransactions(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.BitcoinBlock, event *chainstorageapi.BlockchainEvent, partitioner *partition.Partitioner) error {
+	header := block.GetHeader()
+	if header == nil {
+		return xerrors.New("header is required")
+	}
+
+	partitionBy := partitioner.GetPartitionBy(header.Height, event.GetSequenceNum(), time.Unix(int64(header.Time), 0))
+	for _, transaction := range block.GetTransactions() {
+		recordAppender := xarrow.NewRecordAppender(recordBuilder).
+			AppendInt64(event.GetSequenceNum()).
+			AppendString(event.GetType().String())
+		if err := appendRawTransaction(recordAppender, header, transaction); err != nil {
+			return err
+		}
+
+		recordAppender.AppendUint64(partitionBy).
+			AppendUint64(uint64(event.GetSequenceNum())).
+			Build()
+	}
+
+	return nil
+}
+
+func appendRawTransaction(ra *xarrow.RecordAppender, header *chainstorageapi.BitcoinHeader, transaction *chainstorageapi.BitcoinTransaction) error {
+	data, err := proto.Marshal(transaction)
+	if err != nil {
+		return xerrors.Errorf("failed to marshal transaction into protobuf (hash=%v): %w", transaction.TransactionId, err)
+	}
+
+	// DO NOT USE transaction.Hash.
+	ra.AppendString(transaction.TransactionId).
+		AppendUint64(transaction.Index).
+		AppendString(header.Hash).
 		AppendUint64(header.Height).
+		AppendUint64(header.Time).
+		AppendBinary(data)
+	return nil
+}
+
+func (t rawNativeBlocksTable) transformRawBlocks(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.BitcoinBlock, partitioner *partition.Partitioner) error {
+	header := block.GetHeader()
+	if header == nil {
+		return xerrors.New("header is required")
+	}
+
+	recordAppender := xarrow.NewRecordAppender(recordBuilder)
+	if err := appendRawBlock(recordAppender, block); err != nil {
+		return err
+	}
+
+	recordAppender.AppendUint64(partitioner.GetPartitionBy(header.Height, 0, time.Unix(int64(header.Time), 0))).
+		AppendUint64(header.Height).
+		Build()
+	return nil
+}
+
+func (t rawNativeStreamedBlocksTable) transformRawStreamedBlocks(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.BitcoinBlock, event *chainstorageapi.BlockchainEvent, partitioner *partition.Partitioner) error {
+	header := block.GetHeader()
+	if header == nil {
+		return xerrors.New("header is required")
+	}
+
+	recordAppender := xarrow.NewRecordAppender(recordBuilder).
+		AppendInt64(event.GetSequenceNum()).
+		AppendString(event.GetType().String())
+	if err := appendRawBlock(recordAppender, block); err != nil {
+		return err
+	}
+
+	recordAppender.AppendUint64(partitioner.GetPartitionBy(header.Height, event.GetSequenceNum(), time.Unix(int64(header.Time), 0))).
+		AppendUint64(uint64(event.GetSequenceNum())).
 		Build()
+	return nil
+}
+
+func appendRawBlock(ra *xarrow.RecordAppender, block *chainstorageapi.BitcoinBlock) error {
+	header := block.GetHeader()
+	data, err := proto.Marshal(block)
+	if err != nil {
+		return xerrors.Errorf("failed to marshal block into protobuf (hash=%v): %w", header.Hash, err)
+	}
+
+	ra.AppendString(header.Hash).
+		AppendUint64(header.Height).
+		AppendUint64(header.Time).
+		AppendString(header.PreviousBlockHash).
+		AppendBinary(data)
+	return nil
+}
+
+func (t inputsTable) transformInputs(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.BitcoinBlock, partitioner *partition.Partitioner) error {
+	header := block.GetHeader()
+	if header == nil {
+		return xerrors.New("header is required")
+	}
+
+	partitionBy := partitioner.GetPartitionBy(header.Height, 0, time.Unix(int64(header.Time), 0))
+	for _, transaction := range block.GetTransactions() {
+		for i, input := range transaction.Inputs {
+			xarrow.NewRecordAppender(recordBuilder).
+				AppendUint64(uint64(i)).
+				AppendString(input.Coinbase).
+				AppendString(input.TransactionId).
+				AppendUint64(input.FromOutputIndex).
+				AppendString(input.GetScriptSignature().GetAssembly()).
+				AppendString(input.GetScriptSignature().GetHex()).
+				AppendUint64(input.Sequence).
+				AppendList(func(la *xarrow.ListAppender) {
+					for _, transactionInputWitness := range input.TransactionInputWitnesses {
+						la.AppendString(transactionInputWitness)
+					}
+				}).
+				AppendString(input.GetFromOutput().GetScriptPublicKey().GetType()).
+				AppendString(input.GetFromOutput().GetScriptPublicKey().GetAddress()).
+				AppendUint64(input.GetFromOutput().GetValue()).
+				AppendString(transaction.TransactionId). // DO NOT USE transaction.Hash.
+				AppendUint64(transaction.Index).
+				AppendString(header.Hash).
+				AppendUint64(header.Height).
+				AppendUint64(header.Time).
+				AppendUint64(partitionBy).
+				AppendUint64(header.Height).
+				Build()
+		}
+	}
+
+	return nil
+}
+
+func (t outputsTable) transformOutputs(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.BitcoinBlock, partitioner *partition.Partitioner) error {
+	header := block.GetHeader()
+	if header == nil {
+		return xerrors.New("header is required")
+	}
+
+	partitionBy := partitioner.GetPartitionBy(header.Height, 0, time.Unix(int64(header.Time), 0))
+	for _, transaction := range block.GetTransactions() {
+		for _, output := range transaction.Outputs {
+			xarrow.NewRecordAppender(recordBuilder).
+				AppendUint64(output.Index).
+				AppendString(output.GetScriptPublicKey().GetAssembly()).
+				AppendString(output.GetScriptPublicKey().GetHex()).
+				AppendString(output.GetScriptPublicKey().GetType()).
+				AppendString(output.GetScriptPublicKey().GetAddress()).
+				AppendUint64(output.Value).
+				AppendString(transaction.TransactionId). // DO NOT USE transaction.Hash.
+				AppendUint64(transaction.Index).
+				AppendString(header.Hash).
+				AppendUint64(header.Height).
+				AppendUint64(header.Time).
+				AppendUint64(partitionBy).
+				AppendUint64(header.Height).
+				Build()
+		}
+	}
 
 	return nil
 }
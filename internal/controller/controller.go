@@ -19,6 +19,8 @@ type (
 		fxparams.Params
 		Ethereum Controller `name:"ethereum"`
 		Bitcoin  Controller `name:"bitcoin"`
+		Solana   Controller `name:"solana"`
+		Aptos    Controller `name:"aptos"`
 		Rosetta  Controller `name:"rosetta"`
 	}
 )
@@ -26,6 +28,8 @@ type (
 // NewController
 // The Ethereum controller defines schemas for most evm chains.
 // The Bitcoin controller defines schemas for the Bitcoin network.
+// The Solana controller defines schemas for the Solana network.
+// The Aptos controller defines schemas for the Aptos network.
 // The Rosetta controller defines rosetta schemas for networks that support rosetta parsing.
 func NewController(params ControllerParams) (Controller, error) {
 	switch blockchain := params.Config.Blockchain(); blockchain {
@@ -37,6 +41,10 @@ func NewController(params ControllerParams) (Controller, error) {
 		return params.Ethereum, nil
 	case common.Blockchain_BLOCKCHAIN_BITCOIN:
 		return params.Bitcoin, nil
+	case common.Blockchain_BLOCKCHAIN_SOLANA:
+		return params.Solana, nil
+	case common.Blockchain_BLOCKCHAIN_APTOS:
+		return params.Aptos, nil
 	default:
 		return nil, xerrors.Errorf("controller is not implemented: %v", blockchain)
 	}
@@ -0,0 +1,9 @@
+package abi
+
+import (
+	"go.uber.org/fx"
+)
+
+var Module = fx.Options(
+	fx.Provide(NewRegistry),
+)
@@ -0,0 +1,371 @@
+package abi
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	ethabi "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+
+	"github.com/coinbase/chainsformer/internal/utils/fxparams"
+	"github.com/coinbase/chainsformer/internal/utils/log"
+)
+
+type (
+	// Registry decodes the logs and calls of the events and functions defined by a directory of JSON ABI files.
+	// The events and functions are looked up by their selectors, regardless of the contract address.
+	Registry struct {
+		events  map[common.Hash][]ethabi.Event
+		methods map[[4]byte]ethabi.Method
+	}
+
+	// Decoded is a decoded log or call.
+	Decoded struct {
+		// Name is the name of the event or function.
+		Name string
+		// Signature is the canonical signature of the event or function, e.g. Transfer(address,address,uint256).
+		Signature string
+		// Args is the JSON object of the arguments by name.
+		// Integers are encoded as decimal strings, and addresses and bytes as hex strings.
+		Args string
+	}
+
+	Params struct {
+		fx.In
+		fxparams.Params
+	}
+
+	// artifact is the format of the compiled contracts, e.g. by truffle and hardhat, which embeds the ABI.
+	artifact struct {
+		ABI json.RawMessage `json:"abi"`
+	}
+)
+
+const (
+	SelectorLength = 4
+)
+
+func NewRegistry(params Params) (*Registry, error) {
+	dir := params.Config.ABIRegistry.Dir
+	registry, err := newRegistry(dir)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create abi registry: %w", err)
+	}
+
+	if registry.isEmpty() {
+		log.WithPackage(params.Logger).Warn(
+			"abi registry has no event or function, the decoded tables will not decode any log or call",
+			zap.String("dir", dir),
+		)
+	}
+
+	return registry, nil
+}
+
+// newRegistry loads the *.json files of the directory, in the order of their names.
+// An empty dir results in an empty registry.
+func newRegistry(dir string) (*Registry, error) {
+	registry := &Registry{
+		events:  make(map[common.Hash][]ethabi.Event),
+		methods: make(map[[4]byte]ethabi.Method),
+	}
+
+	if dir == "" {
+		return registry, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, xerrors.Errorf("failed to list abi files in %v: %w", dir, err)
+	}
+
+	sort.Strings(files)
+	for _, file := range files {
+		contractABI, err := readABI(file)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to read abi file %v: %w", file, err)
+		}
+
+		registry.add(contractABI)
+	}
+
+	return registry, nil
+}
+
+func readABI(file string) (*ethabi.ABI, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read file: %w", err)
+	}
+
+	// Accept both the plain ABI array and the compiled artifacts embedding it.
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		var a artifact
+		if err := json.Unmarshal(data, &a); err != nil {
+			return nil, xerrors.Errorf("failed to parse artifact: %w", err)
+		}
+		data = a.ABI
+	}
+
+	contractABI, err := ethabi.JSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, xerrors.Errorf("failed to parse abi: %w", err)
+	}
+
+	return &contractABI, nil
+}
+
+// isEmpty returns true if the registry has no event or function to decode.
+func (r *Registry) isEmpty() bool {
+	return len(r.events) == 0 && len(r.methods) == 0
+}
+
+// add adds the events and functions of the ABI.
+// The first function of a selector wins, while all the events of a selector are kept,
+// since events sharing a signature may differ by their indexed arguments, e.g. Transfer of ERC20 and ERC721.
+func (r *Registry) add(contractABI *ethabi.ABI) {
+	for _, event := range sortedEvents(contractABI) {
+		if event.Anonymous {
+			continue
+		}
+
+		duplicated := false
+		for _, other := range r.events[event.ID] {
+			duplicated = duplicated || indexedPattern(other) == indexedPattern(event)
+		}
+		if !duplicated {
+			r.events[event.ID] = append(r.events[event.ID], event)
+		}
+	}
+
+	for _, method := range sortedMethods(contractABI) {
+		var selector [4]byte
+		copy(selector[:], method.ID)
+		if _, ok := r.methods[selector]; !ok {
+			r.methods[selector] = method
+		}
+	}
+}
+
+// DecodeLog decodes the log with the given hex encoded topics and data.
+// It returns nil if the event is unknown or the log does not match its definition.
+func (r *Registry) DecodeLog(topics []string, data string) *Decoded {
+	if len(topics) == 0 {
+		return nil
+	}
+
+	hashes := make([]common.Hash, len(topics))
+	for i, topic := range topics {
+		b, err := decodeHex(topic)
+		if err != nil || len(b) != common.HashLength {
+			return nil
+		}
+		hashes[i] = common.BytesToHash(b)
+	}
+
+	dataBytes, err := decodeHex(data)
+	if err != nil {
+		return nil
+	}
+
+	for _, event := range r.events[hashes[0]] {
+		args, err := decodeEventArgs(event, hashes[1:], dataBytes)
+		if err != nil {
+			continue
+		}
+
+		return &Decoded{
+			Name:      event.RawName,
+			Signature: event.Sig,
+			Args:      args,
+		}
+	}
+
+	return nil
+}
+
+// DecodeCall decodes the hex encoded input of a transaction or trace.
+// It returns nil if the function is unknown or the input does not match its definition.
+func (r *Registry) DecodeCall(input string) *Decoded {
+	inputBytes, err := decodeHex(input)
+	if err != nil || len(inputBytes) < SelectorLength {
+		return nil
+	}
+
+	var selector [4]byte
+	copy(selector[:], inputBytes)
+	method, ok := r.methods[selector]
+	if !ok {
+		return nil
+	}
+
+	values, err := method.Inputs.Unpack(inputBytes[SelectorLength:])
+	if err != nil {
+		return nil
+	}
+
+	res := make(map[string]interface{}, len(values))
+	for i, value := range values {
+		res[argName(method.Inputs[i], i)] = formatValue(reflect.ValueOf(value))
+	}
+
+	args, err := json.Marshal(res)
+	if err != nil {
+		return nil
+	}
+
+	return &Decoded{
+		Name:      method.RawName,
+		Signature: method.Sig,
+		Args:      string(args),
+	}
+}
+
+func decodeEventArgs(event ethabi.Event, topics []common.Hash, data []byte) (string, error) {
+	var indexed, nonIndexed ethabi.Arguments
+	for i, arg := range event.Inputs {
+		arg.Name = argName(arg, i)
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		} else {
+			nonIndexed = append(nonIndexed, arg)
+		}
+	}
+
+	if len(indexed) != len(topics) {
+		return "", xerrors.Errorf("expected %d indexed arguments, got %d topics", len(indexed), len(topics))
+	}
+
+	values := make(map[string]interface{}, len(event.Inputs))
+	if err := ethabi.ParseTopicsIntoMap(values, indexed, topics); err != nil {
+		return "", xerrors.Errorf("failed to parse topics: %w", err)
+	}
+
+	unpacked, err := nonIndexed.Unpack(data)
+	if err != nil {
+		return "", xerrors.Errorf("failed to unpack data: %w", err)
+	}
+	for i, value := range unpacked {
+		values[nonIndexed[i].Name] = value
+	}
+
+	res := make(map[string]interface{}, len(values))
+	for name, value := range values {
+		res[name] = formatValue(reflect.ValueOf(value))
+	}
+
+	args, err := json.Marshal(res)
+	if err != nil {
+		return "", xerrors.Errorf("failed to marshal args: %w", err)
+	}
+
+	return string(args), nil
+}
+
+// formatValue converts the value unpacked by go-ethereum into a JSON friendly value.
+func formatValue(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+
+	switch value := v.Interface().(type) {
+	case common.Address:
+		return strings.ToLower(value.Hex())
+	case common.Hash:
+		return value.Hex()
+	case *big.Int:
+		return value.String()
+	case []byte:
+		return "0x" + hex.EncodeToString(value)
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fmt.Sprint(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fmt.Sprint(v.Uint())
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return "0x" + hex.EncodeToString(b)
+		}
+		fallthrough
+	case reflect.Slice:
+		res := make([]interface{}, v.Len())
+		for i := range res {
+			res[i] = formatValue(v.Index(i))
+		}
+		return res
+	case reflect.Struct:
+		// Tuples are unpacked into structs whose json tags hold the names of the components.
+		res := make(map[string]interface{}, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			name := v.Type().Field(i).Tag.Get("json")
+			if name == "" {
+				name = v.Type().Field(i).Name
+			}
+			res[name] = formatValue(v.Field(i))
+		}
+		return res
+	case reflect.Ptr:
+		return formatValue(v.Elem())
+	default:
+		return v.Interface()
+	}
+}
+
+func argName(arg ethabi.Argument, i int) string {
+	if arg.Name == "" {
+		return fmt.Sprintf("arg%d", i)
+	}
+
+	return arg.Name
+}
+
+func indexedPattern(event ethabi.Event) string {
+	var sb strings.Builder
+	for _, arg := range event.Inputs {
+		if arg.Indexed {
+			sb.WriteString("1")
+		} else {
+			sb.WriteString("0")
+		}
+	}
+
+	return sb.String()
+}
+
+func decodeHex(s string) ([]byte, error) {
+	return hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X"))
+}
+
+func sortedEvents(contractABI *ethabi.ABI) []ethabi.Event {
+	events := make([]ethabi.Event, 0, len(contractABI.Events))
+	for _, event := range contractABI.Events {
+		events = append(events, event)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Name < events[j].Name })
+	return events
+}
+
+func sortedMethods(contractABI *ethabi.ABI) []ethabi.Method {
+	methods := make([]ethabi.Method, 0, len(contractABI.Methods))
+	for _, method := range contractABI.Methods {
+		methods = append(methods, method)
+	}
+	sort.Slice(methods, func(i, j int) bool { return methods[i].Name < methods[j].Name })
+	return methods
+}
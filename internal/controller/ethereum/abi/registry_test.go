@@ -0,0 +1,96 @@
+package abi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTransfer  = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	testApproval  = "0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925"
+	testFromTopic = "0x00000000000000000000000095222290dd7278aa3ddd389cc1e1d165cc4bafe5"
+	testToTopic   = "0x000000000000000000000000a0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+	testValue     = "0x00000000000000000000000000000000000000000000000000000000000f4240"
+	testTokenId   = "0x000000000000000000000000000000000000000000000000000000000000002a"
+)
+
+func TestDecodeLog(t *testing.T) {
+	registry, err := newRegistry("testdata")
+	require.NoError(t, err)
+	require.False(t, registry.isEmpty())
+
+	tests := []struct {
+		name     string
+		topics   []string
+		data     string
+		expected *Decoded
+	}{
+		{
+			name:   "erc20",
+			topics: []string{testTransfer, testFromTopic, testToTopic},
+			data:   testValue,
+			expected: &Decoded{
+				Name:      "Transfer",
+				Signature: "Transfer(address,address,uint256)",
+				Args:      `{"from":"0x95222290dd7278aa3ddd389cc1e1d165cc4bafe5","to":"0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48","value":"1000000"}`,
+			},
+		},
+		{
+			name:   "erc721",
+			topics: []string{testTransfer, testFromTopic, testToTopic, testTokenId},
+			data:   "0x",
+			expected: &Decoded{
+				Name:      "Transfer",
+				Signature: "Transfer(address,address,uint256)",
+				Args:      `{"from":"0x95222290dd7278aa3ddd389cc1e1d165cc4bafe5","to":"0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48","tokenId":"42"}`,
+			},
+		},
+		{
+			name:   "unknown_event",
+			topics: []string{testApproval, testFromTopic, testToTopic},
+			data:   testValue,
+		},
+		{
+			name:   "malformed_data",
+			topics: []string{testTransfer, testFromTopic, testToTopic},
+			data:   "0x01",
+		},
+		{
+			name: "no_topics",
+			data: testValue,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, registry.DecodeLog(test.topics, test.data))
+		})
+	}
+}
+
+func TestDecodeCall(t *testing.T) {
+	require := require.New(t)
+
+	registry, err := newRegistry("testdata")
+	require.NoError(err)
+
+	input := "0xa9059cbb" + testToTopic[2:] + testValue[2:]
+	require.Equal(&Decoded{
+		Name:      "transfer",
+		Signature: "transfer(address,uint256)",
+		Args:      `{"amount":"1000000","to":"0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"}`,
+	}, registry.DecodeCall(input))
+
+	require.Nil(registry.DecodeCall("0x095ea7b3" + testToTopic[2:] + testValue[2:]))
+	require.Nil(registry.DecodeCall("0xa9059cbb"))
+	require.Nil(registry.DecodeCall("0x"))
+}
+
+func TestNewRegistry_EmptyDir(t *testing.T) {
+	require := require.New(t)
+
+	registry, err := newRegistry("")
+	require.NoError(err)
+	require.True(registry.isEmpty())
+	require.Nil(registry.DecodeCall("0xa9059cbb" + testToTopic[2:] + testValue[2:]))
+}
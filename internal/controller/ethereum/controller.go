@@ -12,6 +12,8 @@ type (
 		fx.In
 		fxparams.Params
 		Tables []internal.Table `group:"ethereum"`
+		// RosettaTables are the rosetta tables shared by the controllers of the blockchains supporting rosetta parsing.
+		RosettaTables []internal.Table `group:"rosetta"`
 	}
 
 	controller struct {
@@ -22,9 +24,11 @@ type (
 func NewController(params ControllerParams) internal.Controller {
 	var tables []internal.Table
 	supportedFormats := params.Config.Table.GetSupportedFormats()
-	for _, table := range params.Tables {
-		if supportedFormats[table.GetFormat().String()] {
-			tables = append(tables, table)
+	for _, group := range [][]internal.Table{params.Tables, params.RosettaTables} {
+		for _, table := range group {
+			if supportedFormats[table.GetFormat().String()] {
+				tables = append(tables, table)
+			}
 		}
 	}
 
@@ -3,6 +3,7 @@ package ethereum
 import (
 	"go.uber.org/fx"
 
+	"github.com/coinbase/chainsformer/internal/controller/ethereum/abi"
 	"github.com/coinbase/chainsformer/internal/controller/ethereum/tables"
 )
 
@@ -11,5 +12,6 @@ var Module = fx.Options(
 		Name:   "ethereum",
 		Target: NewController,
 	}),
+	abi.Module,
 	tables.Module,
 )
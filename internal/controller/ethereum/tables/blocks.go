@@ -3,7 +3,6 @@ package tables
 import (
 	"context"
 
-	"github.com/apache/arrow/go/v10/arrow/array"
 	"golang.org/x/xerrors"
 
 	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
@@ -11,6 +10,9 @@ import (
 
 	"github.com/coinbase/chainsformer/internal/config"
 	"github.com/coinbase/chainsformer/internal/controller/internal"
+	"github.com/coinbase/chainsformer/internal/controller/internal/constant"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
 )
 
 type (
@@ -25,7 +27,7 @@ type (
 func NewBlocksTable(params internal.CommonTableParams) internal.Table {
 	return internal.NewBatchTable(
 		&params,
-		internal.NewTableAttributes(internal.TableNameBlocks),
+		internal.NewTableAttributes(internal.TableNameBlocks, internal.WithEstimateMode(constant.EstimateModeBlocks)),
 		newBlockSchema(params.Config),
 		blocksTable{
 			params.Config,
@@ -33,7 +35,7 @@ func NewBlocksTable(params internal.CommonTableParams) internal.Table {
 	)
 }
 
-func (t blocksTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *array.RecordBuilder, partitionBySize uint64) error {
+func (t blocksTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
 	nativeBlock, err := parser.ParseNativeBlock(ctx, block)
 	if err != nil {
 		return xerrors.Errorf("failed to parse raw block to native block: %w", err)
@@ -44,7 +46,7 @@ func (t blocksTable) TransformBlock(ctx context.Context, block *chainstorageapi.
 		return xerrors.New("failed to extract ethereum block from native block")
 	}
 
-	if err := t.transformBlocks(recordBuilder, ethereumBlock, partitionBySize); err != nil {
+	if err := t.transformBlocks(recordBuilder, ethereumBlock, partitioner); err != nil {
 		return xerrors.Errorf("failed to transform blocks: %w", err)
 	}
 
@@ -63,7 +65,7 @@ func NewNativeStreamedBlocksTable(params internal.CommonTableParams) internal.Ta
 	)
 }
 
-func (t nativeStreamedBlocksTable) TransformBlock(ctx context.Context, blockAndEvent *internal.BlockAndEvent, parser sdk.Parser, recordBuilder *array.RecordBuilder, partitionBySize uint64) error {
+func (t nativeStreamedBlocksTable) TransformBlock(ctx context.Context, blockAndEvent *internal.BlockAndEvent, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
 	nativeBlock, err := parser.ParseNativeBlock(ctx, blockAndEvent.Block)
 	if err != nil {
 		return xerrors.Errorf("failed to parse raw block to native block: %w", err)
@@ -74,7 +76,7 @@ func (t nativeStreamedBlocksTable) TransformBlock(ctx context.Context, blockAndE
 		return xerrors.New("failed to extract ethereum block from native block")
 	}
 
-	if err := t.transformStreamedBlocks(recordBuilder, ethereumBlock, blockAndEvent.BlockChainEvent, partitionBySize); err != nil {
+	if err := t.transformStreamedBlocks(recordBuilder, ethereumBlock, blockAndEvent.BlockChainEvent, partitioner); err != nil {
 		return xerrors.Errorf("failed to transform blocks: %w", err)
 	}
 
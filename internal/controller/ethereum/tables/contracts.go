@@ -0,0 +1,50 @@
+package tables
+
+import (
+	"context"
+
+	"golang.org/x/xerrors"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+	"github.com/coinbase/chainstorage/sdk"
+
+	"github.com/coinbase/chainsformer/internal/controller/internal"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+type (
+	contractsTable struct{}
+)
+
+const (
+	traceTypeCreate  = "create"
+	traceTypeCreate2 = "create2"
+)
+
+func NewContractsTable(params internal.CommonTableParams) internal.Table {
+	return internal.NewBatchTable(
+		&params,
+		internal.NewTableAttributes(internal.TableNameContracts),
+		newContractSchema(),
+		contractsTable{},
+	)
+}
+
+func (t contractsTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
+	nativeBlock, err := parser.ParseNativeBlock(ctx, block)
+	if err != nil {
+		return xerrors.Errorf("failed to parse raw block to native block: %w", err)
+	}
+
+	ethereumBlock := nativeBlock.GetEthereum()
+	if ethereumBlock == nil {
+		return xerrors.New("failed to extract ethereum block from native block")
+	}
+
+	if err := t.transformContracts(recordBuilder, ethereumBlock, partitioner); err != nil {
+		return xerrors.Errorf("failed to transform contracts: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v10/arrow/array"
+	"github.com/apache/arrow/go/v10/arrow/memory"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+func TestTransformContracts(t *testing.T) {
+	require := require.New(t)
+
+	block := &chainstorageapi.EthereumBlock{
+		Header: &chainstorageapi.EthereumHeader{
+			Number:    100,
+			Hash:      "0xabc",
+			Timestamp: &timestamppb.Timestamp{Seconds: 1700000000},
+		},
+		Transactions: []*chainstorageapi.EthereumTransaction{
+			{
+				// Created by the transaction without traces.
+				Hash: "0x1",
+				From: testEOA,
+				Receipt: &chainstorageapi.EthereumTransactionReceipt{
+					ContractAddress: testUSDT,
+					OptionalStatus:  &chainstorageapi.EthereumTransactionReceipt_Status{Status: 1},
+				},
+			},
+			{
+				// Failed creation.
+				Hash:  "0x2",
+				Index: 1,
+				From:  testEOA,
+				Receipt: &chainstorageapi.EthereumTransactionReceipt{
+					ContractAddress: testUSDC,
+				},
+			},
+			{
+				Hash:  "0x3",
+				Index: 2,
+				From:  testEOA,
+				FlattenedTraces: []*chainstorageapi.EthereumTransactionFlattenedTrace{
+					{TransactionHash: "0x3", TraceType: "create", From: testEOA, To: "0xf1", Output: "0x60"},
+					{TransactionHash: "0x3", TraceType: "create", From: "0xf1", To: "0xc1", Output: "0x61", TraceAddress: []uint64{0}},
+					{TransactionHash: "0x3", TraceType: "call", From: "0xf1", To: "0xc2", Error: "Reverted", TraceAddress: []uint64{1}},
+					{TransactionHash: "0x3", TraceType: "create", From: "0xc2", To: "0xc3", Output: "0x62", TraceAddress: []uint64{1, 0}},
+				},
+			},
+		},
+	}
+
+	schema := newContractSchema()
+	recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), schema, nil)
+	defer recordBuilder.Release()
+
+	require.NoError(contractsTable{}.transformContracts(recordBuilder, block, partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+	rec := recordBuilder.NewRecord()
+	defer rec.Release()
+
+	expectedAddress := []string{testUSDT, "0xf1", "0xc1"}
+	expectedDeployer := []string{testEOA, testEOA, "0xf1"}
+	expectedIsFactoryCreated := []bool{false, false, true}
+	require.Equal(int64(len(expectedAddress)), rec.NumRows())
+	bytecode := rec.Column(schema.FieldIndices("bytecode")[0]).(*array.String)
+	for i := range expectedAddress {
+		require.Equal(expectedAddress[i], rec.Column(schema.FieldIndices("address")[0]).(*array.String).Value(i))
+		require.Equal(expectedDeployer[i], rec.Column(schema.FieldIndices("deployer_address")[0]).(*array.String).Value(i))
+		require.Equal(expectedIsFactoryCreated[i], rec.Column(schema.FieldIndices("is_factory_created")[0]).(*array.Boolean).Value(i))
+		require.Equal(uint64(100), rec.Column(schema.FieldIndices("block_number")[0]).(*array.Uint64).Value(i))
+	}
+	require.True(bytecode.IsNull(0))
+	require.Equal("0x60", bytecode.Value(1))
+	require.Equal("0x61", bytecode.Value(2))
+}
+
+func TestTransformContracts_GethTraces(t *testing.T) {
+	require := require.New(t)
+
+	// The geth traces use the uppercase trace types, and every transaction has flattened traces.
+	block := &chainstorageapi.EthereumBlock{
+		Header: &chainstorageapi.EthereumHeader{
+			Number:    100,
+			Hash:      "0xabc",
+			Timestamp: &timestamppb.Timestamp{Seconds: 1700000000},
+		},
+		Transactions: []*chainstorageapi.EthereumTransaction{
+			{
+				Hash: "0x1",
+				From: testEOA,
+				Receipt: &chainstorageapi.EthereumTransactionReceipt{
+					ContractAddress: "0xf1",
+					OptionalStatus:  &chainstorageapi.EthereumTransactionReceipt_Status{Status: 1},
+				},
+				FlattenedTraces: []*chainstorageapi.EthereumTransactionFlattenedTrace{
+					{TransactionHash: "0x1", Type: "CREATE", TraceType: "CREATE", From: testEOA, To: "0xf1", Output: "0x60", TraceAddress: []uint64{}},
+					{TransactionHash: "0x1", Type: "CREATE2", TraceType: "CREATE2", From: "0xf1", To: "0xc1", Output: "0x61", TraceAddress: []uint64{0}},
+					{TransactionHash: "0x1", Type: "STATICCALL", TraceType: "CALL", CallType: "STATICCALL", From: "0xf1", To: "0xc1", TraceAddress: []uint64{1}},
+				},
+			},
+			{
+				Hash:  "0x2",
+				Index: 1,
+				From:  testEOA,
+				Receipt: &chainstorageapi.EthereumTransactionReceipt{
+					OptionalStatus: &chainstorageapi.EthereumTransactionReceipt_Status{Status: 1},
+				},
+				FlattenedTraces: []*chainstorageapi.EthereumTransactionFlattenedTrace{
+					{TransactionHash: "0x2", Type: "CALL", TraceType: "CALL", CallType: "CALL", From: testEOA, To: "0xf1", TraceAddress: []uint64{}},
+					{TransactionHash: "0x2", Type: "CREATE", TraceType: "CREATE", From: "0xf1", To: "0xc2", Output: "0x62", TraceAddress: []uint64{0}},
+				},
+			},
+		},
+	}
+
+	schema := newContractSchema()
+	recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), schema, nil)
+	defer recordBuilder.Release()
+
+	require.NoError(contractsTable{}.transformContracts(recordBuilder, block, partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+	rec := recordBuilder.NewRecord()
+	defer rec.Release()
+
+	expectedAddress := []string{"0xf1", "0xc1", "0xc2"}
+	expectedDeployer := []string{testEOA, "0xf1", "0xf1"}
+	expectedTransactionHash := []string{"0x1", "0x1", "0x2"}
+	expectedIsFactoryCreated := []bool{false, true, true}
+	expectedBytecode := []string{"0x60", "0x61", "0x62"}
+	require.Equal(int64(len(expectedAddress)), rec.NumRows())
+	for i := range expectedAddress {
+		require.Equal(expectedAddress[i], rec.Column(schema.FieldIndices("address")[0]).(*array.String).Value(i))
+		require.Equal(expectedDeployer[i], rec.Column(schema.FieldIndices("deployer_address")[0]).(*array.String).Value(i))
+		require.Equal(expectedTransactionHash[i], rec.Column(schema.FieldIndices("transaction_hash")[0]).(*array.String).Value(i))
+		require.Equal(expectedIsFactoryCreated[i], rec.Column(schema.FieldIndices("is_factory_created")[0]).(*array.Boolean).Value(i))
+		require.Equal(expectedBytecode[i], rec.Column(schema.FieldIndices("bytecode")[0]).(*array.String).Value(i))
+	}
+}
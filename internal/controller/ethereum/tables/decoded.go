@@ -0,0 +1,102 @@
+package tables
+
+import (
+	"context"
+
+	"golang.org/x/xerrors"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+	"github.com/coinbase/chainstorage/sdk"
+
+	"github.com/coinbase/chainsformer/internal/controller/ethereum/abi"
+	"github.com/coinbase/chainsformer/internal/controller/internal"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+	api "github.com/coinbase/chainsformer/protos/coinbase/chainsformer"
+)
+
+type (
+	decodedLogsTable struct {
+		registry *abi.Registry
+	}
+
+	decodedCallsTable struct {
+		registry *abi.Registry
+	}
+)
+
+const (
+	traceTypeCall = "call"
+)
+
+func NewDecodedLogsTable(params internal.CommonTableParams, registry *abi.Registry) internal.Table {
+	return internal.NewBatchTable(
+		&params,
+		internal.NewTableAttributes(internal.TableNameDecodedLogs),
+		newDecodedLogSchema(),
+		decodedLogsTable{registry: registry},
+	)
+}
+
+// ParseFilter parses the filter of the decoded logs. from_addresses, to_addresses and transaction_types are matched
+// against the transaction emitting each log, while log_addresses and topics are matched against each log,
+// so that only the matching logs of the matching transactions are returned.
+func (t decodedLogsTable) ParseFilter(filter *api.GetFlightInfoCmd_Filter) (internal.Filter, error) {
+	return parseTransactionFilter(filter)
+}
+
+func (t decodedLogsTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
+	nativeBlock, err := parser.ParseNativeBlock(ctx, block)
+	if err != nil {
+		return xerrors.Errorf("failed to parse raw block to native block: %w", err)
+	}
+
+	ethereumBlock := nativeBlock.GetEthereum()
+	if ethereumBlock == nil {
+		return xerrors.New("failed to extract ethereum block from native block")
+	}
+
+	logFilter := getTransactionFilter(filter)
+
+	if err := t.transformDecodedLogs(recordBuilder, ethereumBlock, logFilter, partitioner); err != nil {
+		return xerrors.Errorf("failed to transform decoded logs: %w", err)
+	}
+
+	return nil
+}
+
+func NewDecodedCallsTable(params internal.CommonTableParams, registry *abi.Registry) internal.Table {
+	return internal.NewBatchTable(
+		&params,
+		internal.NewTableAttributes(internal.TableNameDecodedCalls),
+		newDecodedCallSchema(),
+		decodedCallsTable{registry: registry},
+	)
+}
+
+// ParseFilter parses the filter of the decoded calls, which is matched against the transaction of each call:
+// from_addresses and to_addresses are the sender and recipient of the transaction, not of the internal calls,
+// and log_addresses and topics return every call of the transactions with at least one matching log.
+func (t decodedCallsTable) ParseFilter(filter *api.GetFlightInfoCmd_Filter) (internal.Filter, error) {
+	return parseTransactionFilter(filter)
+}
+
+func (t decodedCallsTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
+	nativeBlock, err := parser.ParseNativeBlock(ctx, block)
+	if err != nil {
+		return xerrors.Errorf("failed to parse raw block to native block: %w", err)
+	}
+
+	ethereumBlock := nativeBlock.GetEthereum()
+	if ethereumBlock == nil {
+		return xerrors.New("failed to extract ethereum block from native block")
+	}
+
+	callFilter := getTransactionFilter(filter)
+
+	if err := t.transformDecodedCalls(recordBuilder, ethereumBlock, callFilter, partitioner); err != nil {
+		return xerrors.Errorf("failed to transform decoded calls: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,292 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v10/arrow/array"
+	"github.com/apache/arrow/go/v10/arrow/memory"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+
+	"github.com/coinbase/chainsformer/internal/config"
+	"github.com/coinbase/chainsformer/internal/controller/ethereum/abi"
+	"github.com/coinbase/chainsformer/internal/utils/fxparams"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+	api "github.com/coinbase/chainsformer/protos/coinbase/chainsformer"
+)
+
+const (
+	testValueData     = "0x00000000000000000000000000000000000000000000000000000000000f4240"
+	testTransferInput = "0xa9059cbb000000000000000000000000a0b86991c6218b36c1d19d4a2e9eb0ce3606eb4800000000000000000000000000000000000000000000000000000000000f4240"
+)
+
+func TestTransformDecodedLogs(t *testing.T) {
+	require := require.New(t)
+
+	block := &chainstorageapi.EthereumBlock{
+		Header: &chainstorageapi.EthereumHeader{
+			Number:    100,
+			Timestamp: &timestamppb.Timestamp{Seconds: 1700000000},
+		},
+		Transactions: []*chainstorageapi.EthereumTransaction{
+			{
+				From: testEOA,
+				To:   testUSDT,
+				Receipt: &chainstorageapi.EthereumTransactionReceipt{
+					Logs: []*chainstorageapi.EthereumEventLog{
+						{LogIndex: 0, Address: testUSDT, Topics: []string{testTransfer, testFromTopic, testToTopic}, Data: testValueData},
+						{LogIndex: 1, Address: testUSDT, Topics: []string{testApproval, testFromTopic, testToTopic}, Data: testValueData},
+					},
+				},
+			},
+		},
+	}
+
+	schema := newDecodedLogSchema()
+	recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), schema, nil)
+	defer recordBuilder.Release()
+
+	filter, err := newTransactionFilter(nil)
+	require.NoError(err)
+	table := decodedLogsTable{registry: newTestRegistry(t)}
+	require.NoError(table.transformDecodedLogs(recordBuilder, block, filter, partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+	rec := recordBuilder.NewRecord()
+	defer rec.Release()
+	require.Equal(int64(2), rec.NumRows())
+
+	eventName := rec.Column(schema.FieldIndices("event_name")[0]).(*array.String)
+	args := rec.Column(schema.FieldIndices("args")[0]).(*array.String)
+	require.Equal("Transfer", eventName.Value(0))
+	require.Equal(`{"from":"0x95222290dd7278aa3ddd389cc1e1d165cc4bafe5","to":"0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48","value":"1000000"}`, args.Value(0))
+
+	// The unknown event yields null decode columns.
+	require.True(eventName.IsNull(1))
+	require.True(args.IsNull(1))
+	require.Equal(uint64(1), rec.Column(schema.FieldIndices("log_index")[0]).(*array.Uint64).Value(1))
+}
+
+func TestTransformDecodedCalls(t *testing.T) {
+	require := require.New(t)
+
+	block := &chainstorageapi.EthereumBlock{
+		Header: &chainstorageapi.EthereumHeader{
+			Number:    100,
+			Hash:      "0xabc",
+			Timestamp: &timestamppb.Timestamp{Seconds: 1700000000},
+		},
+		Transactions: []*chainstorageapi.EthereumTransaction{
+			{
+				Hash:  "0x1",
+				From:  testEOA,
+				To:    testUSDT,
+				Input: testTransferInput,
+			},
+			{
+				Hash:  "0x2",
+				Index: 1,
+				From:  testEOA,
+				To:    testUSDC,
+				Input: "0x",
+			},
+			{
+				// The geth traces.
+				Hash:  "0x3",
+				Index: 2,
+				From:  testEOA,
+				To:    testUSDC,
+				FlattenedTraces: []*chainstorageapi.EthereumTransactionFlattenedTrace{
+					{Type: "CALL", TraceType: "CALL", CallType: "CALL", From: testEOA, To: testUSDC, Input: "0x12345678", TraceAddress: []uint64{}},
+					{Type: "DELEGATECALL", TraceType: "CALL", CallType: "DELEGATECALL", From: testUSDC, To: testUSDT, Input: testTransferInput, TraceAddress: []uint64{0}},
+					{Type: "CREATE", TraceType: "CREATE", From: testUSDC, Input: testTransferInput, TraceAddress: []uint64{1}},
+				},
+			},
+			{
+				// The parity traces.
+				Hash:  "0x4",
+				Index: 3,
+				From:  testEOA,
+				To:    testUSDT,
+				FlattenedTraces: []*chainstorageapi.EthereumTransactionFlattenedTrace{
+					{Type: "call", TraceType: "call", CallType: "call", From: testEOA, To: testUSDT, Input: testTransferInput},
+				},
+			},
+			{
+				// The root trace creates a contract.
+				Hash:  "0x5",
+				Index: 4,
+				From:  testEOA,
+				Input: "0x60806040",
+				FlattenedTraces: []*chainstorageapi.EthereumTransactionFlattenedTrace{
+					{Type: "CREATE", TraceType: "CREATE", From: testEOA, To: testUSDC, Input: "0x60806040", TraceAddress: []uint64{}},
+					{Type: "CALL", TraceType: "CALL", CallType: "CALL", From: testUSDC, To: testUSDT, Input: testTransferInput, TraceAddress: []uint64{0}},
+				},
+			},
+		},
+	}
+
+	schema := newDecodedCallSchema()
+	recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), schema, nil)
+	defer recordBuilder.Release()
+
+	filter, err := newTransactionFilter(nil)
+	require.NoError(err)
+	table := decodedCallsTable{registry: newTestRegistry(t)}
+	require.NoError(table.transformDecodedCalls(recordBuilder, block, filter, partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+	rec := recordBuilder.NewRecord()
+	defer rec.Release()
+	require.Equal(int64(6), rec.NumRows())
+
+	transactionHash := rec.Column(schema.FieldIndices("transaction_hash")[0]).(*array.String)
+	callType := rec.Column(schema.FieldIndices("call_type")[0]).(*array.String)
+	selector := rec.Column(schema.FieldIndices("function_selector")[0]).(*array.String)
+	functionName := rec.Column(schema.FieldIndices("function_name")[0]).(*array.String)
+	expectedTransactionHash := []string{"0x1", "0x3", "0x3", "0x4", "0x5", "0x5"}
+	expectedCallType := []string{"", "call", "delegatecall", "call", "", "call"}
+	expectedSelector := []string{"0xa9059cbb", "0x12345678", "0xa9059cbb", "0xa9059cbb", "0x60806040", "0xa9059cbb"}
+	expectedFunctionName := []string{"transfer", "", "transfer", "transfer", "", "transfer"}
+	for i := range expectedTransactionHash {
+		require.Equal(expectedTransactionHash[i], transactionHash.Value(i))
+		require.Equal(expectedCallType[i], callType.Value(i))
+		require.Equal(expectedSelector[i], selector.Value(i))
+		if expectedFunctionName[i] == "" {
+			require.True(functionName.IsNull(i))
+		} else {
+			require.Equal(expectedFunctionName[i], functionName.Value(i))
+		}
+	}
+}
+
+func TestTransformDecoded_Filter(t *testing.T) {
+	const router = "0x00000000000000000000000000000000000000aa"
+	block := &chainstorageapi.EthereumBlock{
+		Header: &chainstorageapi.EthereumHeader{
+			Number:    100,
+			Timestamp: &timestamppb.Timestamp{Seconds: 1700000000},
+		},
+		Transactions: []*chainstorageapi.EthereumTransaction{
+			{
+				Hash:  "0x1",
+				From:  testEOA,
+				To:    router,
+				Input: testTransferInput,
+				FlattenedTraces: []*chainstorageapi.EthereumTransactionFlattenedTrace{
+					{Type: "CALL", TraceType: "CALL", CallType: "CALL", From: testEOA, To: router, Input: testTransferInput, TraceAddress: []uint64{}},
+					{Type: "CALL", TraceType: "CALL", CallType: "CALL", From: router, To: testUSDT, Input: testTransferInput, TraceAddress: []uint64{0}},
+				},
+				Receipt: &chainstorageapi.EthereumTransactionReceipt{
+					Logs: []*chainstorageapi.EthereumEventLog{
+						{LogIndex: 0, TransactionHash: "0x1", Address: testUSDT, Topics: []string{testTransfer, testFromTopic, testToTopic}, Data: testValueData},
+						{LogIndex: 1, TransactionHash: "0x1", Address: testUSDT, Topics: []string{testApproval, testFromTopic, testToTopic}, Data: testValueData},
+						{LogIndex: 2, TransactionHash: "0x1", Address: testUSDC, Topics: []string{testTransfer, testFromTopic, testToTopic}, Data: testValueData},
+					},
+				},
+			},
+			{
+				Hash:  "0x2",
+				Index: 1,
+				From:  testUSDC,
+				To:    testUSDT,
+				Input: testTransferInput,
+				Receipt: &chainstorageapi.EthereumTransactionReceipt{
+					Logs: []*chainstorageapi.EthereumEventLog{
+						{LogIndex: 3, TransactionHash: "0x2", Address: testUSDT, Topics: []string{testTransfer, testFromTopic, testToTopic}, Data: testValueData},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name                     string
+		filter                   *api.GetFlightInfoCmd_Filter
+		expectedLogIndexes       []uint64
+		expectedCallTransactions []string
+	}{
+		{
+			name:                     "transaction sender and log address",
+			filter:                   &api.GetFlightInfoCmd_Filter{FromAddresses: []string{testEOA}, LogAddresses: []string{testUSDT}},
+			expectedLogIndexes:       []uint64{0, 1},
+			expectedCallTransactions: []string{"0x1", "0x1"},
+		},
+		{
+			name:                     "transaction sender and topic",
+			filter:                   &api.GetFlightInfoCmd_Filter{FromAddresses: []string{testEOA}, Topics: []string{testTransfer}},
+			expectedLogIndexes:       []uint64{0, 2},
+			expectedCallTransactions: []string{"0x1", "0x1"},
+		},
+		{
+			name:                     "transaction recipient, log address and topic",
+			filter:                   &api.GetFlightInfoCmd_Filter{ToAddresses: []string{testUSDT}, LogAddresses: []string{testUSDT}, Topics: []string{testTransfer}},
+			expectedLogIndexes:       []uint64{3},
+			expectedCallTransactions: []string{"0x2"},
+		},
+		{
+			// The recipient of an internal call does not match the recipient of the transaction.
+			name:   "internal call recipient and log address",
+			filter: &api.GetFlightInfoCmd_Filter{ToAddresses: []string{testUSDT}, LogAddresses: []string{testUSDC}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			registry := newTestRegistry(t)
+			logsTable := decodedLogsTable{registry: registry}
+			logsFilter, err := logsTable.ParseFilter(test.filter)
+			require.NoError(err)
+			logsSchema := newDecodedLogSchema()
+			logsBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), logsSchema, nil)
+			defer logsBuilder.Release()
+			require.NoError(logsTable.transformDecodedLogs(logsBuilder, block, getTransactionFilter(logsFilter), partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+			logs := logsBuilder.NewRecord()
+			defer logs.Release()
+			logIndex := logs.Column(logsSchema.FieldIndices("log_index")[0]).(*array.Uint64)
+			actualLogIndexes := []uint64{}
+			for i := 0; i < int(logs.NumRows()); i++ {
+				actualLogIndexes = append(actualLogIndexes, logIndex.Value(i))
+			}
+			if test.expectedLogIndexes == nil {
+				test.expectedLogIndexes = []uint64{}
+			}
+			require.Equal(test.expectedLogIndexes, actualLogIndexes)
+
+			callsTable := decodedCallsTable{registry: registry}
+			callsFilter, err := callsTable.ParseFilter(test.filter)
+			require.NoError(err)
+			callsSchema := newDecodedCallSchema()
+			callsBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), callsSchema, nil)
+			defer callsBuilder.Release()
+			require.NoError(callsTable.transformDecodedCalls(callsBuilder, block, getTransactionFilter(callsFilter), partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+			calls := callsBuilder.NewRecord()
+			defer calls.Release()
+			transactionHash := calls.Column(callsSchema.FieldIndices("transaction_hash")[0]).(*array.String)
+			actualCallTransactions := []string{}
+			for i := 0; i < int(calls.NumRows()); i++ {
+				actualCallTransactions = append(actualCallTransactions, transactionHash.Value(i))
+			}
+			if test.expectedCallTransactions == nil {
+				test.expectedCallTransactions = []string{}
+			}
+			require.Equal(test.expectedCallTransactions, actualCallTransactions)
+		})
+	}
+}
+
+func newTestRegistry(t *testing.T) *abi.Registry {
+	registry, err := abi.NewRegistry(abi.Params{
+		Params: fxparams.Params{
+			Config: &config.Config{
+				ABIRegistry: config.ABIRegistryConfig{Dir: "../abi/testdata"},
+			},
+		},
+	})
+	require.NoError(t, err)
+	return registry
+}
@@ -0,0 +1,258 @@
+package tables
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+	"golang.org/x/xerrors"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+
+	"github.com/coinbase/chainsformer/internal/controller/internal"
+	"github.com/coinbase/chainsformer/internal/errors"
+	api "github.com/coinbase/chainsformer/protos/coinbase/chainsformer"
+)
+
+type (
+	// transactionFilter evaluates a GetFlightInfoCmd_Filter against ethereum transactions.
+	// A nil transactionFilter matches every transaction.
+	transactionFilter struct {
+		fromAddresses    map[string]bool
+		toAddresses      map[string]bool
+		logAddresses     map[string]bool
+		topics           map[string]bool
+		transactionTypes map[uint64]bool
+		logAddressBlooms []bloomBits
+		topicBlooms      []bloomBits
+		// transferAddresses matches fromAddresses and toAddresses against the token transfers
+		// instead of the transactions.
+		transferAddresses bool
+	}
+
+	// bloomBits are the three bits set by a value in a logs bloom.
+	bloomBits [3]uint
+)
+
+const (
+	addressLength = 20
+	topicLength   = 32
+	bloomLength   = 256
+)
+
+func newTransactionFilter(filter *api.GetFlightInfoCmd_Filter) (*transactionFilter, error) {
+	if internal.IsEmptyFilter(filter) {
+		return nil, nil
+	}
+
+	f := &transactionFilter{
+		transactionTypes: make(map[uint64]bool, len(filter.GetTransactionTypes())),
+	}
+
+	var err error
+	if f.fromAddresses, _, err = parseHexValues(filter.GetFromAddresses(), addressLength); err != nil {
+		return nil, xerrors.Errorf("invalid from_addresses: %w", err)
+	}
+
+	if f.toAddresses, _, err = parseHexValues(filter.GetToAddresses(), addressLength); err != nil {
+		return nil, xerrors.Errorf("invalid to_addresses: %w", err)
+	}
+
+	if f.logAddresses, f.logAddressBlooms, err = parseHexValues(filter.GetLogAddresses(), addressLength); err != nil {
+		return nil, xerrors.Errorf("invalid log_addresses: %w", err)
+	}
+
+	if f.topics, f.topicBlooms, err = parseHexValues(filter.GetTopics(), topicLength); err != nil {
+		return nil, xerrors.Errorf("invalid topics: %w", err)
+	}
+
+	for _, transactionType := range filter.GetTransactionTypes() {
+		f.transactionTypes[transactionType] = true
+	}
+
+	return f, nil
+}
+
+// parseTransactionFilter implements internal.FilterParser for the transactions tables.
+func parseTransactionFilter(filter *api.GetFlightInfoCmd_Filter) (internal.Filter, error) {
+	f, err := newTransactionFilter(filter)
+	if err != nil {
+		return nil, xerrors.Errorf("%v: %w", err, errors.ErrInvalidArgument)
+	}
+
+	return f, nil
+}
+
+// parseTokenTransferFilter implements internal.FilterParser for the token transfers tables.
+// Unlike the transactions tables, from_addresses and to_addresses are matched against the sender and recipient
+// of each token transfer, e.g. to include the transfers sent through a router contract.
+// The other fields are matched as by parseTransactionFilter.
+func parseTokenTransferFilter(filter *api.GetFlightInfoCmd_Filter) (internal.Filter, error) {
+	f, err := newTransactionFilter(filter)
+	if err != nil {
+		return nil, xerrors.Errorf("%v: %w", err, errors.ErrInvalidArgument)
+	}
+
+	if f != nil {
+		f.transferAddresses = true
+	}
+
+	return f, nil
+}
+
+// getTransactionFilter returns the filter parsed by parseTransactionFilter, or nil if the query has no filter.
+func getTransactionFilter(filter internal.Filter) *transactionFilter {
+	f, _ := filter.(*transactionFilter)
+	return f
+}
+
+// matchBlock returns false if the logs bloom of the block proves that no log can match the filter.
+func (f *transactionFilter) matchBlock(header *chainstorageapi.EthereumHeader) bool {
+	if f == nil || (len(f.logAddressBlooms) == 0 && len(f.topicBlooms) == 0) {
+		return true
+	}
+
+	bloom, err := hex.DecodeString(strings.TrimPrefix(header.GetLogsBloom(), "0x"))
+	if err != nil || len(bloom) != bloomLength {
+		// The bloom cannot be used to rule out the block.
+		return true
+	}
+
+	return matchAnyBloom(bloom, f.logAddressBlooms) && matchAnyBloom(bloom, f.topicBlooms)
+}
+
+func (f *transactionFilter) matchTransaction(transaction *chainstorageapi.EthereumTransaction) bool {
+	if f == nil {
+		return true
+	}
+
+	if !f.transferAddresses && !f.matchAddresses(transaction.GetFrom(), transaction.GetTo()) {
+		return false
+	}
+
+	if len(f.transactionTypes) > 0 && !f.transactionTypes[transaction.GetType()] {
+		return false
+	}
+
+	if len(f.logAddresses) > 0 || len(f.topics) > 0 {
+		for _, log := range transaction.GetReceipt().GetLogs() {
+			if f.matchLog(log) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return true
+}
+
+// matchTokenTransfer returns whether the sender and recipient of the transfer match the filter.
+// The transfers are only filtered by address if the filter was parsed by parseTokenTransferFilter.
+func (f *transactionFilter) matchTokenTransfer(transfer *tokenTransfer) bool {
+	if f == nil || !f.transferAddresses {
+		return true
+	}
+
+	return f.matchAddresses(transfer.from, transfer.to)
+}
+
+func (f *transactionFilter) matchAddresses(from string, to string) bool {
+	if len(f.fromAddresses) > 0 && !f.fromAddresses[strings.ToLower(from)] {
+		return false
+	}
+
+	if len(f.toAddresses) > 0 && !f.toAddresses[strings.ToLower(to)] {
+		return false
+	}
+
+	return true
+}
+
+func (f *transactionFilter) matchLog(log *chainstorageapi.EthereumEventLog) bool {
+	if f == nil {
+		return true
+	}
+
+	if len(f.logAddresses) > 0 && !f.logAddresses[strings.ToLower(log.GetAddress())] {
+		return false
+	}
+
+	if len(f.topics) == 0 {
+		return true
+	}
+
+	for _, topic := range log.GetTopics() {
+		if f.topics[strings.ToLower(topic)] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseHexValues validates the hex encoded values and returns them in lower case, along with their bloom bits.
+func parseHexValues(values []string, length int) (map[string]bool, []bloomBits, error) {
+	res := make(map[string]bool, len(values))
+	blooms := make([]bloomBits, 0, len(values))
+	for _, value := range values {
+		value = strings.ToLower(value)
+		if !strings.HasPrefix(value, "0x") {
+			return nil, nil, xerrors.Errorf("value(%v) must be prefixed with 0x", value)
+		}
+
+		data, err := hex.DecodeString(value[2:])
+		if err != nil {
+			return nil, nil, xerrors.Errorf("failed to decode value(%v): %w", value, err)
+		}
+
+		if len(data) != length {
+			return nil, nil, xerrors.Errorf("value(%v) must be %d bytes long", value, length)
+		}
+
+		res[value] = true
+		blooms = append(blooms, newBloomBits(data))
+	}
+
+	return res, blooms, nil
+}
+
+// newBloomBits computes the bits set by the value in a logs bloom, as specified in the yellow paper:
+// the low 11 bits of the first three pairs of bytes of keccak256(value).
+func newBloomBits(data []byte) bloomBits {
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write(data)
+	hash := hasher.Sum(nil)
+
+	var bits bloomBits
+	for i := range bits {
+		bits[i] = (uint(hash[2*i])<<8 | uint(hash[2*i+1])) & (bloomLength*8 - 1)
+	}
+
+	return bits
+}
+
+func matchAnyBloom(bloom []byte, values []bloomBits) bool {
+	if len(values) == 0 {
+		return true
+	}
+
+	for _, bits := range values {
+		if bits.in(bloom) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (b bloomBits) in(bloom []byte) bool {
+	for _, bit := range b {
+		// The bloom is big-endian, i.e. bit 0 is the least significant bit of the last byte.
+		if bloom[bloomLength-1-bit/8]&(1<<(bit%8)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
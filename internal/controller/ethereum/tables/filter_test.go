@@ -0,0 +1,175 @@
+package tables
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+
+	internalerrors "github.com/coinbase/chainsformer/internal/errors"
+	api "github.com/coinbase/chainsformer/protos/coinbase/chainsformer"
+)
+
+const (
+	// The logs bloom of a block with a single USDT transfer log.
+	testLogsBloom = "0x00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000008000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000080000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000"
+	testUSDT      = "0xdAC17F958D2ee523a2206206994597C13D831ec7"
+	testTransfer  = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	testApproval  = "0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925"
+	testUSDC      = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+	testEOA       = "0x95222290dd7278aa3ddd389cc1e1d165cc4bafe5"
+)
+
+func TestTransactionFilter_MatchBlock(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   *api.GetFlightInfoCmd_Filter
+		expected bool
+	}{
+		{
+			name:     "empty",
+			expected: true,
+		},
+		{
+			name:     "log_address",
+			filter:   &api.GetFlightInfoCmd_Filter{LogAddresses: []string{testUSDT}},
+			expected: true,
+		},
+		{
+			name:     "log_address_and_topic",
+			filter:   &api.GetFlightInfoCmd_Filter{LogAddresses: []string{testUSDC, testUSDT}, Topics: []string{testTransfer}},
+			expected: true,
+		},
+		{
+			name:     "missing_log_address",
+			filter:   &api.GetFlightInfoCmd_Filter{LogAddresses: []string{testUSDC}},
+			expected: false,
+		},
+		{
+			name:     "missing_topic",
+			filter:   &api.GetFlightInfoCmd_Filter{LogAddresses: []string{testUSDT}, Topics: []string{testApproval}},
+			expected: false,
+		},
+		{
+			name:     "no_log_filter",
+			filter:   &api.GetFlightInfoCmd_Filter{FromAddresses: []string{testEOA}},
+			expected: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			filter, err := newTransactionFilter(test.filter)
+			require.NoError(err)
+			require.Equal(test.expected, filter.matchBlock(&chainstorageapi.EthereumHeader{LogsBloom: testLogsBloom}))
+			// Blocks with an invalid bloom cannot be ruled out.
+			require.True(filter.matchBlock(&chainstorageapi.EthereumHeader{}))
+		})
+	}
+}
+
+func TestTransactionFilter_MatchTransaction(t *testing.T) {
+	transaction := &chainstorageapi.EthereumTransaction{
+		From: testEOA,
+		To:   "0xdac17f958d2ee523a2206206994597c13d831ec7",
+		Type: 2,
+		Receipt: &chainstorageapi.EthereumTransactionReceipt{
+			Logs: []*chainstorageapi.EthereumEventLog{
+				{
+					Address: "0xdac17f958d2ee523a2206206994597c13d831ec7",
+					Topics:  []string{testTransfer},
+				},
+				{
+					Address: testUSDC,
+					Topics:  []string{testApproval},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		filter   *api.GetFlightInfoCmd_Filter
+		expected bool
+	}{
+		{
+			name:     "empty",
+			expected: true,
+		},
+		{
+			name:     "from_address",
+			filter:   &api.GetFlightInfoCmd_Filter{FromAddresses: []string{"0x95222290DD7278Aa3Ddd389Cc1E1d165CC4BAfe5"}},
+			expected: true,
+		},
+		{
+			name:     "to_address",
+			filter:   &api.GetFlightInfoCmd_Filter{ToAddresses: []string{testUSDC, testUSDT}},
+			expected: true,
+		},
+		{
+			name:     "mismatched_to_address",
+			filter:   &api.GetFlightInfoCmd_Filter{ToAddresses: []string{testUSDC}},
+			expected: false,
+		},
+		{
+			name:     "transaction_type",
+			filter:   &api.GetFlightInfoCmd_Filter{TransactionTypes: []uint64{0, 2}},
+			expected: true,
+		},
+		{
+			name:     "mismatched_transaction_type",
+			filter:   &api.GetFlightInfoCmd_Filter{FromAddresses: []string{testEOA}, TransactionTypes: []uint64{0}},
+			expected: false,
+		},
+		{
+			name:     "log_address_and_topic",
+			filter:   &api.GetFlightInfoCmd_Filter{LogAddresses: []string{testUSDC}, Topics: []string{testApproval}},
+			expected: true,
+		},
+		{
+			name:     "log_address_and_topic_in_different_logs",
+			filter:   &api.GetFlightInfoCmd_Filter{LogAddresses: []string{testUSDC}, Topics: []string{testTransfer}},
+			expected: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			filter, err := newTransactionFilter(test.filter)
+			require.NoError(err)
+			require.Equal(test.expected, filter.matchTransaction(transaction))
+		})
+	}
+}
+
+func TestParseTransactionFilter(t *testing.T) {
+	require := require.New(t)
+
+	filter, err := parseTransactionFilter(nil)
+	require.NoError(err)
+	require.Nil(getTransactionFilter(filter))
+	require.Nil(getTransactionFilter(nil))
+
+	filter, err = parseTransactionFilter(&api.GetFlightInfoCmd_Filter{LogAddresses: []string{testUSDT}, Topics: []string{testTransfer}})
+	require.NoError(err)
+	transactionFilter := getTransactionFilter(filter)
+	require.NotNil(transactionFilter)
+	require.True(transactionFilter.logAddresses[strings.ToLower(testUSDT)])
+	require.True(transactionFilter.topics[testTransfer])
+
+	for _, filter := range []*api.GetFlightInfoCmd_Filter{
+		{FromAddresses: []string{"dac17f958d2ee523a2206206994597c13d831ec7"}},
+		{ToAddresses: []string{"0xdac17f"}},
+		{LogAddresses: []string{"0xzzc17f958d2ee523a2206206994597c13d831ec7"}},
+		{Topics: []string{testUSDT}},
+	} {
+		_, err := parseTransactionFilter(filter)
+		require.Error(err)
+		require.True(errors.Is(err, internalerrors.ErrInvalidArgument))
+	}
+}
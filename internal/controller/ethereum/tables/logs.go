@@ -0,0 +1,87 @@
+package tables
+
+import (
+	"context"
+
+	"golang.org/x/xerrors"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+	"github.com/coinbase/chainstorage/sdk"
+
+	"github.com/coinbase/chainsformer/internal/controller/internal"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+	api "github.com/coinbase/chainsformer/protos/coinbase/chainsformer"
+)
+
+type (
+	logsTable               struct{}
+	nativeStreamedLogsTable struct{}
+)
+
+func NewLogsTable(params internal.CommonTableParams) internal.Table {
+	return internal.NewBatchTable(
+		&params,
+		internal.NewTableAttributes(internal.TableNameLogs),
+		newLogSchema(),
+		logsTable{},
+	)
+}
+
+func (t logsTable) ParseFilter(filter *api.GetFlightInfoCmd_Filter) (internal.Filter, error) {
+	return parseTransactionFilter(filter)
+}
+
+func (t logsTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
+	nativeBlock, err := parser.ParseNativeBlock(ctx, block)
+	if err != nil {
+		return xerrors.Errorf("failed to parse raw block to native block: %w", err)
+	}
+
+	ethereumBlock := nativeBlock.GetEthereum()
+	if ethereumBlock == nil {
+		return xerrors.New("failed to extract ethereum block from native block")
+	}
+
+	logFilter := getTransactionFilter(filter)
+
+	if err := t.transformLogs(recordBuilder, ethereumBlock, logFilter, partitioner); err != nil {
+		return xerrors.Errorf("failed to transform logs: %w", err)
+	}
+
+	return nil
+}
+
+func NewNativeStreamedLogsTable(params internal.CommonTableParams) internal.Table {
+	return internal.NewStreamTable(
+		&params,
+		internal.NewTableAttributes(internal.TableNameStreamedLogs),
+		newStreamedLogSchema(),
+		nativeStreamedLogsTable{},
+		params.Params.Config.Table.StreamTable,
+	)
+}
+
+func (t nativeStreamedLogsTable) ParseFilter(filter *api.GetFlightInfoCmd_Filter) (internal.Filter, error) {
+	return parseTransactionFilter(filter)
+}
+
+func (t nativeStreamedLogsTable) TransformBlock(ctx context.Context, blockAndEvent *internal.BlockAndEvent, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
+	nativeBlock, err := parser.ParseNativeBlock(ctx, blockAndEvent.Block)
+	if err != nil {
+		return xerrors.Errorf("failed to parse raw block to native block: %w", err)
+	}
+
+	ethereumBlock := nativeBlock.GetEthereum()
+	if ethereumBlock == nil {
+		return xerrors.New("failed to extract ethereum block from native block")
+	}
+
+	logFilter := getTransactionFilter(filter)
+
+	if err := t.transformStreamedLogs(recordBuilder, ethereumBlock, blockAndEvent.BlockChainEvent, logFilter, partitioner); err != nil {
+		return xerrors.Errorf("failed to transform logs: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v10/arrow/array"
+	"github.com/apache/arrow/go/v10/arrow/memory"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+	api "github.com/coinbase/chainsformer/protos/coinbase/chainsformer"
+)
+
+func TestTransformLogs(t *testing.T) {
+	block := &chainstorageapi.EthereumBlock{
+		Header: &chainstorageapi.EthereumHeader{
+			Number:    100,
+			Timestamp: &timestamppb.Timestamp{Seconds: 1700000000},
+		},
+		Transactions: []*chainstorageapi.EthereumTransaction{
+			{
+				From: testEOA,
+				To:   testUSDT,
+				Receipt: &chainstorageapi.EthereumTransactionReceipt{
+					OptionalStatus: &chainstorageapi.EthereumTransactionReceipt_Status{Status: 1},
+					Logs: []*chainstorageapi.EthereumEventLog{
+						{LogIndex: 0, Address: testUSDT, Topics: []string{testTransfer}},
+						{LogIndex: 1, Address: testUSDC, Topics: []string{testApproval}},
+					},
+				},
+			},
+			{
+				From: testEOA,
+				To:   testUSDC,
+				Receipt: &chainstorageapi.EthereumTransactionReceipt{
+					Logs: []*chainstorageapi.EthereumEventLog{
+						{LogIndex: 2, Address: testUSDC, Topics: []string{testTransfer}},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name              string
+		filter            *api.GetFlightInfoCmd_Filter
+		expectedLogIndex  []uint64
+		expectedToAddress []string
+	}{
+		{
+			name:              "empty",
+			expectedLogIndex:  []uint64{0, 1, 2},
+			expectedToAddress: []string{testUSDT, testUSDT, testUSDC},
+		},
+		{
+			name:              "log_address",
+			filter:            &api.GetFlightInfoCmd_Filter{LogAddresses: []string{testUSDC}},
+			expectedLogIndex:  []uint64{1, 2},
+			expectedToAddress: []string{testUSDT, testUSDC},
+		},
+		{
+			name:              "to_address_and_topic",
+			filter:            &api.GetFlightInfoCmd_Filter{ToAddresses: []string{testUSDT}, Topics: []string{testTransfer}},
+			expectedLogIndex:  []uint64{0},
+			expectedToAddress: []string{testUSDT},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			schema := newLogSchema()
+			recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), schema, nil)
+			defer recordBuilder.Release()
+
+			filter, err := newTransactionFilter(test.filter)
+			require.NoError(err)
+			require.NoError(logsTable{}.transformLogs(recordBuilder, block, filter, partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+			rec := recordBuilder.NewRecord()
+			defer rec.Release()
+			require.Equal(int64(len(test.expectedLogIndex)), rec.NumRows())
+			for i, logIndex := range test.expectedLogIndex {
+				require.Equal(logIndex, rec.Column(schema.FieldIndices("log_index")[0]).(*array.Uint64).Value(i))
+				require.Equal(uint64(1700000000), rec.Column(schema.FieldIndices("block_timestamp")[0]).(*array.Uint64).Value(i))
+				require.Equal(test.expectedToAddress[i], rec.Column(schema.FieldIndices("transaction_to_address")[0]).(*array.String).Value(i))
+				require.Equal(uint64(100), rec.Column(schema.FieldIndices("_repartition_by_range")[0]).(*array.Uint64).Value(i))
+			}
+		})
+	}
+}
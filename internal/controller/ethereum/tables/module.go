@@ -2,8 +2,6 @@ package tables
 
 import (
 	"go.uber.org/fx"
-
-	"github.com/coinbase/chainsformer/internal/controller/rosetta/tables"
 )
 
 var Module = fx.Options(
@@ -29,14 +27,47 @@ var Module = fx.Options(
 	}),
 	fx.Provide(fx.Annotated{
 		Group:  "ethereum",
-		Target: tables.NewRosettaTransactionsTable,
+		Target: NewLogsTable,
+	}),
+	fx.Provide(fx.Annotated{
+		Group:  "ethereum",
+		Target: NewNativeStreamedLogsTable,
+	}),
+	fx.Provide(fx.Annotated{
+		Group:  "ethereum",
+		Target: NewTracesTable,
+	}),
+	fx.Provide(fx.Annotated{
+		Group:  "ethereum",
+		Target: NewNativeStreamedTracesTable,
 	}),
 	fx.Provide(fx.Annotated{
 		Group:  "ethereum",
-		Target: tables.NewRosettaBlocksTable,
+		Target: NewTokenTransfersTable,
 	}),
 	fx.Provide(fx.Annotated{
 		Group:  "ethereum",
-		Target: tables.NewRawRosettaStreamedTransactionsTable,
+		Target: NewNativeStreamedTokenTransfersTable,
+	}),
+	fx.Provide(fx.Annotated{
+		Group:  "ethereum",
+		Target: NewDecodedLogsTable,
+	}),
+	fx.Provide(fx.Annotated{
+		Group:  "ethereum",
+		Target: NewDecodedCallsTable,
+	}),
+	fx.Provide(fx.Annotated{
+		Group:  "ethereum",
+		Target: NewContractsTable,
+	}),
+	// The withdrawals and uncle blocks tables are only registered for the networks where the data exists.
+	fx.Provide(fx.Annotated{
+		Group:  "ethereum,flatten",
+		Target: NewWithdrawalsTables,
+	}),
+	fx.Provide(fx.Annotated{
+		Group:  "ethereum,flatten",
+		Target: NewUncleBlocksTables,
 	}),
 )
@@ -126,6 +126,208 @@ func newRawStreamedTransactionSchema() *arrow.Schema {
 	)
 }
 
+func newLogSchema() *arrow.Schema {
+	f := xarrow.NewSchemaFactory()
+	fields := append(
+		newLogDataType().(*arrow.StructType).Fields(),
+		f.NewField("block_timestamp", arrow.PrimitiveTypes.Uint64, "The unix timestamp for when the block was collated"),
+		f.NewField("transaction_from_address", arrow.BinaryTypes.String, "Address of the sender of the transaction"),
+		f.NewField("transaction_to_address", arrow.BinaryTypes.String, "Address of the receiver of the transaction"),
+		f.NewField("transaction_status", arrow.PrimitiveTypes.Uint64, "Either 1 (success) or 0 (failure) (post Byzantium)"),
+		f.NewField("_partition_by", arrow.PrimitiveTypes.Uint64, "Records with the same _partition_by value will be stored in the same s3 directory"),
+		f.NewField("_repartition_by_range", arrow.PrimitiveTypes.Uint64, "Records will be range partitioned base on the _repartition_by_range column"),
+	)
+	return f.NewSchema(fields...)
+}
+
+func newStreamedLogSchema() *arrow.Schema {
+	logSchema := newLogSchema()
+	f := xarrow.NewSchemaFactory()
+
+	metadataFields := []arrow.Field{
+		f.NewField("_sequence_number", arrow.PrimitiveTypes.Int64, "Monotonically increasing event sequence number"),
+		f.NewField("_event_type", arrow.BinaryTypes.String, "Event type UNKNOWN, BLOCK_ADDED, BLOCK_REMOVED"),
+	}
+
+	return f.NewSchema(
+		append(metadataFields, logSchema.Fields()...)...,
+	)
+}
+
+func newTraceSchema() *arrow.Schema {
+	f := xarrow.NewSchemaFactory()
+	fields := append(
+		newTraceDataType().(*arrow.StructType).Fields(),
+		f.NewField("block_timestamp", arrow.PrimitiveTypes.Uint64, "The unix timestamp for when the block was collated"),
+		f.NewField("parent_trace_address", arrow.ListOf(arrow.PrimitiveTypes.Uint64), "The trace address of the parent call in call tree. Empty for the top-level call and the block-scoped traces"),
+		f.NewField("depth", arrow.PrimitiveTypes.Uint64, "The depth of the call in call tree, i.e. the length of trace_address. 0 for the top-level call"),
+		f.NewField("is_success", arrow.FixedWidthTypes.Boolean, "True when neither the call nor any of its parent calls failed, i.e. its effects were not reverted"),
+		f.NewField("_partition_by", arrow.PrimitiveTypes.Uint64, "Records with the same _partition_by value will be stored in the same s3 directory"),
+		f.NewField("_repartition_by_range", arrow.PrimitiveTypes.Uint64, "Records will be range partitioned base on the _repartition_by_range column"),
+	)
+	return f.NewSchema(fields...)
+}
+
+func newStreamedTraceSchema() *arrow.Schema {
+	traceSchema := newTraceSchema()
+	f := xarrow.NewSchemaFactory()
+
+	metadataFields := []arrow.Field{
+		f.NewField("_sequence_number", arrow.PrimitiveTypes.Int64, "Monotonically increasing event sequence number"),
+		f.NewField("_event_type", arrow.BinaryTypes.String, "Event type UNKNOWN, BLOCK_ADDED, BLOCK_REMOVED"),
+	}
+
+	return f.NewSchema(
+		append(metadataFields, traceSchema.Fields()...)...,
+	)
+}
+
+func newTokenTransferSchema() *arrow.Schema {
+	f := xarrow.NewSchemaFactory()
+	return f.NewSchema(
+		f.NewField("token_address", arrow.BinaryTypes.String, "Address of the token contract which emitted the transfer event"),
+		f.NewField("token_standard", arrow.BinaryTypes.String, "One of ERC20, ERC721, ERC1155"),
+		f.NewField("operator_address", arrow.BinaryTypes.String, "Address of the account approved to make the transfer. Only set for ERC1155"),
+		f.NewField("from_address", arrow.BinaryTypes.String, "Address of the sender. The zero address when the token is minted"),
+		f.NewField("to_address", arrow.BinaryTypes.String, "Address of the receiver. The zero address when the token is burned"),
+		f.NewField("token_id", arrow.BinaryTypes.String, "Id of the transferred token as string. Empty for ERC20"),
+		f.NewField("amount", xarrow.DecimalTypes.Decimal128, "Amount of tokens transferred as decimal, null when it cannot be represented as decimal. Always 1 for ERC721"),
+		f.NewField("amount_string", arrow.BinaryTypes.String, "Amount of tokens transferred as string"),
+		f.NewField("batch_index", arrow.PrimitiveTypes.Uint64, "Zero-based index of the transfer in the ERC1155 TransferBatch event. 0 for the other events"),
+		f.NewField("log_index", arrow.PrimitiveTypes.Uint64, "Integer of the log index position in the block"),
+		f.NewField("transaction_hash", arrow.BinaryTypes.String, "Hash of the transaction this transfer was created from"),
+		f.NewField("transaction_index", arrow.PrimitiveTypes.Uint64, "Integer of the transactions index position transfer was created from"),
+		f.NewField("block_hash", arrow.BinaryTypes.String, "Hash of the block where this transfer was in"),
+		f.NewField("block_number", arrow.PrimitiveTypes.Uint64, "The block number where this transfer was in"),
+		f.NewField("block_timestamp", arrow.PrimitiveTypes.Uint64, "The unix timestamp for when the block was collated"),
+		f.NewField("_partition_by", arrow.PrimitiveTypes.Uint64, "Records with the same _partition_by value will be stored in the same s3 directory"),
+		f.NewField("_repartition_by_range", arrow.PrimitiveTypes.Uint64, "Records will be range partitioned base on the _repartition_by_range column"),
+	)
+}
+
+func newStreamedTokenTransferSchema() *arrow.Schema {
+	tokenTransferSchema := newTokenTransferSchema()
+	f := xarrow.NewSchemaFactory()
+
+	metadataFields := []arrow.Field{
+		f.NewField("_sequence_number", arrow.PrimitiveTypes.Int64, "Monotonically increasing event sequence number"),
+		f.NewField("_event_type", arrow.BinaryTypes.String, "Event type UNKNOWN, BLOCK_ADDED, BLOCK_REMOVED"),
+	}
+
+	return f.NewSchema(
+		append(metadataFields, tokenTransferSchema.Fields()...)...,
+	)
+}
+
+func newDecodedLogSchema() *arrow.Schema {
+	f := xarrow.NewSchemaFactory()
+	fields := append(
+		newLogDataType().(*arrow.StructType).Fields(),
+		f.NewField("block_timestamp", arrow.PrimitiveTypes.Uint64, "The unix timestamp for when the block was collated"),
+		f.NewField("event_name", arrow.BinaryTypes.String, "Name of the decoded event, null when the event is not in the abi registry"),
+		f.NewField("event_signature", arrow.BinaryTypes.String, "Signature of the decoded event, e.g. Transfer(address,address,uint256)"),
+		f.NewField("args", arrow.BinaryTypes.String, "JSON object of the decoded arguments by name, with integers as decimal strings"),
+		f.NewField("_partition_by", arrow.PrimitiveTypes.Uint64, "Records with the same _partition_by value will be stored in the same s3 directory"),
+		f.NewField("_repartition_by_range", arrow.PrimitiveTypes.Uint64, "Records will be range partitioned base on the _repartition_by_range column"),
+	)
+	return f.NewSchema(fields...)
+}
+
+func newDecodedCallSchema() *arrow.Schema {
+	f := xarrow.NewSchemaFactory()
+	return f.NewSchema(
+		f.NewField("transaction_hash", arrow.BinaryTypes.String, "Hash of the transaction where this call was in"),
+		f.NewField("transaction_index", arrow.PrimitiveTypes.Uint64, "Zero-based index of the transaction"),
+		f.NewField("block_hash", arrow.BinaryTypes.String, "Hash of the block where this call was in"),
+		f.NewField("block_number", arrow.PrimitiveTypes.Uint64, "Block number where this call was in"),
+		f.NewField("block_timestamp", arrow.PrimitiveTypes.Uint64, "The unix timestamp for when the block was collated"),
+		f.NewField("trace_address", arrow.ListOf(arrow.PrimitiveTypes.Uint64), "The trace address of the call in call tree. Empty for the top-level call"),
+		f.NewField("call_type", arrow.BinaryTypes.String, "One of call, callcode, delegatecall, staticcall. Empty when the call is decoded from the transaction rather than its root trace"),
+		f.NewField("from_address", arrow.BinaryTypes.String, "Address of the caller"),
+		f.NewField("to_address", arrow.BinaryTypes.String, "Address of the called contract"),
+		f.NewField("function_selector", arrow.BinaryTypes.String, "The first 4 bytes of the input"),
+		f.NewField("function_name", arrow.BinaryTypes.String, "Name of the decoded function, null when the function is not in the abi registry"),
+		f.NewField("function_signature", arrow.BinaryTypes.String, "Signature of the decoded function, e.g. transfer(address,uint256)"),
+		f.NewField("args", arrow.BinaryTypes.String, "JSON object of the decoded arguments by name, with integers as decimal strings"),
+		f.NewField("_partition_by", arrow.PrimitiveTypes.Uint64, "Records with the same _partition_by value will be stored in the same s3 directory"),
+		f.NewField("_repartition_by_range", arrow.PrimitiveTypes.Uint64, "Records will be range partitioned base on the _repartition_by_range column"),
+	)
+}
+
+func newContractSchema() *arrow.Schema {
+	f := xarrow.NewSchemaFactory()
+	return f.NewSchema(
+		f.NewField("address", arrow.BinaryTypes.String, "Address of the created contract"),
+		f.NewField("deployer_address", arrow.BinaryTypes.String, "Address of the account or the factory contract which created the contract"),
+		f.NewField("transaction_hash", arrow.BinaryTypes.String, "Hash of the transaction which created the contract"),
+		f.NewField("transaction_index", arrow.PrimitiveTypes.Uint64, "Zero-based index of the transaction"),
+		f.NewField("trace_address", arrow.ListOf(arrow.PrimitiveTypes.Uint64), "The trace address of the create call in call tree. Empty when created by the transaction itself"),
+		f.NewField("block_hash", arrow.BinaryTypes.String, "Hash of the block where the contract was created"),
+		f.NewField("block_number", arrow.PrimitiveTypes.Uint64, "Block number where the contract was created"),
+		f.NewField("block_timestamp", arrow.PrimitiveTypes.Uint64, "The unix timestamp for when the block was collated"),
+		f.NewField("bytecode", arrow.BinaryTypes.String, "The deployed bytecode, i.e. the output of the create call. Null when the transaction has no traces"),
+		f.NewField("is_factory_created", arrow.FixedWidthTypes.Boolean, "True when the contract was created by another contract rather than by the transaction itself"),
+		f.NewField("_partition_by", arrow.PrimitiveTypes.Uint64, "Records with the same _partition_by value will be stored in the same s3 directory"),
+		f.NewField("_repartition_by_range", arrow.PrimitiveTypes.Uint64, "Records will be range partitioned base on the _repartition_by_range column"),
+	)
+}
+
+func newWithdrawalSchema() *arrow.Schema {
+	f := xarrow.NewSchemaFactory()
+	fields := append(
+		newWithdrawalDataType().(*arrow.StructType).Fields(),
+		f.NewField("amount_wei", xarrow.DecimalTypes.Decimal128, "Amount of ether given in Wei as decimal"),
+		f.NewField("block_hash", arrow.BinaryTypes.String, "Hash of the block where this withdrawal was in"),
+		f.NewField("block_number", arrow.PrimitiveTypes.Uint64, "Block number where this withdrawal was in"),
+		f.NewField("block_timestamp", arrow.PrimitiveTypes.Uint64, "The unix timestamp for when the block was collated"),
+		f.NewField("_partition_by", arrow.PrimitiveTypes.Uint64, "Records with the same _partition_by value will be stored in the same s3 directory"),
+		f.NewField("_repartition_by_range", arrow.PrimitiveTypes.Uint64, "Records will be range partitioned base on the _repartition_by_range column"),
+	)
+	return f.NewSchema(fields...)
+}
+
+func newStreamedWithdrawalSchema() *arrow.Schema {
+	withdrawalSchema := newWithdrawalSchema()
+	f := xarrow.NewSchemaFactory()
+
+	metadataFields := []arrow.Field{
+		f.NewField("_sequence_number", arrow.PrimitiveTypes.Int64, "Monotonically increasing event sequence number"),
+		f.NewField("_event_type", arrow.BinaryTypes.String, "Event type UNKNOWN, BLOCK_ADDED, BLOCK_REMOVED"),
+	}
+
+	return f.NewSchema(
+		append(metadataFields, withdrawalSchema.Fields()...)...,
+	)
+}
+
+func newUncleBlockSchema() *arrow.Schema {
+	f := xarrow.NewSchemaFactory()
+	fields := append(
+		NewBlockDataType().(*arrow.StructType).Fields(),
+		f.NewField("uncle_index", arrow.PrimitiveTypes.Uint64, "Zero-based index of the uncle in the block including it"),
+		f.NewField("nephew_block_hash", arrow.BinaryTypes.String, "Hash of the block including the uncle"),
+		f.NewField("nephew_block_number", arrow.PrimitiveTypes.Uint64, "Block number of the block including the uncle"),
+		f.NewField("nephew_block_timestamp", arrow.PrimitiveTypes.Uint64, "The unix timestamp for when the block including the uncle was collated"),
+		f.NewField("_partition_by", arrow.PrimitiveTypes.Uint64, "Records with the same _partition_by value will be stored in the same s3 directory"),
+		f.NewField("_repartition_by_range", arrow.PrimitiveTypes.Uint64, "Records will be range partitioned base on the _repartition_by_range column"),
+	)
+	return f.NewSchema(fields...)
+}
+
+func newStreamedUncleBlockSchema() *arrow.Schema {
+	uncleBlockSchema := newUncleBlockSchema()
+	f := xarrow.NewSchemaFactory()
+
+	metadataFields := []arrow.Field{
+		f.NewField("_sequence_number", arrow.PrimitiveTypes.Int64, "Monotonically increasing event sequence number"),
+		f.NewField("_event_type", arrow.BinaryTypes.String, "Event type UNKNOWN, BLOCK_ADDED, BLOCK_REMOVED"),
+	}
+
+	return f.NewSchema(
+		append(metadataFields, uncleBlockSchema.Fields()...)...,
+	)
+}
+
 func newStreamedBlocksSchema(config *config.Config) *arrow.Schema {
 	blockSchema := newBlockSchema(config)
 	f := xarrow.NewSchemaFactory()
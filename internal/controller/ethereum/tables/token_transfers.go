@@ -0,0 +1,245 @@
+package tables
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+	"github.com/coinbase/chainstorage/sdk"
+
+	"github.com/coinbase/chainsformer/internal/controller/internal"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+	api "github.com/coinbase/chainsformer/protos/coinbase/chainsformer"
+)
+
+type (
+	tokenTransfersTable               struct{}
+	nativeStreamedTokenTransfersTable struct{}
+
+	tokenStandard string
+
+	tokenTransfer struct {
+		standard tokenStandard
+		operator string
+		from     string
+		to       string
+		tokenID  string
+		amount   string
+	}
+)
+
+const (
+	tokenStandardERC20   tokenStandard = "ERC20"
+	tokenStandardERC721  tokenStandard = "ERC721"
+	tokenStandardERC1155 tokenStandard = "ERC1155"
+
+	// Transfer(address indexed from, address indexed to, uint256 value) of ERC20,
+	// which shares its signature with Transfer(address indexed from, address indexed to, uint256 indexed tokenId) of ERC721.
+	transferEventTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	// TransferSingle(address indexed operator, address indexed from, address indexed to, uint256 id, uint256 value) of ERC1155.
+	transferSingleEventTopic = "0xc3d58168c5ae7397731d063d5bbf3d657854427343f4c083240f7aacaa2d0f62"
+	// TransferBatch(address indexed operator, address indexed from, address indexed to, uint256[] ids, uint256[] values) of ERC1155.
+	transferBatchEventTopic = "0x4a39dc06d4c0dbc64b70af90fd698a233a518aa5d07e595d983b8c0526c8f7fb"
+
+	abiWordLength = 32
+)
+
+func NewTokenTransfersTable(params internal.CommonTableParams) internal.Table {
+	return internal.NewBatchTable(
+		&params,
+		internal.NewTableAttributes(internal.TableNameTokenTransfers),
+		newTokenTransferSchema(),
+		tokenTransfersTable{},
+	)
+}
+
+func (t tokenTransfersTable) ParseFilter(filter *api.GetFlightInfoCmd_Filter) (internal.Filter, error) {
+	return parseTokenTransferFilter(filter)
+}
+
+func (t tokenTransfersTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
+	nativeBlock, err := parser.ParseNativeBlock(ctx, block)
+	if err != nil {
+		return xerrors.Errorf("failed to parse raw block to native block: %w", err)
+	}
+
+	ethereumBlock := nativeBlock.GetEthereum()
+	if ethereumBlock == nil {
+		return xerrors.New("failed to extract ethereum block from native block")
+	}
+
+	logFilter := getTransactionFilter(filter)
+
+	if err := t.transformTokenTransfers(recordBuilder, ethereumBlock, logFilter, partitioner); err != nil {
+		return xerrors.Errorf("failed to transform token transfers: %w", err)
+	}
+
+	return nil
+}
+
+func NewNativeStreamedTokenTransfersTable(params internal.CommonTableParams) internal.Table {
+	return internal.NewStreamTable(
+		&params,
+		internal.NewTableAttributes(internal.TableNameStreamedTokenTransfers),
+		newStreamedTokenTransferSchema(),
+		nativeStreamedTokenTransfersTable{},
+		params.Params.Config.Table.StreamTable,
+	)
+}
+
+func (t nativeStreamedTokenTransfersTable) ParseFilter(filter *api.GetFlightInfoCmd_Filter) (internal.Filter, error) {
+	return parseTokenTransferFilter(filter)
+}
+
+func (t nativeStreamedTokenTransfersTable) TransformBlock(ctx context.Context, blockAndEvent *internal.BlockAndEvent, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
+	nativeBlock, err := parser.ParseNativeBlock(ctx, blockAndEvent.Block)
+	if err != nil {
+		return xerrors.Errorf("failed to parse raw block to native block: %w", err)
+	}
+
+	ethereumBlock := nativeBlock.GetEthereum()
+	if ethereumBlock == nil {
+		return xerrors.New("failed to extract ethereum block from native block")
+	}
+
+	logFilter := getTransactionFilter(filter)
+
+	if err := t.transformStreamedTokenTransfers(recordBuilder, ethereumBlock, blockAndEvent.BlockChainEvent, logFilter, partitioner); err != nil {
+		return xerrors.Errorf("failed to transform token transfers: %w", err)
+	}
+
+	return nil
+}
+
+// decodeTokenTransfers decodes the token transfers of a log emitted by an ERC20, ERC721 or ERC1155 contract.
+// It returns nil if the log is not a well-formed transfer event.
+func decodeTokenTransfers(log *chainstorageapi.EthereumEventLog) []*tokenTransfer {
+	topics := log.GetTopics()
+	if len(topics) == 0 {
+		return nil
+	}
+
+	data, err := hex.DecodeString(strings.TrimPrefix(log.GetData(), "0x"))
+	if err != nil {
+		return nil
+	}
+
+	switch strings.ToLower(topics[0]) {
+	case transferEventTopic:
+		// ERC20 and ERC721 share the event signature, but ERC721 indexes the token id.
+		switch {
+		case len(topics) == 3 && len(data) == abiWordLength:
+			return []*tokenTransfer{{
+				standard: tokenStandardERC20,
+				from:     decodeAddressTopic(topics[1]),
+				to:       decodeAddressTopic(topics[2]),
+				amount:   decodeUint256(data).String(),
+			}}
+		case len(topics) == 4 && len(data) == 0:
+			tokenID, ok := decodeUint256Topic(topics[3])
+			if !ok {
+				return nil
+			}
+
+			return []*tokenTransfer{{
+				standard: tokenStandardERC721,
+				from:     decodeAddressTopic(topics[1]),
+				to:       decodeAddressTopic(topics[2]),
+				tokenID:  tokenID.String(),
+				amount:   "1",
+			}}
+		}
+	case transferSingleEventTopic:
+		if len(topics) != 4 || len(data) != 2*abiWordLength {
+			return nil
+		}
+
+		return []*tokenTransfer{{
+			standard: tokenStandardERC1155,
+			operator: decodeAddressTopic(topics[1]),
+			from:     decodeAddressTopic(topics[2]),
+			to:       decodeAddressTopic(topics[3]),
+			tokenID:  decodeUint256(data[:abiWordLength]).String(),
+			amount:   decodeUint256(data[abiWordLength:]).String(),
+		}}
+	case transferBatchEventTopic:
+		if len(topics) != 4 || len(data) < 2*abiWordLength {
+			return nil
+		}
+
+		ids, ok := decodeUint256Array(data, decodeUint256(data[:abiWordLength]))
+		if !ok {
+			return nil
+		}
+
+		values, ok := decodeUint256Array(data, decodeUint256(data[abiWordLength:2*abiWordLength]))
+		if !ok || len(ids) != len(values) {
+			return nil
+		}
+
+		transfers := make([]*tokenTransfer, len(ids))
+		for i := range ids {
+			transfers[i] = &tokenTransfer{
+				standard: tokenStandardERC1155,
+				operator: decodeAddressTopic(topics[1]),
+				from:     decodeAddressTopic(topics[2]),
+				to:       decodeAddressTopic(topics[3]),
+				tokenID:  ids[i].String(),
+				amount:   values[i].String(),
+			}
+		}
+
+		return transfers
+	}
+
+	return nil
+}
+
+// decodeAddressTopic returns the address held by the last 20 bytes of the topic.
+func decodeAddressTopic(topic string) string {
+	topic = strings.ToLower(strings.TrimPrefix(topic, "0x"))
+	if len(topic) < 2*addressLength {
+		return "0x" + topic
+	}
+
+	return "0x" + topic[len(topic)-2*addressLength:]
+}
+
+func decodeUint256Topic(topic string) (*big.Int, bool) {
+	data, err := hex.DecodeString(strings.TrimPrefix(topic, "0x"))
+	if err != nil || len(data) != abiWordLength {
+		return nil, false
+	}
+
+	return decodeUint256(data), true
+}
+
+func decodeUint256(word []byte) *big.Int {
+	return new(big.Int).SetBytes(word)
+}
+
+// decodeUint256Array decodes the ABI encoded uint256[] at the offset of the data.
+func decodeUint256Array(data []byte, offset *big.Int) ([]*big.Int, bool) {
+	if !offset.IsUint64() || offset.Uint64() > uint64(len(data)-abiWordLength) {
+		return nil, false
+	}
+
+	start := offset.Uint64() + abiWordLength
+	length := decodeUint256(data[offset.Uint64():start])
+	if !length.IsUint64() || length.Uint64() > (uint64(len(data))-start)/abiWordLength {
+		return nil, false
+	}
+
+	res := make([]*big.Int, length.Uint64())
+	for i := range res {
+		wordStart := start + uint64(i)*abiWordLength
+		res[i] = decodeUint256(data[wordStart : wordStart+abiWordLength])
+	}
+
+	return res, true
+}
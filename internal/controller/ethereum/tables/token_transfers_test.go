@@ -0,0 +1,188 @@
+package tables
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow/go/v10/arrow/memory"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+	api "github.com/coinbase/chainsformer/protos/coinbase/chainsformer"
+)
+
+const (
+	testFromTopic     = "0x00000000000000000000000095222290dd7278aa3ddd389cc1e1d165cc4bafe5"
+	testToTopic       = "0x000000000000000000000000A0b86991c6218b36c1d19d4a2e9eb0ce3606eB48"
+	testOperatorTopic = "0x000000000000000000000000dac17f958d2ee523a2206206994597c13d831ec7"
+)
+
+func TestDecodeTokenTransfers(t *testing.T) {
+	tests := []struct {
+		name     string
+		log      *chainstorageapi.EthereumEventLog
+		expected []*tokenTransfer
+	}{
+		{
+			name: "erc20",
+			log: &chainstorageapi.EthereumEventLog{
+				Topics: []string{testTransfer, testFromTopic, testToTopic},
+				Data:   testABIWords(1000000),
+			},
+			expected: []*tokenTransfer{
+				{standard: tokenStandardERC20, from: testEOA, to: testUSDC, amount: "1000000"},
+			},
+		},
+		{
+			name: "erc721",
+			log: &chainstorageapi.EthereumEventLog{
+				Topics: []string{testTransfer, testFromTopic, testToTopic, testABIWords(42)},
+				Data:   "0x",
+			},
+			expected: []*tokenTransfer{
+				{standard: tokenStandardERC721, from: testEOA, to: testUSDC, tokenID: "42", amount: "1"},
+			},
+		},
+		{
+			name: "erc1155_transfer_single",
+			log: &chainstorageapi.EthereumEventLog{
+				Topics: []string{transferSingleEventTopic, testOperatorTopic, testFromTopic, testToTopic},
+				Data:   testABIWords(7, 3),
+			},
+			expected: []*tokenTransfer{
+				{standard: tokenStandardERC1155, operator: strings.ToLower(testUSDT), from: testEOA, to: testUSDC, tokenID: "7", amount: "3"},
+			},
+		},
+		{
+			name: "erc1155_transfer_batch",
+			log: &chainstorageapi.EthereumEventLog{
+				Topics: []string{transferBatchEventTopic, testOperatorTopic, testFromTopic, testToTopic},
+				// The ids [1, 2] at offset 64 and the values [10, 20] at offset 160.
+				Data: testABIWords(64, 160, 2, 1, 2, 2, 10, 20),
+			},
+			expected: []*tokenTransfer{
+				{standard: tokenStandardERC1155, operator: strings.ToLower(testUSDT), from: testEOA, to: testUSDC, tokenID: "1", amount: "10"},
+				{standard: tokenStandardERC1155, operator: strings.ToLower(testUSDT), from: testEOA, to: testUSDC, tokenID: "2", amount: "20"},
+			},
+		},
+		{
+			name: "erc1155_transfer_batch_out_of_range",
+			log: &chainstorageapi.EthereumEventLog{
+				Topics: []string{transferBatchEventTopic, testOperatorTopic, testFromTopic, testToTopic},
+				Data:   testABIWords(64, 160, 2, 1, 2, 3, 10, 20),
+			},
+		},
+		{
+			name: "erc20_without_data",
+			log: &chainstorageapi.EthereumEventLog{
+				Topics: []string{testTransfer, testFromTopic, testToTopic},
+				Data:   "0x",
+			},
+		},
+		{
+			name: "other_event",
+			log: &chainstorageapi.EthereumEventLog{
+				Topics: []string{testApproval, testFromTopic, testToTopic},
+				Data:   testABIWords(1),
+			},
+		},
+		{
+			name: "anonymous_event",
+			log:  &chainstorageapi.EthereumEventLog{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+			require.Equal(test.expected, decodeTokenTransfers(test.log))
+		})
+	}
+}
+
+// testABIWords returns the hex encoded ABI words of the values.
+func testABIWords(values ...uint64) string {
+	var sb strings.Builder
+	sb.WriteString("0x")
+	for _, value := range values {
+		sb.WriteString(fmt.Sprintf("%064x", value))
+	}
+
+	return sb.String()
+}
+
+func TestTransformTokenTransfers_Filter(t *testing.T) {
+	const router = "0x00000000000000000000000000000000000000aa"
+	// The transaction is sent by the EOA to a router, which transfers the tokens of the EOA to USDC.
+	block := &chainstorageapi.EthereumBlock{
+		Header: &chainstorageapi.EthereumHeader{
+			Number:    100,
+			Timestamp: &timestamppb.Timestamp{Seconds: 1700000000},
+		},
+		Transactions: []*chainstorageapi.EthereumTransaction{
+			{
+				From: testEOA,
+				To:   router,
+				Receipt: &chainstorageapi.EthereumTransactionReceipt{
+					Logs: []*chainstorageapi.EthereumEventLog{
+						{
+							Address: testUSDT,
+							Topics:  []string{testTransfer, testFromTopic, testToTopic},
+							Data:    testABIWords(1000000),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		filter       *api.GetFlightInfoCmd_Filter
+		expectedRows int64
+	}{
+		{
+			name:         "transfer sender",
+			filter:       &api.GetFlightInfoCmd_Filter{FromAddresses: []string{testEOA}},
+			expectedRows: 1,
+		},
+		{
+			name:         "transfer recipient",
+			filter:       &api.GetFlightInfoCmd_Filter{ToAddresses: []string{testUSDC}},
+			expectedRows: 1,
+		},
+		{
+			name:   "transaction recipient",
+			filter: &api.GetFlightInfoCmd_Filter{ToAddresses: []string{router}},
+		},
+		{
+			name:         "transfer sender and log address",
+			filter:       &api.GetFlightInfoCmd_Filter{FromAddresses: []string{testEOA}, LogAddresses: []string{testUSDT}},
+			expectedRows: 1,
+		},
+		{
+			name:   "transfer sender and other log address",
+			filter: &api.GetFlightInfoCmd_Filter{FromAddresses: []string{testEOA}, LogAddresses: []string{testUSDC}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			filter, err := tokenTransfersTable{}.ParseFilter(test.filter)
+			require.NoError(err)
+
+			recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), newTokenTransferSchema(), nil)
+			defer recordBuilder.Release()
+			require.NoError(tokenTransfersTable{}.transformTokenTransfers(recordBuilder, block, getTransactionFilter(filter), partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+			rec := recordBuilder.NewRecord()
+			defer rec.Release()
+			require.Equal(test.expectedRows, rec.NumRows())
+		})
+	}
+}
@@ -0,0 +1,370 @@
+package tables
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+
+	"github.com/coinbase/chainstorage/protos/coinbase/c3/common"
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+	"github.com/coinbase/chainstorage/sdk"
+
+	"github.com/coinbase/chainsformer/internal/config"
+	"github.com/coinbase/chainsformer/internal/controller/internal"
+	"github.com/coinbase/chainsformer/internal/utils/log"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+type (
+	tracesTable struct {
+		synthesizer *blockTraceSynthesizer
+	}
+
+	nativeStreamedTracesTable struct {
+		synthesizer *blockTraceSynthesizer
+	}
+
+	// blockTraceSynthesizer synthesizes the block-scoped traces of the chains traced with geth, whose traces only
+	// cover the transactions. The rewards are computed from the header and uncles of the blocks,
+	// and the genesis traces from the genesis allocations.
+	blockTraceSynthesizer struct {
+		// rewards is the fork schedule of the block rewards, or nil if the blocks have no reward.
+		rewards *blockRewardSchedule
+		genesis func() *core.Genesis
+	}
+
+	// blockRewardSchedule is the heights of the forks changing the block reward of the ethash consensus engine.
+	blockRewardSchedule struct {
+		byzantiumBlock      uint64
+		constantinopleBlock uint64
+	}
+
+	// parityBlockTrace is the raw parity trace. The traces scoped to the block have no transaction hash.
+	parityBlockTrace struct {
+		TransactionHash *string           `json:"transactionHash"`
+		Type            string            `json:"type"`
+		Action          parityTraceAction `json:"action"`
+		Result          parityTraceResult `json:"result"`
+		Error           string            `json:"error"`
+		BlockHash       string            `json:"blockHash"`
+		BlockNumber     uint64            `json:"blockNumber"`
+		TraceAddress    []uint64          `json:"traceAddress"`
+		Subtraces       uint64            `json:"subtraces"`
+	}
+
+	parityTraceAction struct {
+		CallType string         `json:"callType"`
+		From     string         `json:"from"`
+		To       string         `json:"to"`
+		Author   string         `json:"author"`
+		Value    *hexutil.Big   `json:"value"`
+		Gas      hexutil.Uint64 `json:"gas"`
+		Input    string         `json:"input"`
+	}
+
+	parityTraceResult struct {
+		GasUsed hexutil.Uint64 `json:"gasUsed"`
+		Output  string         `json:"output"`
+	}
+)
+
+const (
+	traceTypeReward  = "reward"
+	traceTypeGenesis = "genesis"
+	// unclesRewardDepth is the maximum distance of an uncle to the block including it.
+	unclesRewardDepth = 8
+	// uncleInclusionRewardDivisor divides the block reward paid to the miner for each included uncle.
+	uncleInclusionRewardDivisor = 32
+)
+
+var (
+	// Only the parity traces carry the block hash.
+	blockHashKey = []byte(`"blockHash"`)
+
+	// The block rewards of the ethash consensus engine, in wei.
+	frontierBlockReward       = big.NewInt(5e18)
+	byzantiumBlockReward      = big.NewInt(3e18)
+	constantinopleBlockReward = big.NewInt(2e18)
+
+	mainnetBlockRewards = &blockRewardSchedule{
+		byzantiumBlock:      4_370_000,
+		constantinopleBlock: 7_280_000,
+	}
+)
+
+// NewTracesTable returns the table of the flattened traces of the transactions, followed by the block-scoped traces.
+func NewTracesTable(params internal.CommonTableParams) internal.Table {
+	return internal.NewBatchTable(
+		&params,
+		internal.NewTableAttributes(internal.TableNameTraces),
+		newTraceSchema(),
+		tracesTable{
+			synthesizer: newBlockTraceSynthesizer(params.Config, params.Logger),
+		},
+	)
+}
+
+func (t tracesTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
+	nativeBlock, err := parser.ParseNativeBlock(ctx, block)
+	if err != nil {
+		return xerrors.Errorf("failed to parse raw block to native block: %w", err)
+	}
+
+	ethereumBlock := nativeBlock.GetEthereum()
+	if ethereumBlock == nil {
+		return xerrors.New("failed to extract ethereum block from native block")
+	}
+
+	blockTraces, err := getBlockTraces(block, ethereumBlock, t.synthesizer)
+	if err != nil {
+		return xerrors.Errorf("failed to parse block traces: %w", err)
+	}
+
+	if err := t.transformTraces(recordBuilder, ethereumBlock, blockTraces, partitioner); err != nil {
+		return xerrors.Errorf("failed to transform traces: %w", err)
+	}
+
+	return nil
+}
+
+func NewNativeStreamedTracesTable(params internal.CommonTableParams) internal.Table {
+	return internal.NewStreamTable(
+		&params,
+		internal.NewTableAttributes(internal.TableNameStreamedTraces),
+		newStreamedTraceSchema(),
+		nativeStreamedTracesTable{
+			synthesizer: newBlockTraceSynthesizer(params.Config, params.Logger),
+		},
+		params.Params.Config.Table.StreamTable,
+	)
+}
+
+func (t nativeStreamedTracesTable) TransformBlock(ctx context.Context, blockAndEvent *internal.BlockAndEvent, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
+	nativeBlock, err := parser.ParseNativeBlock(ctx, blockAndEvent.Block)
+	if err != nil {
+		return xerrors.Errorf("failed to parse raw block to native block: %w", err)
+	}
+
+	ethereumBlock := nativeBlock.GetEthereum()
+	if ethereumBlock == nil {
+		return xerrors.New("failed to extract ethereum block from native block")
+	}
+
+	blockTraces, err := getBlockTraces(blockAndEvent.Block, ethereumBlock, t.synthesizer)
+	if err != nil {
+		return xerrors.Errorf("failed to parse block traces: %w", err)
+	}
+
+	if err := t.transformStreamedTraces(recordBuilder, ethereumBlock, blockTraces, blockAndEvent.BlockChainEvent, partitioner); err != nil {
+		return xerrors.Errorf("failed to transform traces: %w", err)
+	}
+
+	return nil
+}
+
+// newBlockTraceSynthesizer returns the synthesizer of the block-scoped traces of the chain,
+// or nil if they are not synthesized, e.g. for the chains whose rewards are not paid by the execution layer.
+// Only the ethereum mainnet and goerli networks are supported. Goerli uses the clique consensus engine,
+// so only its genesis traces are synthesized.
+func newBlockTraceSynthesizer(cfg *config.Config, logger *zap.Logger) *blockTraceSynthesizer {
+	if cfg.Blockchain() != common.Blockchain_BLOCKCHAIN_ETHEREUM {
+		return nil
+	}
+
+	switch cfg.Network() {
+	case common.Network_NETWORK_ETHEREUM_MAINNET:
+		return &blockTraceSynthesizer{
+			rewards: mainnetBlockRewards,
+			genesis: core.DefaultGenesisBlock,
+		}
+	case common.Network_NETWORK_ETHEREUM_GOERLI:
+		return &blockTraceSynthesizer{
+			genesis: core.DefaultGoerliGenesisBlock,
+		}
+	default:
+		log.WithPackage(logger).Warn(
+			"block-scoped traces are not synthesized for the network, the traces tables will only include those of the parity traces",
+			zap.String("network", cfg.Network().String()),
+		)
+		return nil
+	}
+}
+
+// getBlockTraces returns the block-scoped traces of the block, parsed from the raw parity traces if any,
+// or synthesized by the synthesizer of the chain otherwise.
+func getBlockTraces(block *chainstorageapi.Block, ethereumBlock *chainstorageapi.EthereumBlock, synthesizer *blockTraceSynthesizer) ([]*chainstorageapi.EthereumTransactionFlattenedTrace, error) {
+	blockTraces, err := parseBlockTraces(block)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to parse block traces: %w", err)
+	}
+
+	if len(blockTraces) > 0 || synthesizer == nil {
+		return blockTraces, nil
+	}
+
+	return synthesizer.synthesizeBlockTraces(ethereumBlock)
+}
+
+// synthesizeBlockTraces returns the genesis traces of the genesis block, or the reward traces of the other blocks.
+func (s *blockTraceSynthesizer) synthesizeBlockTraces(block *chainstorageapi.EthereumBlock) ([]*chainstorageapi.EthereumTransactionFlattenedTrace, error) {
+	header := block.GetHeader()
+	if header == nil {
+		return nil, xerrors.New("header is required")
+	}
+
+	if header.Number == 0 {
+		return s.synthesizeGenesisTraces(header), nil
+	}
+
+	return s.synthesizeRewardTraces(header, block.GetUncles()), nil
+}
+
+// synthesizeRewardTraces returns the reward of the miner, followed by the rewards of the uncle miners,
+// as computed by the ethash consensus engine. The miner is also rewarded 1/32 of the block reward per included uncle.
+// The transaction fees are not part of the rewards, as in the parity traces.
+func (s *blockTraceSynthesizer) synthesizeRewardTraces(header *chainstorageapi.EthereumHeader, uncles []*chainstorageapi.EthereumHeader) []*chainstorageapi.EthereumTransactionFlattenedTrace {
+	// The blocks of the other consensus engines, e.g. clique, and of the proof of stake have no reward.
+	if s.rewards == nil || header.Difficulty == 0 {
+		return nil
+	}
+
+	blockReward := s.rewards.getBlockReward(header.Number)
+
+	minerReward := new(big.Int).Set(blockReward)
+	inclusionReward := new(big.Int).Div(blockReward, big.NewInt(uncleInclusionRewardDivisor))
+	blockTraces := make([]*chainstorageapi.EthereumTransactionFlattenedTrace, 1, len(uncles)+1)
+	for i, uncle := range uncles {
+		uncleReward := new(big.Int).SetUint64(uncle.Number + unclesRewardDepth - header.Number)
+		uncleReward.Mul(uncleReward, blockReward)
+		uncleReward.Div(uncleReward, big.NewInt(unclesRewardDepth))
+		blockTraces = append(blockTraces, newBlockTrace(header, traceTypeReward, uncle.Miner, uncleReward, i+1))
+		minerReward.Add(minerReward, inclusionReward)
+	}
+	blockTraces[0] = newBlockTrace(header, traceTypeReward, header.Miner, minerReward, 0)
+
+	return blockTraces
+}
+
+// getBlockReward returns the block reward of the height.
+func (s *blockRewardSchedule) getBlockReward(height uint64) *big.Int {
+	switch {
+	case height >= s.constantinopleBlock:
+		return constantinopleBlockReward
+	case height >= s.byzantiumBlock:
+		return byzantiumBlockReward
+	default:
+		return frontierBlockReward
+	}
+}
+
+// synthesizeGenesisTraces returns one trace per genesis allocation, ordered by address.
+func (s *blockTraceSynthesizer) synthesizeGenesisTraces(header *chainstorageapi.EthereumHeader) []*chainstorageapi.EthereumTransactionFlattenedTrace {
+	alloc := s.genesis().Alloc
+	addresses := make([]gethcommon.Address, 0, len(alloc))
+	for address := range alloc {
+		addresses = append(addresses, address)
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return bytes.Compare(addresses[i][:], addresses[j][:]) < 0
+	})
+
+	blockTraces := make([]*chainstorageapi.EthereumTransactionFlattenedTrace, 0, len(addresses))
+	for i, address := range addresses {
+		balance := alloc[address].Balance
+		if balance == nil {
+			balance = new(big.Int)
+		}
+
+		blockTraces = append(blockTraces, newBlockTrace(header, traceTypeGenesis, strings.ToLower(address.Hex()), balance, i))
+	}
+
+	return blockTraces
+}
+
+// newBlockTrace returns the successful block-scoped trace crediting the value to the address.
+func newBlockTrace(header *chainstorageapi.EthereumHeader, traceType string, to string, value *big.Int, traceIndex int) *chainstorageapi.EthereumTransactionFlattenedTrace {
+	return &chainstorageapi.EthereumTransactionFlattenedTrace{
+		Type:        traceType,
+		TraceType:   traceType,
+		To:          to,
+		Value:       value.String(),
+		BlockNumber: header.Number,
+		BlockHash:   header.Hash,
+		Status:      1,
+		TraceId:     fmt.Sprintf("%v_%v_%v", traceType, header.Number, traceIndex),
+	}
+}
+
+// parseBlockTraces parses the block-scoped traces, e.g. rewards and genesis, from the raw parity traces.
+// ChainStorage groups the parity traces of the native block by their transaction hash and drops the block-scoped traces,
+// which have no transaction hash. The geth traces do not include any block-scoped trace, which are synthesized instead.
+func parseBlockTraces(block *chainstorageapi.Block) ([]*chainstorageapi.EthereumTransactionFlattenedTrace, error) {
+	var blockTraces []*chainstorageapi.EthereumTransactionFlattenedTrace
+	traceIndexes := make(map[string]int)
+	for _, rawTrace := range block.GetEthereum().GetTransactionTraces() {
+		if !bytes.Contains(rawTrace, blockHashKey) {
+			continue
+		}
+
+		var trace parityBlockTrace
+		if err := json.Unmarshal(rawTrace, &trace); err != nil {
+			return nil, xerrors.Errorf("failed to parse trace: %w", err)
+		}
+
+		// The traces of the transactions are already in the native block.
+		if trace.TransactionHash != nil && *trace.TransactionHash != "" {
+			continue
+		}
+
+		to := trace.Action.To
+		if to == "" {
+			// The rewards are credited to the author.
+			to = trace.Action.Author
+		}
+
+		value := "0"
+		if trace.Action.Value != nil {
+			value = trace.Action.Value.ToInt().String()
+		}
+
+		var status uint64
+		if trace.Error == "" {
+			status = 1
+		}
+
+		traceIndex := traceIndexes[trace.Type]
+		traceIndexes[trace.Type] = traceIndex + 1
+		blockTraces = append(blockTraces, &chainstorageapi.EthereumTransactionFlattenedTrace{
+			Error:        trace.Error,
+			Type:         trace.Type,
+			TraceType:    trace.Type,
+			CallType:     trace.Action.CallType,
+			From:         trace.Action.From,
+			To:           to,
+			Value:        value,
+			Gas:          uint64(trace.Action.Gas),
+			GasUsed:      uint64(trace.Result.GasUsed),
+			Input:        trace.Action.Input,
+			Output:       trace.Result.Output,
+			TraceAddress: trace.TraceAddress,
+			Subtraces:    trace.Subtraces,
+			BlockNumber:  trace.BlockNumber,
+			BlockHash:    trace.BlockHash,
+			Status:       status,
+			TraceId:      fmt.Sprintf("%v_%v_%v", trace.Type, trace.BlockNumber, traceIndex),
+		})
+	}
+
+	return blockTraces, nil
+}
@@ -0,0 +1,248 @@
+package tables
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow/go/v10/arrow/array"
+	"github.com/apache/arrow/go/v10/arrow/memory"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/coinbase/chainstorage/protos/coinbase/c3/common"
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+
+	"github.com/coinbase/chainsformer/internal/config"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+func TestTransformTraces(t *testing.T) {
+	require := require.New(t)
+
+	const transactionHash = "0x1"
+	block := &chainstorageapi.EthereumBlock{
+		Header: &chainstorageapi.EthereumHeader{
+			Number:    100,
+			Timestamp: &timestamppb.Timestamp{Seconds: 1700000000},
+		},
+		Transactions: []*chainstorageapi.EthereumTransaction{
+			{
+				Hash: transactionHash,
+				FlattenedTraces: []*chainstorageapi.EthereumTransactionFlattenedTrace{
+					{TransactionHash: transactionHash, TraceAddress: nil},
+					{TransactionHash: transactionHash, TraceAddress: []uint64{0}, Error: "Reverted"},
+					{TransactionHash: transactionHash, TraceAddress: []uint64{0, 0}},
+					{TransactionHash: transactionHash, TraceAddress: []uint64{1}, Value: "1000"},
+				},
+			},
+		},
+	}
+
+	// The native block does not include the block-scoped traces, which are parsed from the raw block.
+	blockTraces, err := parseBlockTraces(newTestRawBlock())
+	require.NoError(err)
+
+	schema := newTraceSchema()
+	recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), schema, nil)
+	defer recordBuilder.Release()
+	require.NoError(tracesTable{}.transformTraces(recordBuilder, block, blockTraces, partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+	rec := recordBuilder.NewRecord()
+	defer rec.Release()
+	require.Equal(int64(5), rec.NumRows())
+
+	depth := rec.Column(schema.FieldIndices("depth")[0]).(*array.Uint64)
+	isSuccess := rec.Column(schema.FieldIndices("is_success")[0]).(*array.Boolean)
+	valueString := rec.Column(schema.FieldIndices("value_string")[0]).(*array.String)
+	parentTraceAddress := rec.Column(schema.FieldIndices("parent_trace_address")[0]).(*array.List)
+	parentTraceAddressValues := parentTraceAddress.ListValues().(*array.Uint64)
+
+	expectedDepth := []uint64{0, 1, 2, 1, 0}
+	expectedIsSuccess := []bool{true, false, false, true, true}
+	expectedParentTraceAddress := [][]uint64{{}, {}, {0}, {}, {}}
+	for i := 0; i < int(rec.NumRows()); i++ {
+		require.Equal(expectedDepth[i], depth.Value(i))
+		require.Equal(expectedIsSuccess[i], isSuccess.Value(i))
+
+		start, end := parentTraceAddress.ValueOffsets(i)
+		actual := []uint64{}
+		for j := start; j < end; j++ {
+			actual = append(actual, parentTraceAddressValues.Value(int(j)))
+		}
+		require.Equal(expectedParentTraceAddress[i], actual)
+	}
+
+	require.Equal("1000", valueString.Value(3))
+	require.Equal("2000000000000000000", valueString.Value(4))
+	require.Equal("", rec.Column(schema.FieldIndices("transaction_hash")[0]).(*array.String).Value(4))
+	require.Equal("reward", rec.Column(schema.FieldIndices("trace_type")[0]).(*array.String).Value(4))
+}
+
+func TestParseBlockTraces(t *testing.T) {
+	require := require.New(t)
+
+	blockTraces, err := parseBlockTraces(newTestRawBlock())
+	require.NoError(err)
+	require.Len(blockTraces, 1)
+
+	trace := blockTraces[0]
+	require.Equal("", trace.TransactionHash)
+	require.Equal("reward", trace.Type)
+	require.Equal("reward", trace.TraceType)
+	require.Equal(testEOA, trace.To)
+	require.Equal("2000000000000000000", trace.Value)
+	require.Equal("0xabc", trace.BlockHash)
+	require.Equal(uint64(100), trace.BlockNumber)
+	require.Equal(uint64(1), trace.Status)
+	require.Equal("reward_100_0", trace.TraceId)
+}
+
+func TestParseBlockTraces_GethTraces(t *testing.T) {
+	require := require.New(t)
+
+	block := &chainstorageapi.Block{
+		Blobdata: &chainstorageapi.Block_Ethereum{
+			Ethereum: &chainstorageapi.EthereumBlobdata{
+				TransactionTraces: [][]byte{
+					[]byte(`{"type":"CALL","from":"0x1","to":"0x2","value":"0x0","gas":"0x5208","gasUsed":"0x5208","input":"0x"}`),
+				},
+			},
+		},
+	}
+	blockTraces, err := parseBlockTraces(block)
+	require.NoError(err)
+	require.Empty(blockTraces)
+}
+
+func TestGetBlockTraces_Synthesized(t *testing.T) {
+	const (
+		miner      = "0x00000000000000000000000000000000000000aa"
+		uncleMiner = "0x00000000000000000000000000000000000000bb"
+	)
+	block := &chainstorageapi.EthereumBlock{
+		Header: &chainstorageapi.EthereumHeader{
+			Hash:       "0xabc",
+			Number:     5000000,
+			Miner:      miner,
+			Difficulty: 1,
+		},
+		Uncles: []*chainstorageapi.EthereumHeader{
+			{Number: 4999999, Miner: uncleMiner},
+		},
+	}
+	rawBlock := &chainstorageapi.Block{
+		Blobdata: &chainstorageapi.Block_Ethereum{
+			Ethereum: &chainstorageapi.EthereumBlobdata{},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		network  common.Network
+		block    *chainstorageapi.EthereumBlock
+		expected []*chainstorageapi.EthereumTransactionFlattenedTrace
+	}{
+		{
+			// The byzantium block reward is 3 ETH, the uncle is rewarded 7/8 of it and the miner 1/32 of it per uncle.
+			name:    "mainnet proof of work block",
+			network: common.Network_NETWORK_ETHEREUM_MAINNET,
+			block:   block,
+			expected: []*chainstorageapi.EthereumTransactionFlattenedTrace{
+				{Type: "reward", TraceType: "reward", To: miner, Value: "3093750000000000000", BlockNumber: 5000000, BlockHash: "0xabc", Status: 1, TraceId: "reward_5000000_0"},
+				{Type: "reward", TraceType: "reward", To: uncleMiner, Value: "2625000000000000000", BlockNumber: 5000000, BlockHash: "0xabc", Status: 1, TraceId: "reward_5000000_1"},
+			},
+		},
+		{
+			name:    "mainnet proof of stake block",
+			network: common.Network_NETWORK_ETHEREUM_MAINNET,
+			block: &chainstorageapi.EthereumBlock{
+				Header: &chainstorageapi.EthereumHeader{Number: 17000000, Miner: miner},
+			},
+		},
+		{
+			name:    "goerli clique block",
+			network: common.Network_NETWORK_ETHEREUM_GOERLI,
+			block:   block,
+		},
+		{
+			name:    "network without synthesized traces",
+			network: common.Network_NETWORK_POLYGON_MAINNET,
+			block:   block,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			cfg := &config.Config{Chain: config.ChainConfig{Blockchain: common.Blockchain_BLOCKCHAIN_ETHEREUM, Network: test.network}}
+			blockTraces, err := getBlockTraces(rawBlock, test.block, newBlockTraceSynthesizer(cfg, zaptest.NewLogger(t)))
+			require.NoError(err)
+			require.Equal(len(test.expected), len(blockTraces))
+			for i, expected := range test.expected {
+				require.True(proto.Equal(expected, blockTraces[i]), "expected %v, actual %v", expected, blockTraces[i])
+			}
+		})
+	}
+}
+
+func TestBlockRewardSchedule(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal("5000000000000000000", mainnetBlockRewards.getBlockReward(4_369_999).String())
+	require.Equal("3000000000000000000", mainnetBlockRewards.getBlockReward(4_370_000).String())
+	require.Equal("3000000000000000000", mainnetBlockRewards.getBlockReward(7_279_999).String())
+	require.Equal("2000000000000000000", mainnetBlockRewards.getBlockReward(7_280_000).String())
+}
+
+func TestGetBlockTraces_Genesis(t *testing.T) {
+	require := require.New(t)
+
+	cfg := &config.Config{Chain: config.ChainConfig{Blockchain: common.Blockchain_BLOCKCHAIN_ETHEREUM, Network: common.Network_NETWORK_ETHEREUM_MAINNET}}
+	block := &chainstorageapi.EthereumBlock{
+		Header: &chainstorageapi.EthereumHeader{Hash: "0xd4e5", Number: 0},
+	}
+	blockTraces, err := getBlockTraces(&chainstorageapi.Block{}, block, newBlockTraceSynthesizer(cfg, zaptest.NewLogger(t)))
+	require.NoError(err)
+	require.Len(blockTraces, 8893)
+	for i, trace := range blockTraces {
+		require.Equal("genesis", trace.TraceType)
+		require.Equal("0xd4e5", trace.BlockHash)
+		require.Equal(fmt.Sprintf("genesis_0_%d", i), trace.TraceId)
+		require.Equal(strings.ToLower(trace.To), trace.To)
+		if i > 0 {
+			require.Less(blockTraces[i-1].To, trace.To)
+		}
+	}
+}
+
+func TestGetBlockTraces_ParityTraces(t *testing.T) {
+	require := require.New(t)
+
+	// The parity block traces are not synthesized again.
+	cfg := &config.Config{Chain: config.ChainConfig{Blockchain: common.Blockchain_BLOCKCHAIN_ETHEREUM, Network: common.Network_NETWORK_ETHEREUM_MAINNET}}
+	block := &chainstorageapi.EthereumBlock{
+		Header: &chainstorageapi.EthereumHeader{Number: 100, Miner: testEOA, Difficulty: 1},
+	}
+	blockTraces, err := getBlockTraces(newTestRawBlock(), block, newBlockTraceSynthesizer(cfg, zaptest.NewLogger(t)))
+	require.NoError(err)
+	require.Len(blockTraces, 1)
+	require.Equal("2000000000000000000", blockTraces[0].Value)
+}
+
+// newTestRawBlock returns the raw block with the parity traces, including a block reward.
+func newTestRawBlock() *chainstorageapi.Block {
+	return &chainstorageapi.Block{
+		Blobdata: &chainstorageapi.Block_Ethereum{
+			Ethereum: &chainstorageapi.EthereumBlobdata{
+				TransactionTraces: [][]byte{
+					[]byte(`{"action":{"callType":"call","from":"` + testEOA + `","gas":"0x5208","input":"0x","to":"0x2","value":"0x3e8"},"blockHash":"0xabc","blockNumber":100,"result":{"gasUsed":"0x0","output":"0x"},"subtraces":0,"traceAddress":[],"transactionHash":"0x1","transactionPosition":0,"type":"call"}`),
+					[]byte(`{"action":{"author":"` + testEOA + `","rewardType":"block","value":"0x1bc16d674ec80000"},"blockHash":"0xabc","blockNumber":100,"result":null,"subtraces":0,"traceAddress":[],"transactionHash":null,"transactionPosition":null,"type":"reward"}`),
+				},
+			},
+		},
+	}
+}
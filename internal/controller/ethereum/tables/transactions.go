@@ -3,7 +3,6 @@ package tables
 import (
 	"context"
 
-	"github.com/apache/arrow/go/v10/arrow/array"
 	"golang.org/x/xerrors"
 
 	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
@@ -12,6 +11,9 @@ import (
 	"github.com/coinbase/chainsformer/internal/config"
 	"github.com/coinbase/chainsformer/internal/controller/internal"
 	"github.com/coinbase/chainsformer/internal/controller/internal/constant"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+	api "github.com/coinbase/chainsformer/protos/coinbase/chainsformer"
 )
 
 type (
@@ -29,15 +31,19 @@ type (
 func NewTransactionsTable(params internal.CommonTableParams) internal.Table {
 	return internal.NewBatchTable(
 		&params,
-		internal.NewTableAttributes(internal.TableNameTransactions),
+		internal.NewTableAttributes(internal.TableNameTransactions, internal.WithEstimateMode(constant.EstimateModeTransactions)),
 		newTransactionSchema(params.Config),
 		transactionsTable{
-			params.Config,
+			config: params.Config,
 		},
 	)
 }
 
-func (t transactionsTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *array.RecordBuilder, partitionBySize uint64) error {
+func (t transactionsTable) ParseFilter(filter *api.GetFlightInfoCmd_Filter) (internal.Filter, error) {
+	return parseTransactionFilter(filter)
+}
+
+func (t transactionsTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
 	nativeBlock, err := parser.ParseNativeBlock(ctx, block)
 	if err != nil {
 		return xerrors.Errorf("failed to parse raw block to native block: %w", err)
@@ -48,7 +54,9 @@ func (t transactionsTable) TransformBlock(ctx context.Context, block *chainstora
 		return xerrors.New("failed to extract ethereum block from native block")
 	}
 
-	if err := t.transformTransactions(recordBuilder, ethereumBlock, partitionBySize); err != nil {
+	transactionFilter := getTransactionFilter(filter)
+
+	if err := t.transformTransactions(recordBuilder, ethereumBlock, transactionFilter, partitioner); err != nil {
 		return xerrors.Errorf("failed to transform transactions: %w", err)
 	}
 
@@ -61,13 +69,17 @@ func NewNativeStreamedTransactionsTable(params internal.CommonTableParams) inter
 		internal.NewTableAttributes(internal.TableNameStreamedTransactions),
 		newStreamedTransactionSchema(params.Config),
 		nativeStreamedTransactionsTable{
-			params.Config,
+			config: params.Config,
 		},
 		params.Params.Config.Table.StreamTable,
 	)
 }
 
-func (t nativeStreamedTransactionsTable) TransformBlock(ctx context.Context, blockAndEvent *internal.BlockAndEvent, parser sdk.Parser, recordBuilder *array.RecordBuilder, partitionBySize uint64) error {
+func (t nativeStreamedTransactionsTable) ParseFilter(filter *api.GetFlightInfoCmd_Filter) (internal.Filter, error) {
+	return parseTransactionFilter(filter)
+}
+
+func (t nativeStreamedTransactionsTable) TransformBlock(ctx context.Context, blockAndEvent *internal.BlockAndEvent, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
 	nativeBlock, err := parser.ParseNativeBlock(ctx, blockAndEvent.Block)
 	if err != nil {
 		return xerrors.Errorf("failed to parse raw block to native block: %w", err)
@@ -78,7 +90,9 @@ func (t nativeStreamedTransactionsTable) TransformBlock(ctx context.Context, blo
 		return xerrors.New("failed to extract ethereum block from native block")
 	}
 
-	if err := t.transformStreamedTransactions(recordBuilder, ethereumBlock, blockAndEvent.BlockChainEvent, partitionBySize); err != nil {
+	transactionFilter := getTransactionFilter(filter)
+
+	if err := t.transformStreamedTransactions(recordBuilder, ethereumBlock, blockAndEvent.BlockChainEvent, transactionFilter, partitioner); err != nil {
 		return xerrors.Errorf("failed to transform transactions: %w", err)
 	}
 
@@ -93,13 +107,17 @@ func NewRawNativeStreamedTransactionsTable(params internal.CommonTableParams) in
 			internal.WithEncoding(constant.EncodingRaw)),
 		newRawStreamedTransactionSchema(),
 		rawNativeStreamedTransactionsTable{
-			params.Config,
+			config: params.Config,
 		},
 		params.Params.Config.Table.StreamTable,
 	)
 }
 
-func (t rawNativeStreamedTransactionsTable) TransformBlock(ctx context.Context, blockAndEvent *internal.BlockAndEvent, parser sdk.Parser, recordBuilder *array.RecordBuilder, partitionBySize uint64) error {
+func (t rawNativeStreamedTransactionsTable) ParseFilter(filter *api.GetFlightInfoCmd_Filter) (internal.Filter, error) {
+	return parseTransactionFilter(filter)
+}
+
+func (t rawNativeStreamedTransactionsTable) TransformBlock(ctx context.Context, blockAndEvent *internal.BlockAndEvent, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
 	nativeBlock, err := parser.ParseNativeBlock(ctx, blockAndEvent.Block)
 	if err != nil {
 		return xerrors.Errorf("failed to parse raw block to native block: %w", err)
@@ -110,7 +128,9 @@ func (t rawNativeStreamedTransactionsTable) TransformBlock(ctx context.Context,
 		return xerrors.New("failed to extract ethereum block from native block")
 	}
 
-	if err := t.transformRawStreamedTransactions(recordBuilder, ethereumBlock, blockAndEvent.BlockChainEvent, partitionBySize); err != nil {
+	transactionFilter := getTransactionFilter(filter)
+
+	if err := t.transformRawStreamedTransactions(recordBuilder, ethereumBlock, blockAndEvent.BlockChainEvent, transactionFilter, partitioner); err != nil {
 		return xerrors.Errorf("failed to transform transactions: %w", err)
 	}
 
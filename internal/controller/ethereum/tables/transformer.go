@@ -1,7 +1,11 @@
 package tables
 
 import (
-	"github.com/apache/arrow/go/v10/arrow/array"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/apache/arrow/go/v10/arrow/decimal128"
 	"github.com/golang/protobuf/proto"
 	"golang.org/x/xerrors"
 
@@ -10,22 +14,27 @@ import (
 	"github.com/coinbase/chainstorage/protos/coinbase/c3/common"
 
 	"github.com/coinbase/chainsformer/internal/config"
+	"github.com/coinbase/chainsformer/internal/controller/ethereum/abi"
 	"github.com/coinbase/chainsformer/internal/utils/partition"
 	"github.com/coinbase/chainsformer/internal/utils/xarrow"
 )
 
-func (t transactionsTable) transformTransactions(recordBuilder *array.RecordBuilder, block *chainstorageapi.EthereumBlock, partitionBySize uint64) error {
+func (t transactionsTable) transformTransactions(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.EthereumBlock, filter *transactionFilter, partitioner *partition.Partitioner) error {
 	header := block.Header
 	if header == nil {
 		return xerrors.New("header is required")
 	}
 
 	transactions := block.GetTransactions()
-	if len(transactions) == 0 {
+	if len(transactions) == 0 || !filter.matchBlock(header) {
 		return nil
 	}
 
 	for _, transaction := range transactions {
+		if !filter.matchTransaction(transaction) {
+			continue
+		}
+
 		recordAppender := xarrow.NewRecordAppender(recordBuilder)
 		recordAppender.AppendString(transaction.Hash).
 			AppendUint64(transaction.Index).
@@ -60,7 +69,7 @@ func (t transactionsTable) transformTransactions(recordBuilder *array.RecordBuil
 			AppendList(func(la *xarrow.ListAppender) {
 				transformTraces(la, transaction)
 			}).
-			AppendUint64(partition.GetPartitionByNumber(header.Number, partitionBySize)).
+			AppendUint64(partitioner.GetPartitionBy(header.Number, 0, header.GetTimestamp().AsTime())).
 			AppendUint64(header.Number).
 			Build()
 	}
@@ -68,7 +77,7 @@ func (t transactionsTable) transformTransactions(recordBuilder *array.RecordBuil
 	return nil
 }
 
-func (t blocksTable) transformBlocks(recordBuilder *array.RecordBuilder, block *chainstorageapi.EthereumBlock, partitionBySize uint64) error {
+func (t blocksTable) transformBlocks(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.EthereumBlock, partitioner *partition.Partitioner) error {
 	header := block.GetHeader()
 	if header == nil {
 		return xerrors.New("header is required")
@@ -124,24 +133,28 @@ func (t blocksTable) transformBlocks(recordBuilder *array.RecordBuilder, block *
 		}).AppendString(header.WithdrawalsRoot)
 	}
 
-	ra.AppendUint64(partition.GetPartitionByNumber(header.Number, partitionBySize)).
+	ra.AppendUint64(partitioner.GetPartitionBy(header.Number, 0, header.GetTimestamp().AsTime())).
 		AppendUint64(header.Number).
 		Build()
 	return nil
 }
 
-func (t nativeStreamedTransactionsTable) transformStreamedTransactions(recordBuilder *array.RecordBuilder, block *chainstorageapi.EthereumBlock, event *chainstorageapi.BlockchainEvent, partitionBySize uint64) error {
+func (t nativeStreamedTransactionsTable) transformStreamedTransactions(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.EthereumBlock, event *chainstorageapi.BlockchainEvent, filter *transactionFilter, partitioner *partition.Partitioner) error {
 	header := block.Header
 	if header == nil {
 		return xerrors.New("header is required")
 	}
 
 	transactions := block.GetTransactions()
-	if len(transactions) == 0 {
+	if len(transactions) == 0 || !filter.matchBlock(header) {
 		return nil
 	}
 
 	for _, transaction := range transactions {
+		if !filter.matchTransaction(transaction) {
+			continue
+		}
+
 		recordAppender := xarrow.NewRecordAppender(recordBuilder)
 		recordAppender.AppendInt64(event.GetSequenceNum()).
 			AppendString(event.GetType().String()).
@@ -178,7 +191,7 @@ func (t nativeStreamedTransactionsTable) transformStreamedTransactions(recordBui
 			AppendList(func(la *xarrow.ListAppender) {
 				transformTraces(la, transaction)
 			}).
-			AppendUint64(partition.GetPartitionByNumber(uint64(event.GetSequenceNum()), partitionBySize)).
+			AppendUint64(partitioner.GetPartitionBy(header.Number, event.GetSequenceNum(), header.GetTimestamp().AsTime())).
 			AppendUint64(uint64(event.GetSequenceNum())).
 			Build()
 	}
@@ -186,7 +199,7 @@ func (t nativeStreamedTransactionsTable) transformStreamedTransactions(recordBui
 	return nil
 }
 
-func (t nativeStreamedBlocksTable) transformStreamedBlocks(recordBuilder *array.RecordBuilder, block *chainstorageapi.EthereumBlock, event *chainstorageapi.BlockchainEvent, partitionBySize uint64) error {
+func (t nativeStreamedBlocksTable) transformStreamedBlocks(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.EthereumBlock, event *chainstorageapi.BlockchainEvent, partitioner *partition.Partitioner) error {
 	header := block.GetHeader()
 	if header == nil {
 		return xerrors.New("header is required")
@@ -244,25 +257,29 @@ func (t nativeStreamedBlocksTable) transformStreamedBlocks(recordBuilder *array.
 		}).AppendString(header.WithdrawalsRoot)
 	}
 
-	ra.AppendUint64(partition.GetPartitionByNumber(uint64(event.GetSequenceNum()), partitionBySize)).
+	ra.AppendUint64(partitioner.GetPartitionBy(header.Number, event.GetSequenceNum(), header.GetTimestamp().AsTime())).
 		AppendUint64(uint64(event.GetSequenceNum())).
 		Build()
 
 	return nil
 }
 
-func (t rawNativeStreamedTransactionsTable) transformRawStreamedTransactions(recordBuilder *array.RecordBuilder, block *chainstorageapi.EthereumBlock, event *chainstorageapi.BlockchainEvent, partitionBySize uint64) error {
+func (t rawNativeStreamedTransactionsTable) transformRawStreamedTransactions(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.EthereumBlock, event *chainstorageapi.BlockchainEvent, filter *transactionFilter, partitioner *partition.Partitioner) error {
 	header := block.Header
 	if header == nil {
 		return xerrors.New("header is required")
 	}
 
 	transactions := block.GetTransactions()
-	if len(transactions) == 0 {
+	if len(transactions) == 0 || !filter.matchBlock(header) {
 		return nil
 	}
 
 	for _, transaction := range transactions {
+		if !filter.matchTransaction(transaction) {
+			continue
+		}
+
 		data, err := proto.Marshal(transaction)
 		if err != nil {
 			return xerrors.New("transaction failed to marshal into protobuf")
@@ -277,7 +294,7 @@ func (t rawNativeStreamedTransactionsTable) transformRawStreamedTransactions(rec
 			AppendUint64(transaction.BlockNumber).
 			AppendUint64(uint64(transaction.BlockTimestamp.GetSeconds())).
 			AppendBinary(data).
-			AppendUint64(partition.GetPartitionByNumber(uint64(event.GetSequenceNum()), partitionBySize)).
+			AppendUint64(partitioner.GetPartitionBy(header.Number, event.GetSequenceNum(), header.GetTimestamp().AsTime())).
 			AppendUint64(uint64(event.GetSequenceNum())).
 			Build()
 	}
@@ -285,6 +302,606 @@ func (t rawNativeStreamedTransactionsTable) transformRawStreamedTransactions(rec
 	return nil
 }
 
+func (t logsTable) transformLogs(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.EthereumBlock, filter *transactionFilter, partitioner *partition.Partitioner) error {
+	header := block.GetHeader()
+	if header == nil {
+		return xerrors.New("header is required")
+	}
+
+	if !filter.matchBlock(header) {
+		return nil
+	}
+
+	partitionBy := partitioner.GetPartitionBy(header.Number, 0, header.GetTimestamp().AsTime())
+	for _, transaction := range block.GetTransactions() {
+		if !filter.matchTransaction(transaction) {
+			continue
+		}
+
+		for _, log := range transaction.GetReceipt().GetLogs() {
+			if !filter.matchLog(log) {
+				continue
+			}
+
+			recordAppender := xarrow.NewRecordAppender(recordBuilder)
+			appendLog(recordAppender, header, transaction, log)
+			recordAppender.AppendUint64(partitionBy).
+				AppendUint64(header.Number).
+				Build()
+		}
+	}
+
+	return nil
+}
+
+func (t nativeStreamedLogsTable) transformStreamedLogs(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.EthereumBlock, event *chainstorageapi.BlockchainEvent, filter *transactionFilter, partitioner *partition.Partitioner) error {
+	header := block.GetHeader()
+	if header == nil {
+		return xerrors.New("header is required")
+	}
+
+	if !filter.matchBlock(header) {
+		return nil
+	}
+
+	partitionBy := partitioner.GetPartitionBy(header.Number, event.GetSequenceNum(), header.GetTimestamp().AsTime())
+	for _, transaction := range block.GetTransactions() {
+		if !filter.matchTransaction(transaction) {
+			continue
+		}
+
+		for _, log := range transaction.GetReceipt().GetLogs() {
+			if !filter.matchLog(log) {
+				continue
+			}
+
+			recordAppender := xarrow.NewRecordAppender(recordBuilder).
+				AppendInt64(event.GetSequenceNum()).
+				AppendString(event.GetType().String())
+			appendLog(recordAppender, header, transaction, log)
+			recordAppender.AppendUint64(partitionBy).
+				AppendUint64(uint64(event.GetSequenceNum())).
+				Build()
+		}
+	}
+
+	return nil
+}
+
+// appendLog appends the columns of the log, followed by its transaction and block context.
+func appendLog(ra *xarrow.RecordAppender, header *chainstorageapi.EthereumHeader, transaction *chainstorageapi.EthereumTransaction, log *chainstorageapi.EthereumEventLog) {
+	ra.AppendUint64(log.LogIndex).
+		AppendString(log.TransactionHash).
+		AppendUint64(log.TransactionIndex).
+		AppendString(log.BlockHash).
+		AppendUint64(log.BlockNumber).
+		AppendString(log.Address).
+		AppendString(log.Data).
+		AppendList(func(la *xarrow.ListAppender) {
+			for _, topic := range log.Topics {
+				la.AppendString(topic)
+			}
+		}).
+		AppendBool(log.Removed).
+		AppendUint64(uint64(header.Timestamp.GetSeconds())).
+		AppendString(transaction.From).
+		AppendString(transaction.To).
+		AppendUint64(transaction.GetReceipt().GetStatus())
+}
+
+func (t tracesTable) transformTraces(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.EthereumBlock, blockTraces []*chainstorageapi.EthereumTransactionFlattenedTrace, partitioner *partition.Partitioner) error {
+	header := block.GetHeader()
+	if header == nil {
+		return xerrors.New("header is required")
+	}
+
+	partitionBy := partitioner.GetPartitionBy(header.Number, 0, header.GetTimestamp().AsTime())
+	appendTraces(recordBuilder, block, blockTraces, func(ra *xarrow.RecordAppender) {}, partitionBy, header.Number)
+	return nil
+}
+
+func (t nativeStreamedTracesTable) transformStreamedTraces(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.EthereumBlock, blockTraces []*chainstorageapi.EthereumTransactionFlattenedTrace, event *chainstorageapi.BlockchainEvent, partitioner *partition.Partitioner) error {
+	header := block.GetHeader()
+	if header == nil {
+		return xerrors.New("header is required")
+	}
+
+	partitionBy := partitioner.GetPartitionBy(header.Number, event.GetSequenceNum(), header.GetTimestamp().AsTime())
+	appendTraces(recordBuilder, block, blockTraces, func(ra *xarrow.RecordAppender) {
+		ra.AppendInt64(event.GetSequenceNum()).
+			AppendString(event.GetType().String())
+	}, partitionBy, uint64(event.GetSequenceNum()))
+	return nil
+}
+
+// appendTraces appends one record per trace of the transactions, followed by the block-scoped traces (e.g. rewards),
+// starting with the columns appended by appendMetadata.
+// The block-scoped traces have no transaction hash and are not part of the call tree of any transaction.
+func appendTraces(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.EthereumBlock, blockTraces []*chainstorageapi.EthereumTransactionFlattenedTrace, appendMetadata func(ra *xarrow.RecordAppender), partitionBy uint64, repartitionByRange uint64) {
+	header := block.GetHeader()
+	appendTrace := func(trace *chainstorageapi.EthereumTransactionFlattenedTrace, failedCalls map[string]bool) {
+		var parentTraceAddress []uint64
+		if trace.TransactionHash != "" && len(trace.TraceAddress) > 0 {
+			parentTraceAddress = trace.TraceAddress[:len(trace.TraceAddress)-1]
+		}
+		isSuccess := isTraceSuccess(trace, failedCalls)
+
+		ra := xarrow.NewRecordAppender(recordBuilder)
+		appendMetadata(ra)
+		ra.AppendString(trace.TransactionHash).
+			AppendUint64(trace.TransactionIndex).
+			AppendString(trace.BlockHash).
+			AppendUint64(trace.BlockNumber).
+			AppendString(trace.From).
+			AppendString(trace.To)
+
+		value, err := xarrow.Decimal128FromString(trace.Value)
+		if err != nil {
+			ra.AppendDecimal128Null()
+		} else {
+			ra.AppendDecimal128(value)
+		}
+
+		ra.AppendString(trace.Value).
+			AppendString(trace.Input).
+			AppendString(trace.Output).
+			AppendString(trace.Type).
+			AppendString(trace.TraceType).
+			AppendString(trace.CallType).
+			AppendUint64(trace.Gas).
+			AppendUint64(trace.GasUsed).
+			AppendUint64(trace.Subtraces).
+			AppendList(func(la *xarrow.ListAppender) {
+				for _, v := range trace.TraceAddress {
+					la.AppendUint64(v)
+				}
+			}).
+			AppendString(trace.Error).
+			AppendUint64(trace.Status).
+			AppendString(trace.TraceId).
+			AppendUint64(uint64(header.Timestamp.GetSeconds())).
+			AppendList(func(la *xarrow.ListAppender) {
+				for _, v := range parentTraceAddress {
+					la.AppendUint64(v)
+				}
+			}).
+			AppendUint64(uint64(len(trace.TraceAddress))).
+			AppendBool(isSuccess).
+			AppendUint64(partitionBy).
+			AppendUint64(repartitionByRange).
+			Build()
+	}
+
+	for _, transaction := range block.GetTransactions() {
+		failedCalls := getFailedCalls(transaction)
+		for _, trace := range transaction.GetFlattenedTraces() {
+			appendTrace(trace, failedCalls)
+		}
+	}
+
+	for _, trace := range blockTraces {
+		appendTrace(trace, nil)
+	}
+}
+
+func (t tokenTransfersTable) transformTokenTransfers(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.EthereumBlock, filter *transactionFilter, partitioner *partition.Partitioner) error {
+	header := block.GetHeader()
+	if header == nil {
+		return xerrors.New("header is required")
+	}
+
+	if !filter.matchBlock(header) {
+		return nil
+	}
+
+	partitionBy := partitioner.GetPartitionBy(header.Number, 0, header.GetTimestamp().AsTime())
+	appendTokenTransfers(recordBuilder, block, filter, func(ra *xarrow.RecordAppender) {}, partitionBy, header.Number)
+	return nil
+}
+
+func (t nativeStreamedTokenTransfersTable) transformStreamedTokenTransfers(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.EthereumBlock, event *chainstorageapi.BlockchainEvent, filter *transactionFilter, partitioner *partition.Partitioner) error {
+	header := block.GetHeader()
+	if header == nil {
+		return xerrors.New("header is required")
+	}
+
+	if !filter.matchBlock(header) {
+		return nil
+	}
+
+	partitionBy := partitioner.GetPartitionBy(header.Number, event.GetSequenceNum(), header.GetTimestamp().AsTime())
+	appendTokenTransfers(recordBuilder, block, filter, func(ra *xarrow.RecordAppender) {
+		ra.AppendInt64(event.GetSequenceNum()).
+			AppendString(event.GetType().String())
+	}, partitionBy, uint64(event.GetSequenceNum()))
+	return nil
+}
+
+// appendTokenTransfers appends one record per token transfer decoded from the logs of the block,
+// starting with the columns appended by appendMetadata.
+func appendTokenTransfers(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.EthereumBlock, filter *transactionFilter, appendMetadata func(ra *xarrow.RecordAppender), partitionBy uint64, repartitionByRange uint64) {
+	header := block.GetHeader()
+	for _, transaction := range block.GetTransactions() {
+		if !filter.matchTransaction(transaction) {
+			continue
+		}
+
+		for _, log := range transaction.GetReceipt().GetLogs() {
+			if !filter.matchLog(log) {
+				continue
+			}
+
+			for i, transfer := range decodeTokenTransfers(log) {
+				if !filter.matchTokenTransfer(transfer) {
+					continue
+				}
+
+				ra := xarrow.NewRecordAppender(recordBuilder)
+				appendMetadata(ra)
+				ra.AppendString(strings.ToLower(log.Address)).
+					AppendString(string(transfer.standard)).
+					AppendString(transfer.operator).
+					AppendString(transfer.from).
+					AppendString(transfer.to).
+					AppendString(transfer.tokenID)
+
+				amount, err := xarrow.Decimal128FromString(transfer.amount)
+				if err != nil {
+					ra.AppendDecimal128Null()
+				} else {
+					ra.AppendDecimal128(amount)
+				}
+
+				ra.AppendString(transfer.amount).
+					AppendUint64(uint64(i)).
+					AppendUint64(log.LogIndex).
+					AppendString(log.TransactionHash).
+					AppendUint64(log.TransactionIndex).
+					AppendString(log.BlockHash).
+					AppendUint64(log.BlockNumber).
+					AppendUint64(uint64(header.Timestamp.GetSeconds())).
+					AppendUint64(partitionBy).
+					AppendUint64(repartitionByRange).
+					Build()
+			}
+		}
+	}
+}
+
+func (t decodedLogsTable) transformDecodedLogs(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.EthereumBlock, filter *transactionFilter, partitioner *partition.Partitioner) error {
+	header := block.GetHeader()
+	if header == nil {
+		return xerrors.New("header is required")
+	}
+
+	if !filter.matchBlock(header) {
+		return nil
+	}
+
+	partitionBy := partitioner.GetPartitionBy(header.Number, 0, header.GetTimestamp().AsTime())
+	for _, transaction := range block.GetTransactions() {
+		if !filter.matchTransaction(transaction) {
+			continue
+		}
+
+		for _, log := range transaction.GetReceipt().GetLogs() {
+			if !filter.matchLog(log) {
+				continue
+			}
+
+			ra := xarrow.NewRecordAppender(recordBuilder).
+				AppendUint64(log.LogIndex).
+				AppendString(log.TransactionHash).
+				AppendUint64(log.TransactionIndex).
+				AppendString(log.BlockHash).
+				AppendUint64(log.BlockNumber).
+				AppendString(log.Address).
+				AppendString(log.Data).
+				AppendList(func(la *xarrow.ListAppender) {
+					for _, topic := range log.Topics {
+						la.AppendString(topic)
+					}
+				}).
+				AppendBool(log.Removed).
+				AppendUint64(uint64(header.Timestamp.GetSeconds()))
+			appendDecoded(ra, t.registry.DecodeLog(log.Topics, log.Data))
+			ra.AppendUint64(partitionBy).
+				AppendUint64(header.Number).
+				Build()
+		}
+	}
+
+	return nil
+}
+
+func (t decodedCallsTable) transformDecodedCalls(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.EthereumBlock, filter *transactionFilter, partitioner *partition.Partitioner) error {
+	header := block.GetHeader()
+	if header == nil {
+		return xerrors.New("header is required")
+	}
+
+	if !filter.matchBlock(header) {
+		return nil
+	}
+
+	partitionBy := partitioner.GetPartitionBy(header.Number, 0, header.GetTimestamp().AsTime())
+	appendCall := func(transaction *chainstorageapi.EthereumTransaction, traceAddress []uint64, callType string, from string, to string, input string) {
+		if len(input) < len("0x")+2*abi.SelectorLength {
+			// Plain transfers of ether do not call any function.
+			return
+		}
+
+		ra := xarrow.NewRecordAppender(recordBuilder).
+			AppendString(transaction.Hash).
+			AppendUint64(transaction.Index).
+			AppendString(header.Hash).
+			AppendUint64(header.Number).
+			AppendUint64(uint64(header.Timestamp.GetSeconds())).
+			AppendList(func(la *xarrow.ListAppender) {
+				for _, v := range traceAddress {
+					la.AppendUint64(v)
+				}
+			}).
+			AppendString(callType).
+			AppendString(from).
+			AppendString(to).
+			AppendString(strings.ToLower(input[:len("0x")+2*abi.SelectorLength]))
+		appendDecoded(ra, t.registry.DecodeCall(input))
+		ra.AppendUint64(partitionBy).
+			AppendUint64(header.Number).
+			Build()
+	}
+
+	for _, transaction := range block.GetTransactions() {
+		if !filter.matchTransaction(transaction) {
+			continue
+		}
+
+		// The input of the transaction is decoded from its root trace when the root trace is a call,
+		// or from the transaction itself otherwise, e.g. when there is no trace.
+		traces := transaction.GetFlattenedTraces()
+		if !hasRootCallTrace(traces) {
+			appendCall(transaction, nil, "", transaction.From, transaction.To, transaction.Input)
+		}
+
+		for _, trace := range traces {
+			if isCallTrace(trace) {
+				appendCall(transaction, trace.TraceAddress, strings.ToLower(trace.CallType), trace.From, trace.To, trace.Input)
+			}
+		}
+	}
+
+	return nil
+}
+
+// appendDecoded appends the name, signature and args columns, which are null if the log or call could not be decoded.
+func appendDecoded(ra *xarrow.RecordAppender, decoded *abi.Decoded) {
+	if decoded == nil {
+		ra.AppendNull().
+			AppendNull().
+			AppendNull()
+		return
+	}
+
+	ra.AppendString(decoded.Name).
+		AppendString(decoded.Signature).
+		AppendString(decoded.Args)
+}
+
+func (t contractsTable) transformContracts(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.EthereumBlock, partitioner *partition.Partitioner) error {
+	header := block.GetHeader()
+	if header == nil {
+		return xerrors.New("header is required")
+	}
+
+	partitionBy := partitioner.GetPartitionBy(header.Number, 0, header.GetTimestamp().AsTime())
+	appendContract := func(transaction *chainstorageapi.EthereumTransaction, address string, deployer string, traceAddress []uint64, appendBytecode func(ra *xarrow.RecordAppender)) {
+		ra := xarrow.NewRecordAppender(recordBuilder).
+			AppendString(address).
+			AppendString(deployer).
+			AppendString(transaction.Hash).
+			AppendUint64(transaction.Index).
+			AppendList(func(la *xarrow.ListAppender) {
+				for _, v := range traceAddress {
+					la.AppendUint64(v)
+				}
+			}).
+			AppendString(header.Hash).
+			AppendUint64(header.Number).
+			AppendUint64(uint64(header.Timestamp.GetSeconds()))
+		appendBytecode(ra)
+		ra.AppendBool(len(traceAddress) > 0).
+			AppendUint64(partitionBy).
+			AppendUint64(header.Number).
+			Build()
+	}
+
+	for _, transaction := range block.GetTransactions() {
+		traces := transaction.GetFlattenedTraces()
+		if len(traces) == 0 {
+			// Without the traces, only the contracts created by the transactions themselves are known,
+			// and their deployed bytecode is not.
+			receipt := transaction.GetReceipt()
+			if receipt.GetContractAddress() != "" && receipt.GetStatus() == 1 {
+				appendContract(transaction, receipt.ContractAddress, transaction.From, nil, func(ra *xarrow.RecordAppender) {
+					ra.AppendNull()
+				})
+			}
+			continue
+		}
+
+		failedCalls := getFailedCalls(transaction)
+		for _, trace := range traces {
+			if !isCreateTrace(trace) || trace.To == "" || !isTraceSuccess(trace, failedCalls) {
+				continue
+			}
+
+			appendContract(transaction, trace.To, trace.From, trace.TraceAddress, func(ra *xarrow.RecordAppender) {
+				ra.AppendString(trace.Output)
+			})
+		}
+	}
+
+	return nil
+}
+
+func (t withdrawalsTable) transformWithdrawals(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.EthereumBlock, partitioner *partition.Partitioner) error {
+	header := block.GetHeader()
+	if header == nil {
+		return xerrors.New("header is required")
+	}
+
+	partitionBy := partitioner.GetPartitionBy(header.Number, 0, header.GetTimestamp().AsTime())
+	for _, withdrawal := range header.Withdrawals {
+		ra := xarrow.NewRecordAppender(recordBuilder)
+		appendWithdrawal(ra, header, withdrawal)
+		ra.AppendUint64(partitionBy).
+			AppendUint64(header.Number).
+			Build()
+	}
+
+	return nil
+}
+
+func (t nativeStreamedWithdrawalsTable) transformStreamedWithdrawals(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.EthereumBlock, event *chainstorageapi.BlockchainEvent, partitioner *partition.Partitioner) error {
+	header := block.GetHeader()
+	if header == nil {
+		return xerrors.New("header is required")
+	}
+
+	partitionBy := partitioner.GetPartitionBy(header.Number, event.GetSequenceNum(), header.GetTimestamp().AsTime())
+	for _, withdrawal := range header.Withdrawals {
+		ra := xarrow.NewRecordAppender(recordBuilder).
+			AppendInt64(event.GetSequenceNum()).
+			AppendString(event.GetType().String())
+		appendWithdrawal(ra, header, withdrawal)
+		ra.AppendUint64(partitionBy).
+			AppendUint64(uint64(event.GetSequenceNum())).
+			Build()
+	}
+
+	return nil
+}
+
+// appendWithdrawal appends the columns of the withdrawal, followed by its block context.
+func appendWithdrawal(ra *xarrow.RecordAppender, header *chainstorageapi.EthereumHeader, withdrawal *chainstorageapi.EthereumWithdrawal) {
+	ra.AppendUint64(withdrawal.Index).
+		AppendUint64(withdrawal.ValidatorIndex).
+		AppendString(withdrawal.Address).
+		AppendUint64(withdrawal.Amount).
+		AppendDecimal128(decimal128.FromBigInt(new(big.Int).Mul(new(big.Int).SetUint64(withdrawal.Amount), big.NewInt(weiPerGwei)))).
+		AppendString(header.Hash).
+		AppendUint64(header.Number).
+		AppendUint64(uint64(header.Timestamp.GetSeconds()))
+}
+
+func (t uncleBlocksTable) transformUncleBlocks(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.EthereumBlock, partitioner *partition.Partitioner) error {
+	header := block.GetHeader()
+	if header == nil {
+		return xerrors.New("header is required")
+	}
+
+	partitionBy := partitioner.GetPartitionBy(header.Number, 0, header.GetTimestamp().AsTime())
+	for i, uncle := range block.GetUncles() {
+		ra := xarrow.NewRecordAppender(recordBuilder)
+		appendUncleBlock(ra, header, uncle, i)
+		ra.AppendUint64(partitionBy).
+			AppendUint64(header.Number).
+			Build()
+	}
+
+	return nil
+}
+
+func (t nativeStreamedUncleBlocksTable) transformStreamedUncleBlocks(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.EthereumBlock, event *chainstorageapi.BlockchainEvent, partitioner *partition.Partitioner) error {
+	header := block.GetHeader()
+	if header == nil {
+		return xerrors.New("header is required")
+	}
+
+	partitionBy := partitioner.GetPartitionBy(header.Number, event.GetSequenceNum(), header.GetTimestamp().AsTime())
+	for i, uncle := range block.GetUncles() {
+		ra := xarrow.NewRecordAppender(recordBuilder).
+			AppendInt64(event.GetSequenceNum()).
+			AppendString(event.GetType().String())
+		appendUncleBlock(ra, header, uncle, i)
+		ra.AppendUint64(partitionBy).
+			AppendUint64(uint64(event.GetSequenceNum())).
+			Build()
+	}
+
+	return nil
+}
+
+// appendUncleBlock appends the columns of the uncle, followed by the context of the block including it.
+func appendUncleBlock(ra *xarrow.RecordAppender, header *chainstorageapi.EthereumHeader, uncle *chainstorageapi.EthereumHeader, index int) {
+	ra.AppendString(uncle.Hash).
+		AppendString(uncle.ParentHash).
+		AppendUint64(uncle.Number).
+		AppendUint64(uint64(uncle.Timestamp.GetSeconds())).
+		AppendString(uncle.Miner).
+		AppendUint64(uncle.Difficulty).
+		AppendUint64(uncle.GasLimit).
+		AppendUint64(uncle.GasUsed).
+		AppendUint64(uncle.GetBaseFeePerGas()).
+		AppendUint64(uint64(index)).
+		AppendString(header.Hash).
+		AppendUint64(header.Number).
+		AppendUint64(uint64(header.Timestamp.GetSeconds()))
+}
+
+// getFailedCalls returns the trace addresses of the failed calls of the transaction, keyed by traceAddressKey.
+func getFailedCalls(transaction *chainstorageapi.EthereumTransaction) map[string]bool {
+	failedCalls := make(map[string]bool)
+	for _, trace := range transaction.GetFlattenedTraces() {
+		if trace.TransactionHash != "" && trace.Error != "" {
+			failedCalls[traceAddressKey(trace.TraceAddress)] = true
+		}
+	}
+
+	return failedCalls
+}
+
+// isTraceSuccess returns whether neither the call nor any of its parent calls failed.
+// The effects of a call are reverted when any of its parent calls fails.
+func isTraceSuccess(trace *chainstorageapi.EthereumTransactionFlattenedTrace, failedCalls map[string]bool) bool {
+	isSuccess := trace.Error == ""
+	if trace.TransactionHash != "" {
+		for i := 0; i < len(trace.TraceAddress) && isSuccess; i++ {
+			isSuccess = !failedCalls[traceAddressKey(trace.TraceAddress[:i])]
+		}
+	}
+
+	return isSuccess
+}
+
+// isCallTrace returns whether the trace is a call, including the delegate and static calls.
+// The parity traces use the lowercase trace types, while ChainStorage rewrites the geth call traces to CALL.
+func isCallTrace(trace *chainstorageapi.EthereumTransactionFlattenedTrace) bool {
+	return strings.EqualFold(trace.TraceType, traceTypeCall)
+}
+
+// hasRootCallTrace returns whether the root trace of the transaction is a call.
+func hasRootCallTrace(traces []*chainstorageapi.EthereumTransactionFlattenedTrace) bool {
+	for _, trace := range traces {
+		if len(trace.TraceAddress) == 0 {
+			return isCallTrace(trace)
+		}
+	}
+
+	return false
+}
+
+// isCreateTrace returns whether the trace creates a contract.
+// The parity traces use the lowercase trace types, while the geth traces use the uppercase ones, including CREATE2.
+func isCreateTrace(trace *chainstorageapi.EthereumTransactionFlattenedTrace) bool {
+	return strings.EqualFold(trace.TraceType, traceTypeCreate) || strings.EqualFold(trace.TraceType, traceTypeCreate2)
+}
+
+func traceAddressKey(traceAddress []uint64) string {
+	return fmt.Sprint(traceAddress)
+}
+
 func TransformBlock(sa *xarrow.StructAppender, header *chainstorageapi.EthereumHeader) {
 	sa.AppendString(header.Hash).
 		AppendString(header.ParentHash).
@@ -0,0 +1,82 @@
+package tables
+
+import (
+	"context"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coinbase/chainstorage/protos/coinbase/c3/common"
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+	"github.com/coinbase/chainstorage/sdk"
+
+	"github.com/coinbase/chainsformer/internal/controller/internal"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+type (
+	uncleBlocksTable               struct{}
+	nativeStreamedUncleBlocksTable struct{}
+)
+
+// NewUncleBlocksTables returns the uncle_blocks and streamed_uncle_blocks tables,
+// or none if the network has never had uncle blocks, i.e. it has never been mined with proof of work.
+// On the ethereum mainnet, the uncles are only populated below the merge, i.e. the heights [0, 15537394).
+func NewUncleBlocksTables(params internal.CommonTableParams) []internal.Table {
+	cfg := params.Params.Config
+	if cfg.Blockchain() != common.Blockchain_BLOCKCHAIN_ETHEREUM || cfg.Network() != common.Network_NETWORK_ETHEREUM_MAINNET {
+		return nil
+	}
+
+	return []internal.Table{
+		internal.NewBatchTable(
+			&params,
+			internal.NewTableAttributes(internal.TableNameUncleBlocks),
+			newUncleBlockSchema(),
+			uncleBlocksTable{},
+		),
+		internal.NewStreamTable(
+			&params,
+			internal.NewTableAttributes(internal.TableNameStreamedUncleBlocks),
+			newStreamedUncleBlockSchema(),
+			nativeStreamedUncleBlocksTable{},
+			params.Params.Config.Table.StreamTable,
+		),
+	}
+}
+
+func (t uncleBlocksTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
+	nativeBlock, err := parser.ParseNativeBlock(ctx, block)
+	if err != nil {
+		return xerrors.Errorf("failed to parse raw block to native block: %w", err)
+	}
+
+	ethereumBlock := nativeBlock.GetEthereum()
+	if ethereumBlock == nil {
+		return xerrors.New("failed to extract ethereum block from native block")
+	}
+
+	if err := t.transformUncleBlocks(recordBuilder, ethereumBlock, partitioner); err != nil {
+		return xerrors.Errorf("failed to transform uncle blocks: %w", err)
+	}
+
+	return nil
+}
+
+func (t nativeStreamedUncleBlocksTable) TransformBlock(ctx context.Context, blockAndEvent *internal.BlockAndEvent, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
+	nativeBlock, err := parser.ParseNativeBlock(ctx, blockAndEvent.Block)
+	if err != nil {
+		return xerrors.Errorf("failed to parse raw block to native block: %w", err)
+	}
+
+	ethereumBlock := nativeBlock.GetEthereum()
+	if ethereumBlock == nil {
+		return xerrors.New("failed to extract ethereum block from native block")
+	}
+
+	if err := t.transformStreamedUncleBlocks(recordBuilder, ethereumBlock, blockAndEvent.BlockChainEvent, partitioner); err != nil {
+		return xerrors.Errorf("failed to transform uncle blocks: %w", err)
+	}
+
+	return nil
+}
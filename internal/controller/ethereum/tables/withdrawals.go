@@ -0,0 +1,92 @@
+package tables
+
+import (
+	"context"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coinbase/chainstorage/protos/coinbase/c3/common"
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+	"github.com/coinbase/chainstorage/sdk"
+
+	"github.com/coinbase/chainsformer/internal/controller/internal"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+type (
+	withdrawalsTable               struct{}
+	nativeStreamedWithdrawalsTable struct{}
+)
+
+const (
+	weiPerGwei = 1_000_000_000
+)
+
+// NewWithdrawalsTables returns the withdrawals and streamed_withdrawals tables,
+// or none if the blocks of the network do not carry the withdrawals of the beacon chain.
+// The withdrawals are only populated since the shanghai upgrade, e.g. from the height 17034870 of the ethereum mainnet.
+func NewWithdrawalsTables(params internal.CommonTableParams) []internal.Table {
+	cfg := params.Params.Config
+	if cfg.Blockchain() != common.Blockchain_BLOCKCHAIN_ETHEREUM {
+		return nil
+	}
+
+	switch cfg.Network() {
+	case common.Network_NETWORK_ETHEREUM_MAINNET, common.Network_NETWORK_ETHEREUM_GOERLI, common.Network_NETWORK_ETHEREUM_HOLESKY:
+	default:
+		return nil
+	}
+
+	return []internal.Table{
+		internal.NewBatchTable(
+			&params,
+			internal.NewTableAttributes(internal.TableNameWithdrawals),
+			newWithdrawalSchema(),
+			withdrawalsTable{},
+		),
+		internal.NewStreamTable(
+			&params,
+			internal.NewTableAttributes(internal.TableNameStreamedWithdrawals),
+			newStreamedWithdrawalSchema(),
+			nativeStreamedWithdrawalsTable{},
+			params.Params.Config.Table.StreamTable,
+		),
+	}
+}
+
+func (t withdrawalsTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
+	nativeBlock, err := parser.ParseNativeBlock(ctx, block)
+	if err != nil {
+		return xerrors.Errorf("failed to parse raw block to native block: %w", err)
+	}
+
+	ethereumBlock := nativeBlock.GetEthereum()
+	if ethereumBlock == nil {
+		return xerrors.New("failed to extract ethereum block from native block")
+	}
+
+	if err := t.transformWithdrawals(recordBuilder, ethereumBlock, partitioner); err != nil {
+		return xerrors.Errorf("failed to transform withdrawals: %w", err)
+	}
+
+	return nil
+}
+
+func (t nativeStreamedWithdrawalsTable) TransformBlock(ctx context.Context, blockAndEvent *internal.BlockAndEvent, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
+	nativeBlock, err := parser.ParseNativeBlock(ctx, blockAndEvent.Block)
+	if err != nil {
+		return xerrors.Errorf("failed to parse raw block to native block: %w", err)
+	}
+
+	ethereumBlock := nativeBlock.GetEthereum()
+	if ethereumBlock == nil {
+		return xerrors.New("failed to extract ethereum block from native block")
+	}
+
+	if err := t.transformStreamedWithdrawals(recordBuilder, ethereumBlock, blockAndEvent.BlockChainEvent, partitioner); err != nil {
+		return xerrors.Errorf("failed to transform withdrawals: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v10/arrow/array"
+	"github.com/apache/arrow/go/v10/arrow/memory"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally/v4"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/coinbase/chainstorage/protos/coinbase/c3/common"
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+
+	"github.com/coinbase/chainsformer/internal/config"
+	"github.com/coinbase/chainsformer/internal/controller/internal"
+	"github.com/coinbase/chainsformer/internal/utils/fxparams"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+func TestTransformWithdrawals(t *testing.T) {
+	require := require.New(t)
+
+	block := &chainstorageapi.EthereumBlock{
+		Header: &chainstorageapi.EthereumHeader{
+			Hash:      "0xabc",
+			Number:    100,
+			Timestamp: &timestamppb.Timestamp{Seconds: 1700000000},
+			Withdrawals: []*chainstorageapi.EthereumWithdrawal{
+				{Index: 1, ValidatorIndex: 10, Address: testEOA, Amount: 1},
+				{Index: 2, ValidatorIndex: 20, Address: testEOA, Amount: 32_000_000_000},
+			},
+		},
+	}
+
+	schema := newWithdrawalSchema()
+	recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), schema, nil)
+	defer recordBuilder.Release()
+
+	require.NoError(withdrawalsTable{}.transformWithdrawals(recordBuilder, block, partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+	rec := recordBuilder.NewRecord()
+	defer rec.Release()
+	require.Equal(int64(2), rec.NumRows())
+
+	amountWei := rec.Column(schema.FieldIndices("amount_wei")[0]).(*array.Decimal128)
+	require.Equal("1000000000", amountWei.Value(0).BigInt().String())
+	require.Equal("32000000000000000000", amountWei.Value(1).BigInt().String())
+	require.Equal(uint64(20), rec.Column(schema.FieldIndices("validator_index")[0]).(*array.Uint64).Value(1))
+	require.Equal("0xabc", rec.Column(schema.FieldIndices("block_hash")[0]).(*array.String).Value(1))
+}
+
+func TestTransformUncleBlocks(t *testing.T) {
+	require := require.New(t)
+
+	block := &chainstorageapi.EthereumBlock{
+		Header: &chainstorageapi.EthereumHeader{
+			Hash:      "0xabc",
+			Number:    100,
+			Timestamp: &timestamppb.Timestamp{Seconds: 1700000000},
+		},
+		Uncles: []*chainstorageapi.EthereumHeader{
+			{Hash: "0xu1", Number: 98, Miner: testEOA},
+			{Hash: "0xu2", Number: 99, Miner: testEOA},
+		},
+	}
+
+	schema := newUncleBlockSchema()
+	recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), schema, nil)
+	defer recordBuilder.Release()
+
+	require.NoError(uncleBlocksTable{}.transformUncleBlocks(recordBuilder, block, partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+	rec := recordBuilder.NewRecord()
+	defer rec.Release()
+	require.Equal(int64(2), rec.NumRows())
+	for i, hash := range []string{"0xu1", "0xu2"} {
+		require.Equal(hash, rec.Column(schema.FieldIndices("hash")[0]).(*array.String).Value(i))
+		require.Equal(uint64(i), rec.Column(schema.FieldIndices("uncle_index")[0]).(*array.Uint64).Value(i))
+		require.Equal(uint64(100), rec.Column(schema.FieldIndices("nephew_block_number")[0]).(*array.Uint64).Value(i))
+	}
+}
+
+func TestNewWithdrawalsAndUncleBlocksTables(t *testing.T) {
+	tests := []struct {
+		blockchain          common.Blockchain
+		network             common.Network
+		expectedWithdrawals int
+		expectedUncleBlocks int
+	}{
+		{blockchain: common.Blockchain_BLOCKCHAIN_ETHEREUM, network: common.Network_NETWORK_ETHEREUM_MAINNET, expectedWithdrawals: 2, expectedUncleBlocks: 2},
+		// Goerli has never been mined with proof of work.
+		{blockchain: common.Blockchain_BLOCKCHAIN_ETHEREUM, network: common.Network_NETWORK_ETHEREUM_GOERLI, expectedWithdrawals: 2, expectedUncleBlocks: 0},
+		{blockchain: common.Blockchain_BLOCKCHAIN_POLYGON, network: common.Network_NETWORK_POLYGON_MAINNET, expectedWithdrawals: 0, expectedUncleBlocks: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.network.String(), func(t *testing.T) {
+			require := require.New(t)
+
+			fxParams := fxparams.Params{
+				Config:  &config.Config{Chain: config.ChainConfig{Blockchain: test.blockchain, Network: test.network}},
+				Logger:  zap.NewNop(),
+				Metrics: tally.NoopScope,
+			}
+			sampler, err := internal.NewBlockSampler(internal.BlockSamplerParams{Params: fxParams})
+			require.NoError(err)
+			params := internal.CommonTableParams{
+				Params:       fxParams,
+				BlockSampler: sampler,
+			}
+			require.Len(NewWithdrawalsTables(params), test.expectedWithdrawals)
+			require.Len(NewUncleBlocksTables(params), test.expectedUncleBlocks)
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+	"sort"
+
+	"github.com/apache/arrow/go/v10/arrow/flight"
+	"golang.org/x/xerrors"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+
+	"github.com/coinbase/chainsformer/internal/errors"
+)
+
+type (
+	chainMetadataResult struct {
+		Blockchain           string `json:"blockchain"`
+		Network              string `json:"network"`
+		BlockStartHeight     uint64 `json:"block_start_height"`
+		IrreversibleDistance uint64 `json:"irreversible_distance"`
+		BlockTime            string `json:"block_time"`
+		LatestBlockTag       uint32 `json:"latest_block_tag"`
+		StableBlockTag       uint32 `json:"stable_block_tag"`
+		LatestEventTag       uint32 `json:"latest_event_tag"`
+		StableEventTag       uint32 `json:"stable_event_tag"`
+	}
+
+	sequenceToHeightRequest struct {
+		Sequence int64 `json:"sequence"`
+	}
+
+	heightToSequenceRequest struct {
+		Height uint64 `json:"height"`
+	}
+
+	eventResult struct {
+		Sequence  int64  `json:"sequence"`
+		Type      string `json:"type"`
+		EventTag  uint32 `json:"event_tag"`
+		Height    uint64 `json:"height"`
+		Hash      string `json:"hash"`
+		Tag       uint32 `json:"tag"`
+		Skipped   bool   `json:"skipped"`
+		Timestamp int64  `json:"timestamp"`
+	}
+
+	versionResult struct {
+		ServerVersion string `json:"server_version"`
+		SchemaVersion string `json:"schema_version"`
+	}
+)
+
+const (
+	flightActionChainMetadata    = "CHAIN_METADATA"
+	flightActionSequenceToHeight = "SEQUENCE_TO_HEIGHT"
+	flightActionHeightToSequence = "HEIGHT_TO_SEQUENCE"
+	flightActionVersion          = "VERSION"
+
+	// schemaVersionLength is the number of bytes of the schema fingerprint used as the schema version.
+	schemaVersionLength = 8
+
+	unknownServerVersion = "unknown"
+)
+
+// flightActions is the catalog of actions returned by ListActions.
+var flightActions = []*flight.ActionType{
+	{
+		Type:        flightActionTip,
+		Description: "Returns the latest irreversible block height. The body is ignored.",
+	},
+	{
+		Type:        flightActionEarliest,
+		Description: "Returns the earliest block height. The body is ignored.",
+	},
+	{
+		Type:        flightStreamActionTip,
+		Description: "Returns the latest event sequence. The body is ignored.",
+	},
+	{
+		Type:        flightStreamActionEarliest,
+		Description: "Returns the earliest event sequence. The body is ignored.",
+	},
+	{
+		Type:        flightActionHeightAtTime,
+		Description: "Returns the height of the first block whose timestamp is not before the time in the body, in RFC 3339.",
+	},
+	{
+		Type:        flightActionChainMetadata,
+		Description: `Returns the chain metadata in JSON, e.g. {"block_start_height":0,"irreversible_distance":12,...}. The body is ignored.`,
+	},
+	{
+		Type:        flightActionSequenceToHeight,
+		Description: `Takes {"sequence":<int64>} and returns the event with the sequence in JSON, e.g. {"sequence":1,"type":"BLOCK_ADDED","height":0,"hash":"0x...",...}.`,
+	},
+	{
+		Type:        flightActionHeightToSequence,
+		Description: `Takes {"height":<uint64>} and returns the first event of the block at the height in JSON, e.g. {"sequence":1,"type":"BLOCK_ADDED","height":0,"hash":"0x...",...}.`,
+	},
+	{
+		Type:        flightActionVersion,
+		Description: `Returns the server and schema versions in JSON, e.g. {"server_version":"...","schema_version":"9f86d081884c7d65"}. The schema version changes whenever a table schema changes. The body is ignored.`,
+	},
+}
+
+func (h *handler) ListActions(in *flight.Empty, fs flight.FlightService_ListActionsServer) error {
+	for _, action := range flightActions {
+		if err := fs.Send(action); err != nil {
+			return xerrors.Errorf("failed to send action type: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (h *handler) doChainMetadataAction(fs flight.FlightService_DoActionServer) error {
+	meta, err := h.csSession.GetStaticChainMetadata(fs.Context(), &chainstorageapi.GetChainMetadataRequest{})
+	if err != nil {
+		return xerrors.Errorf("failed to get chain metadata: %w", err)
+	}
+
+	return sendJSONResult(fs, &chainMetadataResult{
+		Blockchain:           h.config.Blockchain().GetName(),
+		Network:              h.config.Network().GetName(),
+		BlockStartHeight:     meta.GetBlockStartHeight(),
+		IrreversibleDistance: meta.GetIrreversibleDistance(),
+		BlockTime:            meta.GetBlockTime(),
+		LatestBlockTag:       meta.GetLatestBlockTag(),
+		StableBlockTag:       meta.GetStableBlockTag(),
+		LatestEventTag:       meta.GetLatestEventTag(),
+		StableEventTag:       meta.GetStableEventTag(),
+	})
+}
+
+func (h *handler) doSequenceToHeightAction(action *flight.Action, fs flight.FlightService_DoActionServer) error {
+	var req sequenceToHeightRequest
+	if err := json.Unmarshal(action.Body, &req); err != nil {
+		return xerrors.Errorf("failed to decode body: %v: %w", err, errors.ErrInvalidArgument)
+	}
+
+	event, err := h.csSession.GetEventBySequence(fs.Context(), req.Sequence)
+	if err != nil {
+		return xerrors.Errorf("failed to get event by sequence(%d): %w", req.Sequence, err)
+	}
+
+	return sendJSONResult(fs, newEventResult(event))
+}
+
+func (h *handler) doHeightToSequenceAction(action *flight.Action, fs flight.FlightService_DoActionServer) error {
+	var req heightToSequenceRequest
+	if err := json.Unmarshal(action.Body, &req); err != nil {
+		return xerrors.Errorf("failed to decode body: %v: %w", err, errors.ErrInvalidArgument)
+	}
+
+	event, err := h.csSession.GetFirstEventByHeight(fs.Context(), req.Height)
+	if err != nil {
+		return xerrors.Errorf("failed to get first event by height(%d): %w", req.Height, err)
+	}
+
+	return sendJSONResult(fs, newEventResult(event))
+}
+
+func (h *handler) doVersionAction(fs flight.FlightService_DoActionServer) error {
+	return sendJSONResult(fs, &versionResult{
+		ServerVersion: getServerVersion(),
+		SchemaVersion: h.schemaVersion,
+	})
+}
+
+// getSchemaVersion returns the fingerprint of the serialized schemas by table name,
+// which changes whenever a table is added, removed or has its schema changed.
+func getSchemaVersion(serializedSchemas map[string][]byte) string {
+	tableNames := make([]string, 0, len(serializedSchemas))
+	for tableName := range serializedSchemas {
+		tableNames = append(tableNames, tableName)
+	}
+	sort.Strings(tableNames)
+
+	hasher := sha256.New()
+	for _, tableName := range tableNames {
+		schema := serializedSchemas[tableName]
+		// The lengths delimit the table names and schemas.
+		fmt.Fprintf(hasher, "%d:%s%d:", len(tableName), tableName, len(schema))
+		hasher.Write(schema)
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)[:schemaVersionLength])
+}
+
+func newEventResult(event *chainstorageapi.BlockchainEvent) *eventResult {
+	block := event.GetBlock()
+	return &eventResult{
+		Sequence:  event.GetSequenceNum(),
+		Type:      event.GetType().String(),
+		EventTag:  event.GetEventTag(),
+		Height:    block.GetHeight(),
+		Hash:      block.GetHash(),
+		Tag:       block.GetTag(),
+		Skipped:   block.GetSkipped(),
+		Timestamp: block.GetTimestamp().GetSeconds(),
+	}
+}
+
+func sendJSONResult(fs flight.FlightService_DoActionServer, result interface{}) error {
+	body, err := json.Marshal(result)
+	if err != nil {
+		return xerrors.Errorf("failed to encode result: %w", err)
+	}
+
+	if err := fs.Send(&flight.Result{Body: body}); err != nil {
+		return xerrors.Errorf("failed to send result: %w", err)
+	}
+
+	return nil
+}
+
+// getServerVersion returns the version of the main module, or the vcs revision it was built from.
+func getServerVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return unknownServerVersion
+	}
+
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			return setting.Value
+		}
+	}
+
+	if version := info.Main.Version; version != "" && version != "(devel)" {
+		return version
+	}
+
+	return unknownServerVersion
+}
@@ -3,40 +3,60 @@ package internal
 import (
 	"context"
 	"math"
+	"time"
 
 	"github.com/apache/arrow/go/v10/arrow"
-	"github.com/apache/arrow/go/v10/arrow/array"
 	"github.com/apache/arrow/go/v10/arrow/flight"
 	"github.com/golang/protobuf/proto"
+	"go.uber.org/zap"
 	"golang.org/x/xerrors"
 
 	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
 	"github.com/coinbase/chainstorage/sdk"
 
 	"github.com/coinbase/chainsformer/internal/chainstorage"
+	"github.com/coinbase/chainsformer/internal/config"
+	"github.com/coinbase/chainsformer/internal/controller/internal/constant"
 	"github.com/coinbase/chainsformer/internal/errors"
+	"github.com/coinbase/chainsformer/internal/utils/log"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
 	"github.com/coinbase/chainsformer/internal/utils/protoutil"
+	"github.com/coinbase/chainsformer/internal/utils/syncgroup"
 	"github.com/coinbase/chainsformer/internal/utils/xarrow"
 	api "github.com/coinbase/chainsformer/protos/coinbase/chainsformer"
 )
 
 type (
 	BatchTransformer interface {
-		TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *array.RecordBuilder, partitionBySize uint64) error
+		TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter Filter, partitioner *partition.Partitioner) error
 	}
 
 	BatchTable struct {
 		*baseTable
 		session     chainstorage.Session
 		transformer BatchTransformer
+		estimator   *sizeEstimator
+		config      *config.BatchTableConfig
+		logger      *zap.Logger
+	}
+
+	// blocksFetch is the result of fetching the blocks of a chunk.
+	// The blocks are only set once done is closed.
+	blocksFetch struct {
+		blocks []*chainstorageapi.Block
+		done   chan struct{}
 	}
 )
 
 func NewBatchTable(commonParams *CommonTableParams, attributes *TableAttributes, schema *arrow.Schema, transformer BatchTransformer) *BatchTable {
+	baseTable := newBaseTable(commonParams, attributes, schema)
 	return &BatchTable{
-		baseTable:   newBaseTable(commonParams, attributes, schema),
+		baseTable:   baseTable,
 		session:     commonParams.Session,
 		transformer: transformer,
+		estimator:   newSizeEstimator(commonParams.BlockSampler, baseTable.GetTableName(), attributes.EstimateMode, schema, transformer),
+		config:      &commonParams.Config.Table.BatchTable,
+		logger:      log.WithPackageName(commonParams.Logger, packageName),
 	}
 }
 
@@ -46,8 +66,19 @@ func (t *BatchTable) parseGetEndpointsParams(ctx context.Context, cmd *api.GetFl
 		return 0, 0, 0, xerrors.Errorf("batchQuery is not provided: %w", errors.ErrInvalidArgument)
 	}
 
-	startHeight := batchQuery.GetStartHeight()
-	endHeight := batchQuery.GetEndHeight()
+	if _, err := parseFilter(t.transformer, batchQuery.GetFilter()); err != nil {
+		return 0, 0, 0, xerrors.Errorf("failed to parse filter: %w", err)
+	}
+
+	if _, err := getPartitionerFromGetFlightInfoCmd(cmd); err != nil {
+		return 0, 0, 0, xerrors.Errorf("failed to get partitioner: %w", err)
+	}
+
+	startHeight, endHeight, err := t.resolveHeights(ctx, batchQuery)
+	if err != nil {
+		return 0, 0, 0, xerrors.Errorf("failed to resolve heights: %w", err)
+	}
+
 	blocksPerPartition := defaultBlocksPerPartition
 	if batchQuery.GetBlocksPerPartition() > 0 {
 		blocksPerPartition = batchQuery.GetBlocksPerPartition()
@@ -76,26 +107,136 @@ func (t *BatchTable) parseGetEndpointsParams(ctx context.Context, cmd *api.GetFl
 	return startHeight, endHeight, blocksPerPartition, nil
 }
 
+// resolveHeights returns the start and end heights of the query, resolving start_time and end_time if provided.
+func (t *BatchTable) resolveHeights(ctx context.Context, batchQuery *api.GetFlightInfoCmd_BatchQuery) (uint64, uint64, error) {
+	startHeight := batchQuery.GetStartHeight()
+	if startTime := batchQuery.GetStartTime(); startTime != nil {
+		if startHeight > 0 {
+			return 0, 0, xerrors.Errorf("start_height and start_time are mutually exclusive: %w", errors.ErrInvalidArgument)
+		}
+
+		if err := startTime.CheckValid(); err != nil {
+			return 0, 0, xerrors.Errorf("invalid start_time: %v: %w", err, errors.ErrInvalidArgument)
+		}
+
+		height, err := t.session.GetHeightAtTime(ctx, startTime.AsTime())
+		if err != nil {
+			return 0, 0, xerrors.Errorf("failed to get height at start time(%v): %w", startTime.AsTime(), err)
+		}
+		startHeight = height
+	}
+
+	endHeight := batchQuery.GetEndHeight()
+	if endTime := batchQuery.GetEndTime(); endTime != nil {
+		if endHeight > 0 {
+			return 0, 0, xerrors.Errorf("end_height and end_time are mutually exclusive: %w", errors.ErrInvalidArgument)
+		}
+
+		if err := endTime.CheckValid(); err != nil {
+			return 0, 0, xerrors.Errorf("invalid end_time: %v: %w", err, errors.ErrInvalidArgument)
+		}
+
+		height, err := t.session.GetHeightAtTime(ctx, endTime.AsTime())
+		if err != nil {
+			return 0, 0, xerrors.Errorf("failed to get height at end time(%v): %w", endTime.AsTime(), err)
+		}
+		endHeight = height
+	}
+
+	return startHeight, endHeight, nil
+}
+
 func (t *BatchTable) GetEndpoints(ctx context.Context, cmd *api.GetFlightInfoCmd) ([]*flight.FlightEndpoint, error) {
+	endpoints, _, err := t.GetEndpointsWithEstimate(ctx, cmd)
+	return endpoints, err
+}
+
+func (t *BatchTable) GetEndpointsWithEstimate(ctx context.Context, cmd *api.GetFlightInfoCmd) ([]*flight.FlightEndpoint, *Estimate, error) {
 	var endpoints []*flight.FlightEndpoint
+	var estimate *Estimate
 	err := t.instrumentGetEndpoints.Instrument(ctx, func(ctx context.Context) error {
 		startHeight, endHeight, blocksPerPartition, err := t.parseGetEndpointsParams(ctx, cmd)
 		if err != nil {
 			return xerrors.Errorf("failed to parse params from cmd(%+v): %w", cmd, err)
 		}
 
-		numEndpoints := uint64(math.Ceil(float64(endHeight-startHeight) / float64(blocksPerPartition)))
-		if numEndpoints > maxNumOfEndpoints {
-			return xerrors.Errorf("blocks per partition(%d) is too small, resulted in %d endpoints: %w", blocksPerPartition, numEndpoints, errors.ErrInvalidArgument)
+		planningMode, err := getPlanningMode(cmd.GetBatchQuery())
+		if err != nil {
+			return xerrors.Errorf("failed to get planning mode: %w", err)
+		}
+
+		span := heightRange{start: startHeight, end: endHeight}
+		var ranges []heightRange
+		var sizes *blockSizes
+		switch planningMode {
+		case constant.PlanningModeRows, constant.PlanningModeBytes:
+			// The partitions are planned from the sizes of the blocks with the columns and filter of the query.
+			sizes, err = t.getBlockSizes(ctx, cmd, span)
+			if err != nil {
+				if xerrors.Is(err, errors.ErrInvalidArgument) {
+					return xerrors.Errorf("failed to get block sizes: %w", err)
+				}
+
+				// The sampling is best effort, so that a cold or slow range is planned by blocks_per_partition instead.
+				t.logger.Warn("failed to get block sizes, falling back to the blocks planning mode", zap.Error(err))
+				ranges, err = planBlocks(span, blocksPerPartition)
+				if err != nil {
+					return err
+				}
+
+				break
+			}
+
+			target := cmd.GetBatchQuery().GetRowsPerPartition()
+			if target == 0 {
+				target = defaultRowsPerPartition
+			}
+			if planningMode == constant.PlanningModeBytes {
+				target = cmd.GetBatchQuery().GetBytesPerPartition()
+				if target == 0 {
+					target = defaultBytesPerPartition
+				}
+			}
+
+			ranges, err = sizes.plan(span, target, planningMode == constant.PlanningModeBytes, maxNumOfEndpoints)
+			if err != nil {
+				return xerrors.Errorf("%v per partition(%d) is too small: %v: %w", planningMode, target, err, errors.ErrInvalidArgument)
+			}
+		default:
+			ranges, err = planBlocks(span, blocksPerPartition)
+			if err != nil {
+				return err
+			}
+
+			// The estimates are best effort and must not fail nor delay the query,
+			// so that they are only served from the sizes already sampled.
+			sizes, err = t.getCachedBlockSizes(ctx, cmd, span)
+			if err != nil {
+				t.logger.Warn("failed to estimate endpoints", zap.Error(err))
+				sizes = nil
+			}
 		}
 
-		endpoints = make([]*flight.FlightEndpoint, 0, numEndpoints)
-		for i := startHeight; i < endHeight; i += blocksPerPartition {
+		if sizes != nil {
+			estimate = &Estimate{}
+		}
+
+		endpoints = make([]*flight.FlightEndpoint, 0, len(ranges))
+		for _, r := range ranges {
 			ticket := proto.Clone(cmd).(*api.GetFlightInfoCmd)
-			ticket.GetBatchQuery().StartHeight = i
-			ticket.GetBatchQuery().EndHeight = i + blocksPerPartition
-			if ticket.GetBatchQuery().EndHeight > endHeight {
-				ticket.GetBatchQuery().EndHeight = endHeight
+			// The time range has been resolved to heights.
+			ticket.GetBatchQuery().StartTime = nil
+			ticket.GetBatchQuery().EndTime = nil
+			ticket.GetBatchQuery().StartHeight = r.start
+			ticket.GetBatchQuery().EndHeight = r.end
+			if sizes != nil {
+				rangeEstimate := sizes.estimate(r)
+				ticket.Estimate = &api.GetFlightInfoCmd_Estimate{
+					TotalRecords: rangeEstimate.TotalRecords,
+					TotalBytes:   rangeEstimate.TotalBytes,
+				}
+				estimate.TotalRecords += rangeEstimate.TotalRecords
+				estimate.TotalBytes += rangeEstimate.TotalBytes
 			}
 
 			ticketBytes, err := protoutil.MarshalJSON(ticket)
@@ -110,7 +251,97 @@ func (t *BatchTable) GetEndpoints(ctx context.Context, cmd *api.GetFlightInfoCmd
 		}
 		return nil
 	})
-	return endpoints, err
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return endpoints, estimate, nil
+}
+
+// planBlocks splits the range into consecutive ranges of blocksPerPartition blocks.
+func planBlocks(r heightRange, blocksPerPartition uint64) ([]heightRange, error) {
+	numEndpoints := uint64(math.Ceil(float64(r.end-r.start) / float64(blocksPerPartition)))
+	if numEndpoints > maxNumOfEndpoints {
+		return nil, xerrors.Errorf("blocks per partition(%d) is too small, resulted in %d endpoints: %w", blocksPerPartition, numEndpoints, errors.ErrInvalidArgument)
+	}
+
+	ranges := make([]heightRange, 0, numEndpoints)
+	for i := r.start; i < r.end; i += blocksPerPartition {
+		partition := heightRange{start: i, end: i + blocksPerPartition}
+		if partition.end > r.end {
+			partition.end = r.end
+		}
+		ranges = append(ranges, partition)
+	}
+
+	return ranges, nil
+}
+
+// getBlockSizes samples the block sizes of the range with the columns and filter of the query.
+// The sampling is bounded by the estimate timeout, so that the query is not delayed by a slow estimate.
+func (t *BatchTable) getBlockSizes(ctx context.Context, cmd *api.GetFlightInfoCmd, r heightRange) (*blockSizes, error) {
+	query, err := t.estimator.newSampleQuery(getColumnsFromGetFlightInfoCmd(cmd), cmd.GetBatchQuery().GetFilter())
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create sample query: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.config.GetEstimateTimeout())
+	defer cancel()
+
+	sizes, err := t.estimator.getBlockSizes(ctx, query, r)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get block sizes within %v: %w", t.config.GetEstimateTimeout(), err)
+	}
+
+	return sizes, nil
+}
+
+// getCachedBlockSizes returns the block sizes of the range with the columns and filter of the query,
+// only if they have all been sampled already. Otherwise, it returns nil and samples the range in the background,
+// so that the next queries of the range are estimated.
+func (t *BatchTable) getCachedBlockSizes(ctx context.Context, cmd *api.GetFlightInfoCmd, r heightRange) (*blockSizes, error) {
+	query, err := t.estimator.newSampleQuery(getColumnsFromGetFlightInfoCmd(cmd), cmd.GetBatchQuery().GetFilter())
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create sample query: %w", err)
+	}
+
+	sizes, err := t.estimator.getCachedBlockSizes(ctx, query, r)
+	if err == nil {
+		return sizes, nil
+	}
+
+	if !xerrors.Is(err, errBlockSizeNotCached) {
+		return nil, xerrors.Errorf("failed to get cached block sizes: %w", err)
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), t.config.GetEstimateTimeout())
+		defer cancel()
+
+		if _, err := t.estimator.getBlockSizes(ctx, query, r); err != nil {
+			t.logger.Warn("failed to sample block sizes", zap.Error(err))
+		}
+	}()
+
+	return nil, nil
+}
+
+func (t *BatchTable) EstimateTable(ctx context.Context) (*Estimate, error) {
+	return t.estimator.estimateTable()
+}
+
+func getPlanningMode(batchQuery *api.GetFlightInfoCmd_BatchQuery) (constant.PlanningMode, error) {
+	planningMode := batchQuery.GetPlanningMode()
+	if planningMode == "" {
+		return constant.PlanningModeBlocks, nil
+	}
+
+	res, err := constant.ParsePlanningMode(planningMode)
+	if err != nil {
+		return constant.PlanningModeBlocks, xerrors.Errorf("unsupported planning mode(%v): %w", planningMode, errors.ErrInvalidArgument)
+	}
+
+	return res, nil
 }
 
 func (t *BatchTable) parseDoGetParams(ctx context.Context, cmd *api.GetFlightInfoCmd) (uint64, uint64, uint64, error) {
@@ -119,6 +350,10 @@ func (t *BatchTable) parseDoGetParams(ctx context.Context, cmd *api.GetFlightInf
 		return 0, 0, 0, xerrors.Errorf("batchQuery is not provided: %w", errors.ErrInvalidArgument)
 	}
 
+	if _, err := getPartitionerFromGetFlightInfoCmd(cmd); err != nil {
+		return 0, 0, 0, xerrors.Errorf("failed to get partitioner: %w", err)
+	}
+
 	startHeight := batchQuery.GetStartHeight()
 	endHeight := batchQuery.GetEndHeight()
 	blocksPerRecord := DefaultBlocksPerRecord
@@ -139,19 +374,41 @@ func (t *BatchTable) DoGet(ctx context.Context, cmd *api.GetFlightInfoCmd, table
 			return xerrors.Errorf("failed to parse params from cmd(%+v): %w", cmd, err)
 		}
 
-		blocksWritten := uint64(0)
+		// The filter is parsed once for all the blocks of the query.
+		filter, err := parseFilter(t.transformer, cmd.GetBatchQuery().GetFilter())
+		if err != nil {
+			return xerrors.Errorf("failed to parse filter: %w", err)
+		}
+
+		partitioner, err := getPartitionerFromGetFlightInfoCmd(cmd)
+		if err != nil {
+			return xerrors.Errorf("failed to get partitioner: %w", err)
+		}
+
+		var chunks []heightRange
 		for chunkStart := startHeight; chunkStart < endHeight; chunkStart += blocksPerRecord {
 			chunkEnd := chunkStart + blocksPerRecord
 			if chunkEnd > endHeight {
 				chunkEnd = endHeight
 			}
+			chunks = append(chunks, heightRange{start: chunkStart, end: chunkEnd})
+		}
+
+		blocksWritten := uint64(0)
+		return t.prefetchBlocks(ctx, chunks, func(blocks []*chainstorageapi.Block) error {
+			transformStart := time.Now()
+			defer func() {
+				t.timerTransform.Record(time.Since(transformStart))
+			}()
 
-			blocks, err := t.session.Client().GetBlocksByRange(ctx, chunkStart, chunkEnd)
-			if err != nil {
-				return xerrors.Errorf("failed to get raw blocks: %w", err)
-			}
 			for _, block := range blocks {
-				if err := t.transformer.TransformBlock(ctx, block, t.session.Parser(), tableWriter.RecordBuilder(), cmd.GetBatchQuery().PartitionBySize); err != nil {
+				// The skipped slots, e.g. of solana, have no block to transform.
+				if block.GetMetadata().GetSkipped() {
+					t.counterBlocksSkipped.Inc(1)
+					continue
+				}
+
+				if err := t.transformer.TransformBlock(ctx, block, t.session.Parser(), tableWriter.RecordBuilder(), filter, partitioner); err != nil {
 					return xerrors.Errorf("failed to process block: %w", err)
 				}
 
@@ -164,8 +421,82 @@ func (t *BatchTable) DoGet(ctx context.Context, cmd *api.GetFlightInfoCmd, table
 				}
 				t.counterBlocksProcessed.Inc(1)
 			}
+
+			return nil
+		})
+	})
+}
+
+// prefetchBlocks fetches the blocks of the chunks and calls fn on them, in the order of the chunks.
+// While fn is processing a chunk, up to PrefetchDepth upcoming chunks are fetched, Parallelism of them at a time.
+func (t *BatchTable) prefetchBlocks(ctx context.Context, chunks []heightRange, fn func(blocks []*chainstorageapi.Block) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	group, ctx := syncgroup.New(ctx, syncgroup.WithThrottling(t.config.GetParallelism()))
+	fetches := make([]*blocksFetch, len(chunks))
+	for i := range fetches {
+		fetches[i] = &blocksFetch{done: make(chan struct{})}
+	}
+
+	// Each fetched chunk holds a slot until fn starts processing it.
+	slots := make(chan struct{}, t.config.GetPrefetchDepth())
+	// The fetches are scheduled outside of the group so that the group is not waited while being added to.
+	scheduled := make(chan struct{})
+	go func() {
+		defer close(scheduled)
+		for i, chunk := range chunks {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			fetch, chunk := fetches[i], chunk
+			group.Go(func() error {
+				blocks, err := t.session.Client().GetBlocksByRange(ctx, chunk.start, chunk.end)
+				if err != nil {
+					return xerrors.Errorf("failed to get raw blocks: %w", err)
+				}
+
+				fetch.blocks = blocks
+				close(fetch.done)
+				return nil
+			})
+		}
+	}()
+
+	process := func() error {
+		for _, fetch := range fetches {
+			waitStart := time.Now()
+			select {
+			case <-fetch.done:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			t.timerFetchWait.Record(time.Since(waitStart))
+			<-slots
+
+			if err := fn(fetch.blocks); err != nil {
+				return err
+			}
 		}
 
 		return nil
-	})
+	}
+
+	processErr := process()
+	cancel()
+	<-scheduled
+	waitErr := group.Wait()
+	if processErr != nil {
+		// Prefer the error of the failed fetch over the resulting cancellation.
+		if waitErr != nil && xerrors.Is(processErr, context.Canceled) {
+			return waitErr
+		}
+
+		return processErr
+	}
+
+	return waitErr
 }
@@ -0,0 +1,457 @@
+package internal
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v10/arrow/array"
+	"github.com/apache/arrow/go/v10/arrow/memory"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/fx"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/xerrors"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+	sdkmocks "github.com/coinbase/chainstorage/sdk/mocks"
+
+	"github.com/coinbase/chainsformer/internal/chainstorage"
+	csmocks "github.com/coinbase/chainsformer/internal/chainstorage/mocks"
+	internalerrors "github.com/coinbase/chainsformer/internal/errors"
+	"github.com/coinbase/chainsformer/internal/utils/protoutil"
+	"github.com/coinbase/chainsformer/internal/utils/testapp"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+	xarrowmocks "github.com/coinbase/chainsformer/internal/utils/xarrow/mocks"
+	api "github.com/coinbase/chainsformer/protos/coinbase/chainsformer"
+)
+
+type (
+	batchTableTestSuite struct {
+		suite.Suite
+	}
+)
+
+var failedToGetBlockError = xerrors.New("failed to get block")
+
+func TestBatchTableTestSuite(t *testing.T) {
+	suite.Run(t, new(batchTableTestSuite))
+}
+
+func (s *batchTableTestSuite) TestGetEndpointsWithEstimate() {
+	testCases := map[string]struct {
+		batchQuery         *api.GetFlightInfoCmd_BatchQuery
+		getBlockError      error
+		getBlockTimeout    bool
+		expectedRanges     []heightRange
+		expectedRecords    []int64
+		expectedEstimate   bool
+		expectedTotalCount int64
+		expectedError      error
+	}{
+		"blocks planning mode cuts partitions of blocks_per_partition blocks": {
+			batchQuery: &api.GetFlightInfoCmd_BatchQuery{
+				StartHeight:        0,
+				EndHeight:          25,
+				BlocksPerPartition: 10,
+			},
+			expectedRanges:     []heightRange{{start: 0, end: 10}, {start: 10, end: 20}, {start: 20, end: 25}},
+			expectedRecords:    []int64{30, 30, 15},
+			expectedEstimate:   true,
+			expectedTotalCount: 75,
+		},
+		"blocks planning mode estimates the columns and filter of the query": {
+			batchQuery: &api.GetFlightInfoCmd_BatchQuery{
+				StartHeight:        0,
+				EndHeight:          25,
+				BlocksPerPartition: 10,
+				Columns:            []string{"height"},
+				Filter:             &api.GetFlightInfoCmd_Filter{TransactionTypes: []uint64{1}},
+			},
+			expectedRanges:     []heightRange{{start: 0, end: 10}, {start: 10, end: 20}, {start: 20, end: 25}},
+			expectedRecords:    []int64{10, 10, 5},
+			expectedEstimate:   true,
+			expectedTotalCount: 25,
+		},
+		"rows planning mode cuts partitions of rows_per_partition rows": {
+			batchQuery: &api.GetFlightInfoCmd_BatchQuery{
+				StartHeight:      0,
+				EndHeight:        25,
+				PlanningMode:     "rows",
+				RowsPerPartition: 30,
+			},
+			expectedRanges:     []heightRange{{start: 0, end: 10}, {start: 10, end: 20}, {start: 20, end: 25}},
+			expectedRecords:    []int64{30, 30, 15},
+			expectedEstimate:   true,
+			expectedTotalCount: 75,
+		},
+		"failed estimation in blocks planning mode returns the endpoints without estimate": {
+			batchQuery: &api.GetFlightInfoCmd_BatchQuery{
+				StartHeight:        0,
+				EndHeight:          20,
+				BlocksPerPartition: 10,
+			},
+			getBlockError:  failedToGetBlockError,
+			expectedRanges: []heightRange{{start: 0, end: 10}, {start: 10, end: 20}},
+		},
+		"estimation timeout in blocks planning mode returns the endpoints without estimate": {
+			batchQuery: &api.GetFlightInfoCmd_BatchQuery{
+				StartHeight:        0,
+				EndHeight:          20,
+				BlocksPerPartition: 10,
+			},
+			getBlockTimeout: true,
+			expectedRanges:  []heightRange{{start: 0, end: 10}, {start: 10, end: 20}},
+		},
+		"failed estimation in rows planning mode falls back to blocks_per_partition": {
+			batchQuery: &api.GetFlightInfoCmd_BatchQuery{
+				StartHeight:        0,
+				EndHeight:          20,
+				PlanningMode:       "rows",
+				BlocksPerPartition: 10,
+			},
+			getBlockError:  failedToGetBlockError,
+			expectedRanges: []heightRange{{start: 0, end: 10}, {start: 10, end: 20}},
+		},
+		"rows planning mode plans from the rows matching the filter": {
+			batchQuery: &api.GetFlightInfoCmd_BatchQuery{
+				StartHeight:      0,
+				EndHeight:        25,
+				PlanningMode:     "rows",
+				RowsPerPartition: 10,
+				Filter:           &api.GetFlightInfoCmd_Filter{TransactionTypes: []uint64{1}},
+			},
+			expectedRanges:     []heightRange{{start: 0, end: 10}, {start: 10, end: 20}, {start: 20, end: 25}},
+			expectedRecords:    []int64{10, 10, 5},
+			expectedEstimate:   true,
+			expectedTotalCount: 25,
+		},
+		// The projected blocks are 28 bytes, against 45 bytes with every column.
+		"bytes planning mode plans from the projected columns": {
+			batchQuery: &api.GetFlightInfoCmd_BatchQuery{
+				StartHeight:       0,
+				EndHeight:         25,
+				PlanningMode:      "bytes",
+				BytesPerPartition: 280,
+				Columns:           []string{"height"},
+			},
+			expectedRanges:     []heightRange{{start: 0, end: 10}, {start: 10, end: 20}, {start: 20, end: 25}},
+			expectedRecords:    []int64{30, 30, 15},
+			expectedEstimate:   true,
+			expectedTotalCount: 75,
+		},
+		"estimation timeout in rows planning mode falls back to blocks_per_partition": {
+			batchQuery: &api.GetFlightInfoCmd_BatchQuery{
+				StartHeight:        0,
+				EndHeight:          20,
+				PlanningMode:       "rows",
+				BlocksPerPartition: 10,
+			},
+			getBlockTimeout: true,
+			expectedRanges:  []heightRange{{start: 0, end: 10}, {start: 10, end: 20}},
+		},
+		"too many partitions in the fallback of the rows planning mode returns invalid argument error": {
+			batchQuery: &api.GetFlightInfoCmd_BatchQuery{
+				StartHeight:        0,
+				EndHeight:          maxNumOfEndpoints + 1,
+				PlanningMode:       "rows",
+				BlocksPerPartition: 1,
+			},
+			getBlockTimeout: true,
+			expectedError:   internalerrors.ErrInvalidArgument,
+		},
+		"unknown planning mode returns invalid argument error": {
+			batchQuery: &api.GetFlightInfoCmd_BatchQuery{
+				StartHeight:  0,
+				EndHeight:    20,
+				PlanningMode: "unknown",
+			},
+			expectedError: internalerrors.ErrInvalidArgument,
+		},
+		"too many partitions in rows planning mode returns invalid argument error": {
+			batchQuery: &api.GetFlightInfoCmd_BatchQuery{
+				StartHeight:      0,
+				EndHeight:        maxNumOfEndpoints + 1,
+				PlanningMode:     "rows",
+				RowsPerPartition: 1,
+			},
+			expectedError: internalerrors.ErrInvalidArgument,
+		},
+	}
+
+	for testName, tc := range testCases {
+		tc := tc
+		s.T().Run(testName, func(t *testing.T) {
+			require := require.New(t)
+
+			batchTable, client := newTestBatchTable(t)
+			batchTable.config.EstimateTimeout = 100 * time.Millisecond
+			batchTable.estimator.sampler.timeout = 100 * time.Millisecond
+			client.EXPECT().GetBlock(gomock.Any(), gomock.Any(), "").
+				DoAndReturn(func(ctx context.Context, height uint64, hash string) (*chainstorageapi.Block, error) {
+					if tc.getBlockTimeout {
+						<-ctx.Done()
+						return nil, ctx.Err()
+					}
+
+					if tc.getBlockError != nil {
+						return nil, tc.getBlockError
+					}
+
+					return &chainstorageapi.Block{
+						Metadata: &chainstorageapi.BlockMetadata{Height: height},
+					}, nil
+				}).AnyTimes()
+
+			cmd := &api.GetFlightInfoCmd{
+				Query: &api.GetFlightInfoCmd_BatchQuery_{
+					BatchQuery: tc.batchQuery,
+				},
+			}
+			endpoints, estimate, err := batchTable.GetEndpointsWithEstimate(context.Background(), cmd)
+			if tc.batchQuery.PlanningMode == "" && err == nil {
+				// The blocks planning mode does not wait for the blocks to be sampled,
+				// and only estimates the queries once sampled in the background.
+				require.Nil(estimate)
+				if tc.expectedEstimate {
+					require.Eventually(func() bool {
+						endpoints, estimate, err = batchTable.GetEndpointsWithEstimate(context.Background(), cmd)
+						return err != nil || estimate != nil
+					}, 5*time.Second, 10*time.Millisecond)
+				}
+			}
+
+			if tc.expectedError != nil {
+				require.ErrorIs(err, tc.expectedError)
+				return
+			}
+
+			require.NoError(err)
+			require.Equal(len(tc.expectedRanges), len(endpoints))
+			for i, endpoint := range endpoints {
+				var ticket api.GetFlightInfoCmd
+				require.NoError(protoutil.UnmarshalJSON(endpoint.Ticket.Ticket, &ticket))
+				require.Equal(tc.expectedRanges[i].start, ticket.GetBatchQuery().GetStartHeight())
+				require.Equal(tc.expectedRanges[i].end, ticket.GetBatchQuery().GetEndHeight())
+				if tc.expectedEstimate {
+					require.Equal(tc.expectedRecords[i], ticket.GetEstimate().GetTotalRecords())
+				} else {
+					require.Nil(ticket.GetEstimate())
+				}
+			}
+
+			if !tc.expectedEstimate {
+				require.Nil(estimate)
+				return
+			}
+
+			require.NotNil(estimate)
+			require.Equal(tc.expectedTotalCount, estimate.TotalRecords)
+			require.Greater(estimate.TotalBytes, int64(0))
+		})
+	}
+}
+
+func (s *batchTableTestSuite) TestDoGet_PrefetchPreservesOrder() {
+	require := require.New(s.T())
+
+	batchTable, client := newTestBatchTable(s.T())
+	var inFlight, maxInFlight int32
+	client.EXPECT().GetBlocksByRange(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, startHeight uint64, endHeight uint64) ([]*chainstorageapi.Block, error) {
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				max := atomic.LoadInt32(&maxInFlight)
+				if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+					break
+				}
+			}
+
+			// The later chunks are fetched faster than the earlier ones.
+			time.Sleep(time.Duration(20-startHeight) * time.Millisecond)
+			blocks := make([]*chainstorageapi.Block, 0, endHeight-startHeight)
+			for height := startHeight; height < endHeight; height++ {
+				blocks = append(blocks, &chainstorageapi.Block{
+					Metadata: &chainstorageapi.BlockMetadata{Height: height},
+				})
+			}
+			return blocks, nil
+		}).Times(10)
+
+	recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), batchTable.GetSchema(), nil)
+	defer recordBuilder.Release()
+	tableWriter := xarrowmocks.NewMockTableWriter(gomock.NewController(s.T()))
+	tableWriter.EXPECT().RecordBuilder().Return(recordBuilder).Times(20)
+	tableWriter.EXPECT().Flush().Return(nil).Times(10)
+
+	cmd := &api.GetFlightInfoCmd{
+		Query: &api.GetFlightInfoCmd_BatchQuery_{
+			BatchQuery: &api.GetFlightInfoCmd_BatchQuery{
+				StartHeight:     0,
+				EndHeight:       20,
+				BlocksPerRecord: 2,
+			},
+		},
+	}
+	require.NoError(batchTable.DoGet(context.Background(), cmd, tableWriter))
+	require.Greater(atomic.LoadInt32(&maxInFlight), int32(1))
+	require.LessOrEqual(atomic.LoadInt32(&maxInFlight), int32(batchTable.config.GetParallelism()))
+
+	rec := recordBuilder.NewRecord()
+	defer rec.Release()
+	// The test table appends 3 rows per block.
+	require.Equal(int64(60), rec.NumRows())
+	heights := rec.Column(0).(*array.Uint64)
+	for i := 0; i < heights.Len(); i++ {
+		require.Equal(uint64(i/3), heights.Value(i))
+	}
+}
+
+func (s *batchTableTestSuite) TestDoGet_FailedFetchReturnsError() {
+	require := require.New(s.T())
+
+	batchTable, client := newTestBatchTable(s.T())
+	client.EXPECT().GetBlocksByRange(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, startHeight uint64, endHeight uint64) ([]*chainstorageapi.Block, error) {
+			if startHeight == 4 {
+				return nil, failedToGetBlockError
+			}
+
+			return []*chainstorageapi.Block{
+				{Metadata: &chainstorageapi.BlockMetadata{Height: startHeight}},
+			}, nil
+		}).AnyTimes()
+
+	recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), batchTable.GetSchema(), nil)
+	defer recordBuilder.Release()
+	tableWriter := xarrowmocks.NewMockTableWriter(gomock.NewController(s.T()))
+	tableWriter.EXPECT().RecordBuilder().Return(recordBuilder).AnyTimes()
+	tableWriter.EXPECT().Flush().Return(nil).AnyTimes()
+
+	cmd := &api.GetFlightInfoCmd{
+		Query: &api.GetFlightInfoCmd_BatchQuery_{
+			BatchQuery: &api.GetFlightInfoCmd_BatchQuery{
+				StartHeight:     0,
+				EndHeight:       100,
+				BlocksPerRecord: 1,
+			},
+		},
+	}
+	err := batchTable.DoGet(context.Background(), cmd, tableWriter)
+	require.ErrorIs(err, failedToGetBlockError)
+}
+
+func (s *batchTableTestSuite) TestDoGet_SkippedBlocks() {
+	require := require.New(s.T())
+
+	batchTable, client := newTestBatchTable(s.T())
+	client.EXPECT().GetBlocksByRange(gomock.Any(), uint64(0), uint64(4)).
+		Return([]*chainstorageapi.Block{
+			{Metadata: &chainstorageapi.BlockMetadata{Height: 0}},
+			{Metadata: &chainstorageapi.BlockMetadata{Height: 1, Skipped: true}},
+			{Metadata: &chainstorageapi.BlockMetadata{Height: 2, Skipped: true}},
+			{Metadata: &chainstorageapi.BlockMetadata{Height: 3}},
+		}, nil)
+
+	recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), batchTable.GetSchema(), nil)
+	defer recordBuilder.Release()
+	tableWriter := xarrowmocks.NewMockTableWriter(gomock.NewController(s.T()))
+	tableWriter.EXPECT().RecordBuilder().Return(recordBuilder).Times(2)
+	tableWriter.EXPECT().Flush().Return(nil).AnyTimes()
+
+	cmd := &api.GetFlightInfoCmd{
+		Query: &api.GetFlightInfoCmd_BatchQuery_{
+			BatchQuery: &api.GetFlightInfoCmd_BatchQuery{
+				StartHeight:     0,
+				EndHeight:       4,
+				BlocksPerRecord: 4,
+			},
+		},
+	}
+	require.NoError(batchTable.DoGet(context.Background(), cmd, tableWriter))
+
+	rec := recordBuilder.NewRecord()
+	defer rec.Release()
+	require.Equal(int64(6), rec.NumRows())
+	heights := rec.Column(0).(*array.Uint64)
+	require.Equal(uint64(0), heights.Value(0))
+	require.Equal(uint64(3), heights.Value(3))
+}
+
+func (s *batchTableTestSuite) TestDoGet_ParsesFilterOnce() {
+	require := require.New(s.T())
+
+	batchTable, client := newTestBatchTable(s.T())
+	var filtersParsed int32
+	batchTable.transformer = testRowsPerBlockTable{rowsPerBlock: 3, filtersParsed: &filtersParsed}
+	client.EXPECT().GetBlocksByRange(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, startHeight uint64, endHeight uint64) ([]*chainstorageapi.Block, error) {
+			blocks := make([]*chainstorageapi.Block, 0, endHeight-startHeight)
+			for height := startHeight; height < endHeight; height++ {
+				blocks = append(blocks, &chainstorageapi.Block{
+					Metadata: &chainstorageapi.BlockMetadata{Height: height},
+				})
+			}
+			return blocks, nil
+		}).Times(2)
+
+	recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), batchTable.GetSchema(), nil)
+	defer recordBuilder.Release()
+	tableWriter := xarrowmocks.NewMockTableWriter(gomock.NewController(s.T()))
+	tableWriter.EXPECT().RecordBuilder().Return(recordBuilder).Times(4)
+	tableWriter.EXPECT().Flush().Return(nil).Times(2)
+
+	cmd := &api.GetFlightInfoCmd{
+		Query: &api.GetFlightInfoCmd_BatchQuery_{
+			BatchQuery: &api.GetFlightInfoCmd_BatchQuery{
+				StartHeight:     0,
+				EndHeight:       4,
+				BlocksPerRecord: 2,
+				Filter:          &api.GetFlightInfoCmd_Filter{TransactionTypes: []uint64{2}},
+			},
+		},
+	}
+	require.NoError(batchTable.DoGet(context.Background(), cmd, tableWriter))
+	require.Equal(int32(1), atomic.LoadInt32(&filtersParsed))
+
+	// Every block is transformed with the parsed filter.
+	rec := recordBuilder.NewRecord()
+	defer rec.Release()
+	require.Equal(int64(4), rec.NumRows())
+}
+
+func newTestBatchTable(t *testing.T) (*BatchTable, *sdkmocks.MockClient) {
+	ctrl := gomock.NewController(t)
+	session := csmocks.NewMockSession(ctrl)
+	client := sdkmocks.NewMockClient(ctrl)
+	parser := sdkmocks.NewMockParser(ctrl)
+	session.EXPECT().Client().Return(client).AnyTimes()
+	session.EXPECT().Parser().Return(parser).AnyTimes()
+	session.EXPECT().GetStaticChainMetadata(gomock.Any(), gomock.Any()).
+		Return(&chainstorageapi.GetChainMetadataResponse{}, nil).AnyTimes()
+	session.EXPECT().GetTipHeight(gomock.Any()).Return(uint64(maxNumOfEndpoints+1), nil).AnyTimes()
+	parser.EXPECT().ParseNativeBlock(gomock.Any(), gomock.Any()).
+		Return(&chainstorageapi.NativeBlock{}, nil).AnyTimes()
+
+	var tableParams CommonTableParams
+	app := testapp.New(
+		t,
+		fx.Provide(func() chainstorage.Session {
+			return session
+		}),
+		fx.Provide(NewBlockSampler),
+		fx.Populate(&tableParams),
+	)
+	defer app.Close()
+
+	batchTable := NewBatchTable(
+		&tableParams,
+		NewTableAttributes(TableNameTransactions),
+		newTestRowsPerBlockSchema(),
+		testRowsPerBlockTable{rowsPerBlock: 3},
+	)
+	return batchTable, client
+}
@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"go.uber.org/fx"
+	"golang.org/x/sync/singleflight"
+	"golang.org/x/xerrors"
+
+	"github.com/coinbase/chainsformer/internal/chainstorage"
+	"github.com/coinbase/chainsformer/internal/utils/fxparams"
+)
+
+type (
+	BlockSamplerParams struct {
+		fx.In
+		fxparams.Params
+		Session chainstorage.Session
+	}
+
+	// BlockSampler caches the block sizes sampled to estimate the size of the queries.
+	// It is shared by all the tables, so that a segment of heights is only sampled once
+	// for all the concurrent and later queries with the same table, columns and filter.
+	// The sampled blocks are fetched and parsed by each table, through the block cache of the session if enabled.
+	BlockSampler struct {
+		session chainstorage.Session
+		group   singleflight.Group
+		sizes   *lru.Cache[sampleKey, *blockSize]
+		timeout time.Duration
+	}
+
+	// sampleKey is the key of the block size of a query sampled over a segment of heights.
+	// The sampled segments never extend past the irreversible tip, so that their sizes never change.
+	sampleKey struct {
+		table   string
+		query   string
+		segment heightRange
+	}
+)
+
+func NewBlockSampler(params BlockSamplerParams) (*BlockSampler, error) {
+	sizes, err := lru.New[sampleKey, *blockSize](estimateCacheSize)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create block size cache: %w", err)
+	}
+
+	return &BlockSampler{
+		session: params.Session,
+		sizes:   sizes,
+		timeout: params.Config.Table.BatchTable.GetEstimateTimeout(),
+	}, nil
+}
+
+// getCachedBlockSize returns the block size of the key if it has already been sampled.
+func (s *BlockSampler) getCachedBlockSize(key sampleKey) (*blockSize, bool) {
+	return s.sizes.Get(key)
+}
+
+// getBlockSize returns the block size of the key, which is sampled by sample if not cached yet.
+// The concurrent callers with the same key share a single sample, which runs under its own context
+// bounded by the estimate timeout, so that a caller giving up does not fail the others.
+func (s *BlockSampler) getBlockSize(ctx context.Context, key sampleKey, sample func(ctx context.Context) (*blockSize, error)) (*blockSize, error) {
+	if size, ok := s.sizes.Get(key); ok {
+		return size, nil
+	}
+
+	groupKey := fmt.Sprintf("%v/%v/%d-%d", key.table, key.query, key.segment.start, key.segment.end)
+	ch := s.group.DoChan(groupKey, func() (interface{}, error) {
+		sampleCtx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		defer cancel()
+
+		// Only the sampled sizes are cached, so that a failed or timed out sample is retried by the next query.
+		size, err := sample(sampleCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		s.sizes.Add(key, size)
+		return size, nil
+	})
+
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+
+		return res.Val.(*blockSize), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -2,9 +2,18 @@ package constant
 
 //go:generate go-enum -f=$GOFILE --marshal
 type (
+	// ENUM(none, lz4_frame, zstd)
+	Compression int
+
 	// ENUM(native, rosetta)
 	TableFormat int
 
-	// ENUM(none, raw)
+	// ENUM(none, raw, typed)
 	Encoding int
+
+	// ENUM(blocks, rows, bytes)
+	PlanningMode int
+
+	// ENUM(sampled, blocks, transactions)
+	EstimateMode int
 )
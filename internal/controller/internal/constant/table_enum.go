@@ -10,18 +10,76 @@ import (
 	"fmt"
 )
 
+const (
+	// CompressionNone is a Compression of type None.
+	CompressionNone Compression = iota
+	// CompressionLz4Frame is a Compression of type Lz4_frame.
+	CompressionLz4Frame
+	// CompressionZstd is a Compression of type Zstd.
+	CompressionZstd
+)
+
+const _CompressionName = "nonelz4_framezstd"
+
+var _CompressionMap = map[Compression]string{
+	CompressionNone:     _CompressionName[0:4],
+	CompressionLz4Frame: _CompressionName[4:13],
+	CompressionZstd:     _CompressionName[13:17],
+}
+
+// String implements the Stringer interface.
+func (x Compression) String() string {
+	if str, ok := _CompressionMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("Compression(%d)", x)
+}
+
+var _CompressionValue = map[string]Compression{
+	_CompressionName[0:4]:   CompressionNone,
+	_CompressionName[4:13]:  CompressionLz4Frame,
+	_CompressionName[13:17]: CompressionZstd,
+}
+
+// ParseCompression attempts to convert a string to a Compression.
+func ParseCompression(name string) (Compression, error) {
+	if x, ok := _CompressionValue[name]; ok {
+		return x, nil
+	}
+	return Compression(0), fmt.Errorf("%s is not a valid Compression", name)
+}
+
+// MarshalText implements the text marshaller method.
+func (x Compression) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *Compression) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseCompression(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
 const (
 	// EncodingNone is a Encoding of type None.
 	EncodingNone Encoding = iota
 	// EncodingRaw is a Encoding of type Raw.
 	EncodingRaw
+	// EncodingTyped is a Encoding of type Typed.
+	EncodingTyped
 )
 
-const _EncodingName = "noneraw"
+const _EncodingName = "nonerawtyped"
 
 var _EncodingMap = map[Encoding]string{
-	EncodingNone: _EncodingName[0:4],
-	EncodingRaw:  _EncodingName[4:7],
+	EncodingNone:  _EncodingName[0:4],
+	EncodingRaw:   _EncodingName[4:7],
+	EncodingTyped: _EncodingName[7:12],
 }
 
 // String implements the Stringer interface.
@@ -33,8 +91,9 @@ func (x Encoding) String() string {
 }
 
 var _EncodingValue = map[string]Encoding{
-	_EncodingName[0:4]: EncodingNone,
-	_EncodingName[4:7]: EncodingRaw,
+	_EncodingName[0:4]:  EncodingNone,
+	_EncodingName[4:7]:  EncodingRaw,
+	_EncodingName[7:12]: EncodingTyped,
 }
 
 // ParseEncoding attempts to convert a string to a Encoding.
@@ -61,6 +120,116 @@ func (x *Encoding) UnmarshalText(text []byte) error {
 	return nil
 }
 
+const (
+	// EstimateModeSampled is a EstimateMode of type Sampled.
+	EstimateModeSampled EstimateMode = iota
+	// EstimateModeBlocks is a EstimateMode of type Blocks.
+	EstimateModeBlocks
+	// EstimateModeTransactions is a EstimateMode of type Transactions.
+	EstimateModeTransactions
+)
+
+const _EstimateModeName = "sampledblockstransactions"
+
+var _EstimateModeMap = map[EstimateMode]string{
+	EstimateModeSampled:      _EstimateModeName[0:7],
+	EstimateModeBlocks:       _EstimateModeName[7:13],
+	EstimateModeTransactions: _EstimateModeName[13:25],
+}
+
+// String implements the Stringer interface.
+func (x EstimateMode) String() string {
+	if str, ok := _EstimateModeMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("EstimateMode(%d)", x)
+}
+
+var _EstimateModeValue = map[string]EstimateMode{
+	_EstimateModeName[0:7]:   EstimateModeSampled,
+	_EstimateModeName[7:13]:  EstimateModeBlocks,
+	_EstimateModeName[13:25]: EstimateModeTransactions,
+}
+
+// ParseEstimateMode attempts to convert a string to a EstimateMode.
+func ParseEstimateMode(name string) (EstimateMode, error) {
+	if x, ok := _EstimateModeValue[name]; ok {
+		return x, nil
+	}
+	return EstimateMode(0), fmt.Errorf("%s is not a valid EstimateMode", name)
+}
+
+// MarshalText implements the text marshaller method.
+func (x EstimateMode) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *EstimateMode) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseEstimateMode(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+const (
+	// PlanningModeBlocks is a PlanningMode of type Blocks.
+	PlanningModeBlocks PlanningMode = iota
+	// PlanningModeRows is a PlanningMode of type Rows.
+	PlanningModeRows
+	// PlanningModeBytes is a PlanningMode of type Bytes.
+	PlanningModeBytes
+)
+
+const _PlanningModeName = "blocksrowsbytes"
+
+var _PlanningModeMap = map[PlanningMode]string{
+	PlanningModeBlocks: _PlanningModeName[0:6],
+	PlanningModeRows:   _PlanningModeName[6:10],
+	PlanningModeBytes:  _PlanningModeName[10:15],
+}
+
+// String implements the Stringer interface.
+func (x PlanningMode) String() string {
+	if str, ok := _PlanningModeMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("PlanningMode(%d)", x)
+}
+
+var _PlanningModeValue = map[string]PlanningMode{
+	_PlanningModeName[0:6]:   PlanningModeBlocks,
+	_PlanningModeName[6:10]:  PlanningModeRows,
+	_PlanningModeName[10:15]: PlanningModeBytes,
+}
+
+// ParsePlanningMode attempts to convert a string to a PlanningMode.
+func ParsePlanningMode(name string) (PlanningMode, error) {
+	if x, ok := _PlanningModeValue[name]; ok {
+		return x, nil
+	}
+	return PlanningMode(0), fmt.Errorf("%s is not a valid PlanningMode", name)
+}
+
+// MarshalText implements the text marshaller method.
+func (x PlanningMode) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *PlanningMode) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParsePlanningMode(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
 const (
 	// TableFormatNative is a TableFormat of type Native.
 	TableFormatNative TableFormat = iota
@@ -49,6 +49,10 @@ func (i *errorInterceptor) DoAction(action *flight.Action, fs flight.FlightServi
 	return i.mapError(i.next.DoAction(action, fs))
 }
 
+func (i *errorInterceptor) ListActions(in *flight.Empty, fs flight.FlightService_ListActionsServer) error {
+	return i.mapError(i.next.ListActions(in, fs))
+}
+
 func (i *errorInterceptor) DoGet(tkt *flight.Ticket, fs flight.FlightService_DoGetServer) error {
 	return i.mapError(i.next.DoGet(tkt, fs))
 }
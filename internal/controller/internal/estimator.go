@@ -0,0 +1,569 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/apache/arrow/go/v10/arrow"
+	"github.com/apache/arrow/go/v10/arrow/flight"
+	"github.com/apache/arrow/go/v10/arrow/memory"
+	"golang.org/x/xerrors"
+	"google.golang.org/protobuf/proto"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+	"github.com/coinbase/chainstorage/sdk"
+
+	"github.com/coinbase/chainsformer/internal/chainstorage"
+	"github.com/coinbase/chainsformer/internal/controller/internal/constant"
+	"github.com/coinbase/chainsformer/internal/errors"
+	"github.com/coinbase/chainsformer/internal/utils/syncgroup"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+	api "github.com/coinbase/chainsformer/protos/coinbase/chainsformer"
+)
+
+const (
+	// estimateBucketSize is the smallest number of heights sharing the same sampled block size.
+	// The partitions of the rows and bytes planning modes are cut assuming the blocks of a bucket have the same size,
+	// so that the buckets must be small enough to follow the changes of the transaction volume.
+	estimateBucketSize = uint64(1000)
+	// estimateSamplesPerBucket is the number of blocks sampled in a bucket.
+	estimateSamplesPerBucket = 2
+	// maxEstimateBuckets bounds the number of buckets sampled by a single estimate.
+	// The bucket size is doubled until the range overlaps at most maxEstimateBuckets buckets,
+	// so that every bucket of the range is sampled and the estimate of a range is deterministic.
+	// A range of the whole Ethereum chain is cut into buckets of about 100,000 heights, i.e. two weeks of blocks.
+	maxEstimateBuckets = 256
+	// estimateSegmentSteps is the number of segments of the bucket crossing the tip which may be sampled.
+	estimateSegmentSteps = 8
+	estimateParallelism  = 8
+	estimateCacheSize    = 100000
+	// tableEstimateInterval is the minimum interval between two refreshes of the estimate of a whole table.
+	tableEstimateInterval = 10 * time.Minute
+	tableEstimateTimeout  = 5 * time.Minute
+)
+
+// errBlockSizeNotCached is returned when the size of the blocks is only looked up from the sampled sizes.
+var errBlockSizeNotCached = xerrors.New("block size not sampled yet")
+
+type (
+	// Estimate is the estimated number of records and uncompressed bytes returned by a query.
+	Estimate struct {
+		TotalRecords int64
+		TotalBytes   int64
+	}
+
+	// SizeEstimator is implemented by the tables able to estimate the size of their queries.
+	SizeEstimator interface {
+		// GetEndpointsWithEstimate is like GetEndpoints, but also returns the estimated size of the query.
+		// The estimate of each endpoint is set in its ticket.
+		// The estimate is nil if it could not be computed.
+		GetEndpointsWithEstimate(ctx context.Context, cmd *api.GetFlightInfoCmd) ([]*flight.FlightEndpoint, *Estimate, error)
+		// EstimateTable returns the estimated size of the whole table, from the start of the chain to the tip.
+		// It must be cheap, and returns a nil estimate if none is available yet.
+		EstimateTable(ctx context.Context) (*Estimate, error)
+	}
+
+	// nativeBlockParser is the parser of a single sampled block, which only parses its native block once.
+	nativeBlockParser struct {
+		sdk.Parser
+		mu          sync.Mutex
+		nativeBlock *chainstorageapi.NativeBlock
+	}
+
+	heightRange struct {
+		start uint64
+		end   uint64
+	}
+
+	// sampleQuery is the projected schema and parsed filter of the queries whose blocks are sampled.
+	// The key identifies the columns and filter of the query.
+	sampleQuery struct {
+		key        string
+		schema     *arrow.Schema
+		projection *xarrow.Projection
+		filter     Filter
+	}
+
+	// blockSize is the average size of the rows produced by a block, sampled from a bucket.
+	blockSize struct {
+		records float64
+		bytes   float64
+	}
+
+	// blockSizes are the block sizes by bucket of bucketSize heights.
+	blockSizes struct {
+		bucketSize uint64
+		sizes      map[uint64]*blockSize
+	}
+
+	// sizeEstimator extrapolates the size of height ranges from a few blocks sampled per bucket of heights.
+	// The number of rows is either counted from the block metadata, or by transforming the sampled blocks.
+	// The blocks are transformed with the columns and filter of the query,
+	// and the sampled sizes are cached by the sampler shared with the other tables.
+	sizeEstimator struct {
+		sampler      *BlockSampler
+		session      chainstorage.Session
+		table        string
+		estimateMode constant.EstimateMode
+		schema       *arrow.Schema
+		transformer  BatchTransformer
+
+		// The estimate of the whole table is refreshed in the background.
+		tableMu         sync.Mutex
+		tableEstimate   *Estimate
+		tableErr        error
+		tableUpdatedAt  time.Time
+		tableRefreshing bool
+	}
+)
+
+func newSizeEstimator(sampler *BlockSampler, table string, estimateMode constant.EstimateMode, schema *arrow.Schema, transformer BatchTransformer) *sizeEstimator {
+	return &sizeEstimator{
+		sampler:      sampler,
+		session:      sampler.session,
+		table:        table,
+		estimateMode: estimateMode,
+		schema:       schema,
+		transformer:  transformer,
+	}
+}
+
+// newSampleQuery returns the query sampled to estimate the size of the queries with the given columns and filter.
+func (e *sizeEstimator) newSampleQuery(columns []string, filter *api.GetFlightInfoCmd_Filter) (*sampleQuery, error) {
+	schema, projection, err := xarrow.ProjectSchema(e.schema, columns)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid columns(%v): %v: %w", columns, err, errors.ErrInvalidArgument)
+	}
+
+	parsedFilter, err := parseFilter(e.transformer, filter)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to parse filter: %w", err)
+	}
+
+	var filterBytes []byte
+	if !IsEmptyFilter(filter) {
+		filterBytes, err = proto.MarshalOptions{Deterministic: true}.Marshal(filter)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to marshal filter(%+v): %w", filter, err)
+		}
+	}
+
+	return &sampleQuery{
+		key:        fmt.Sprintf("%q/%x", columns, filterBytes),
+		schema:     schema,
+		projection: projection,
+		filter:     parsedFilter,
+	}, nil
+}
+
+// estimate returns the estimated size of each range.
+func (e *sizeEstimator) estimate(ctx context.Context, query *sampleQuery, ranges []heightRange) ([]*Estimate, error) {
+	if len(ranges) == 0 {
+		return nil, nil
+	}
+
+	span := ranges[0]
+	for _, r := range ranges {
+		if r.start < span.start {
+			span.start = r.start
+		}
+		if r.end > span.end {
+			span.end = r.end
+		}
+	}
+
+	sizes, err := e.getBlockSizes(ctx, query, span)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get block sizes: %w", err)
+	}
+
+	estimates := make([]*Estimate, len(ranges))
+	for i, r := range ranges {
+		estimates[i] = sizes.estimate(r)
+	}
+
+	return estimates, nil
+}
+
+// estimateTable returns the last estimate of the whole table, from the start of the chain to the tip.
+// The estimate is refreshed in the background once stale, so that the callers never wait for the blocks to be sampled.
+// It is nil until the first refresh completes.
+func (e *sizeEstimator) estimateTable() (*Estimate, error) {
+	e.tableMu.Lock()
+	defer e.tableMu.Unlock()
+
+	if !e.tableRefreshing && time.Since(e.tableUpdatedAt) >= tableEstimateInterval {
+		e.tableRefreshing = true
+		go e.refreshTableEstimate()
+	}
+
+	return e.tableEstimate, e.tableErr
+}
+
+func (e *sizeEstimator) refreshTableEstimate() {
+	ctx, cancel := context.WithTimeout(context.Background(), tableEstimateTimeout)
+	defer cancel()
+
+	estimate, err := e.estimateAvailableRange(ctx)
+
+	e.tableMu.Lock()
+	defer e.tableMu.Unlock()
+	if err != nil {
+		e.tableErr = xerrors.Errorf("failed to estimate table: %w", err)
+	} else {
+		e.tableEstimate, e.tableErr = estimate, nil
+	}
+	e.tableUpdatedAt = time.Now()
+	e.tableRefreshing = false
+}
+
+// estimateAvailableRange returns the estimated size of the whole table, with all its columns and no filter.
+func (e *sizeEstimator) estimateAvailableRange(ctx context.Context) (*Estimate, error) {
+	query, err := e.newSampleQuery(nil, nil)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create sample query: %w", err)
+	}
+
+	available, err := e.getAvailableRange(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get available range: %w", err)
+	}
+
+	if available.start >= available.end {
+		return &Estimate{}, nil
+	}
+
+	estimates, err := e.estimate(ctx, query, []heightRange{available})
+	if err != nil {
+		return nil, err
+	}
+
+	return estimates[0], nil
+}
+
+// getBlockSizes returns the size of the blocks of every bucket overlapping the range.
+// The buckets not sampled yet are sampled.
+func (e *sizeEstimator) getBlockSizes(ctx context.Context, query *sampleQuery, r heightRange) (*blockSizes, error) {
+	return e.collectBlockSizes(ctx, query, r, func(ctx context.Context, key sampleKey) (*blockSize, error) {
+		return e.sampler.getBlockSize(ctx, key, func(ctx context.Context) (*blockSize, error) {
+			return e.sampleSegment(ctx, query, key.segment)
+		})
+	})
+}
+
+// getCachedBlockSizes is like getBlockSizes, but never samples the blocks.
+// It fails with errBlockSizeNotCached if a bucket of the range has not been sampled yet.
+func (e *sizeEstimator) getCachedBlockSizes(ctx context.Context, query *sampleQuery, r heightRange) (*blockSizes, error) {
+	return e.collectBlockSizes(ctx, query, r, func(ctx context.Context, key sampleKey) (*blockSize, error) {
+		size, ok := e.sampler.getCachedBlockSize(key)
+		if !ok {
+			return nil, errBlockSizeNotCached
+		}
+
+		return size, nil
+	})
+}
+
+// collectBlockSizes returns the size of the blocks of every bucket overlapping the range, as returned by getSize.
+func (e *sizeEstimator) collectBlockSizes(
+	ctx context.Context,
+	query *sampleQuery,
+	r heightRange,
+	getSize func(ctx context.Context, key sampleKey) (*blockSize, error),
+) (*blockSizes, error) {
+	available, err := e.getAvailableRange(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get available range: %w", err)
+	}
+
+	sizes := &blockSizes{
+		bucketSize: getBucketSize(r),
+		sizes:      make(map[uint64]*blockSize),
+	}
+	var buckets []uint64
+	for bucket := r.start / sizes.bucketSize; bucket*sizes.bucketSize < r.end; bucket++ {
+		buckets = append(buckets, bucket)
+	}
+
+	sampled := make([]*blockSize, len(buckets))
+	group, ctx := syncgroup.New(ctx, syncgroup.WithThrottling(estimateParallelism))
+	for i, bucket := range buckets {
+		i, bucket := i, bucket
+		group.Go(func() error {
+			segment := getSampleSegment(sizes.bucketSize, bucket, available, r)
+			if segment.start >= segment.end {
+				sampled[i] = &blockSize{}
+				return nil
+			}
+
+			size, err := getSize(ctx, sampleKey{table: e.table, query: query.key, segment: segment})
+			if err != nil {
+				return xerrors.Errorf("failed to sample bucket(%d): %w", bucket, err)
+			}
+
+			sampled[i] = size
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, xerrors.Errorf("failed to sample buckets: %w", err)
+	}
+
+	for i, bucket := range buckets {
+		sizes.sizes[bucket] = sampled[i]
+	}
+
+	return sizes, nil
+}
+
+// getBucketSize returns the smallest bucket size, doubling estimateBucketSize,
+// such that the range overlaps at most maxEstimateBuckets buckets.
+func getBucketSize(r heightRange) uint64 {
+	bucketSize := estimateBucketSize
+	for r.end > r.start && (r.end-1)/bucketSize-r.start/bucketSize+1 > maxEstimateBuckets {
+		bucketSize *= 2
+	}
+
+	return bucketSize
+}
+
+// getAvailableRange returns the heights which may be sampled, from the start of the chain to the tip.
+func (e *sizeEstimator) getAvailableRange(ctx context.Context) (heightRange, error) {
+	meta, err := e.session.GetStaticChainMetadata(ctx, &chainstorageapi.GetChainMetadataRequest{})
+	if err != nil {
+		return heightRange{}, xerrors.Errorf("failed to get chain metadata: %w", err)
+	}
+
+	tipHeight, err := e.session.GetTipHeight(ctx)
+	if err != nil {
+		return heightRange{}, xerrors.Errorf("failed to get tip height: %w", err)
+	}
+
+	return heightRange{start: meta.GetBlockStartHeight(), end: tipHeight}, nil
+}
+
+// estimate returns the estimated size of the range, whose buckets must all have a size.
+func (s *blockSizes) estimate(r heightRange) *Estimate {
+	var records, bytes float64
+	for bucket := r.start / s.bucketSize; bucket*s.bucketSize < r.end; bucket++ {
+		segment := r.intersect(s.bucketSize, bucket)
+		size := s.sizes[bucket]
+		records += size.records * float64(segment.end-segment.start)
+		bytes += size.bytes * float64(segment.end-segment.start)
+	}
+
+	return &Estimate{
+		TotalRecords: int64(records + 0.5),
+		TotalBytes:   int64(bytes + 0.5),
+	}
+}
+
+// plan splits the range into consecutive ranges of about target records, or target bytes if byBytes is set.
+// It fails if the plan results in more than maxRanges ranges.
+func (s *blockSizes) plan(r heightRange, target uint64, byBytes bool, maxRanges int) ([]heightRange, error) {
+	var ranges []heightRange
+	start := r.start
+	accumulated := float64(0)
+	for bucket := r.start / s.bucketSize; bucket*s.bucketSize < r.end; bucket++ {
+		segment := r.intersect(s.bucketSize, bucket)
+		perBlock := s.sizes[bucket].records
+		if byBytes {
+			perBlock = s.sizes[bucket].bytes
+		}
+
+		// Blocks without rows never fill a partition.
+		for perBlock > 0 && segment.start < segment.end {
+			numBlocks := uint64(math.Ceil((float64(target) - accumulated) / perBlock))
+			if numBlocks == 0 {
+				numBlocks = 1
+			}
+
+			if segment.start+numBlocks > segment.end {
+				accumulated += perBlock * float64(segment.end-segment.start)
+				break
+			}
+
+			segment.start += numBlocks
+			ranges = append(ranges, heightRange{start: start, end: segment.start})
+			if len(ranges) > maxRanges {
+				return nil, xerrors.Errorf("plan resulted in more than %d partitions", maxRanges)
+			}
+
+			start = segment.start
+			accumulated = 0
+		}
+	}
+
+	if start < r.end {
+		ranges = append(ranges, heightRange{start: start, end: r.end})
+		if len(ranges) > maxRanges {
+			return nil, xerrors.Errorf("plan resulted in more than %d partitions", maxRanges)
+		}
+	}
+
+	return ranges, nil
+}
+
+// intersect returns the part of the range within the bucket.
+func (r heightRange) intersect(bucketSize uint64, bucket uint64) heightRange {
+	res := bucketRange(bucketSize, bucket)
+	if res.start < r.start {
+		res.start = r.start
+	}
+	if res.end > r.end {
+		res.end = r.end
+	}
+
+	return res
+}
+
+// bucketRange returns the heights of the bucket.
+func bucketRange(bucketSize uint64, bucket uint64) heightRange {
+	return heightRange{start: bucket * bucketSize, end: (bucket + 1) * bucketSize}
+}
+
+// getSampleSegment returns the heights of the bucket sampled to estimate the range, which are below the tip.
+// The bucket crossing the tip is only sampled up to the end of the range, so that the estimate of a range
+// does not depend on the tip. Its end is rounded down to a multiple of the bucket size / estimateSegmentSteps,
+// so that the sampled segments are shared by the queries near the tip as the tip moves.
+func getSampleSegment(bucketSize uint64, bucket uint64, available heightRange, r heightRange) heightRange {
+	segment := available.intersect(bucketSize, bucket)
+	bucketStart := bucket * bucketSize
+	if segment.end == bucketStart+bucketSize {
+		return segment
+	}
+
+	if segment.end > r.end {
+		segment.end = r.end
+	}
+
+	step := bucketSize / estimateSegmentSteps
+	if end := bucketStart + (segment.end-bucketStart)/step*step; end > segment.start {
+		segment.end = end
+	}
+
+	return segment
+}
+
+// sampleSegment returns the average size of the blocks sampled over the segment.
+// The skipped blocks are sampled as empty.
+func (e *sizeEstimator) sampleSegment(ctx context.Context, query *sampleQuery, segment heightRange) (*blockSize, error) {
+	heights := sampleHeights(segment.start, segment.end, estimateSamplesPerBucket)
+	sampled := make([]*blockSize, len(heights))
+	group, ctx := syncgroup.New(ctx, syncgroup.WithThrottling(estimateParallelism))
+	for i, height := range heights {
+		i, height := i, height
+		group.Go(func() error {
+			size, err := e.sampleBlock(ctx, query, height)
+			if err != nil {
+				return xerrors.Errorf("failed to sample block(%d): %w", height, err)
+			}
+
+			sampled[i] = size
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	size := &blockSize{}
+	if len(sampled) == 0 {
+		return size, nil
+	}
+
+	for _, s := range sampled {
+		size.records += s.records
+		size.bytes += s.bytes
+	}
+
+	size.records /= float64(len(sampled))
+	size.bytes /= float64(len(sampled))
+	return size, nil
+}
+
+// sampleBlock transforms the block at the height with the columns and filter of the query,
+// and returns the number of records and bytes it produced.
+func (e *sizeEstimator) sampleBlock(ctx context.Context, query *sampleQuery, height uint64) (*blockSize, error) {
+	block, err := e.session.Client().GetBlock(ctx, height, "")
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get block: %w", err)
+	}
+
+	if block.GetMetadata().GetSkipped() {
+		return &blockSize{}, nil
+	}
+
+	// The native block parsed by the transformer is reused to count the transactions of the block.
+	parser := &nativeBlockParser{Parser: e.session.Parser()}
+	recordBuilder := xarrow.NewRecordBuilder(memory.DefaultAllocator, query.schema, query.projection)
+	defer recordBuilder.Release()
+	if err := e.transformer.TransformBlock(ctx, block, parser, recordBuilder, query.filter, nil); err != nil {
+		return nil, xerrors.Errorf("failed to transform block: %w", err)
+	}
+
+	rec := recordBuilder.NewRecord()
+	defer rec.Release()
+	size := &blockSize{
+		records: float64(rec.NumRows()),
+		bytes:   float64(xarrow.RecordSize(rec)),
+	}
+
+	// The estimate mode counts the records of the unfiltered queries from the block metadata.
+	if query.filter == nil {
+		switch e.estimateMode {
+		case constant.EstimateModeBlocks:
+			size.records = 1
+		case constant.EstimateModeTransactions:
+			nativeBlock, err := parser.ParseNativeBlock(ctx, block)
+			if err != nil {
+				return nil, xerrors.Errorf("failed to parse native block: %w", err)
+			}
+
+			size.records = float64(nativeBlock.GetNumTransactions())
+		}
+	}
+
+	return size, nil
+}
+
+// ParseNativeBlock parses the native block once, and returns the same native block to the later calls.
+func (p *nativeBlockParser) ParseNativeBlock(ctx context.Context, rawBlock *chainstorageapi.Block) (*chainstorageapi.NativeBlock, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.nativeBlock != nil {
+		return p.nativeBlock, nil
+	}
+
+	nativeBlock, err := p.Parser.ParseNativeBlock(ctx, rawBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	p.nativeBlock = nativeBlock
+	return nativeBlock, nil
+}
+
+// sampleHeights returns up to n heights evenly spread over [start, end).
+func sampleHeights(start uint64, end uint64, n uint64) []uint64 {
+	if end <= start {
+		return nil
+	}
+
+	if end-start < n {
+		n = end - start
+	}
+
+	heights := make([]uint64, n)
+	for i := range heights {
+		heights[i] = start + (end-start)*(2*uint64(i)+1)/(2*n)
+	}
+
+	return heights
+}
@@ -0,0 +1,549 @@
+package internal
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v10/arrow"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/xerrors"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+	"github.com/coinbase/chainstorage/sdk"
+	sdkmocks "github.com/coinbase/chainstorage/sdk/mocks"
+
+	"github.com/coinbase/chainsformer/internal/chainstorage"
+	csmocks "github.com/coinbase/chainsformer/internal/chainstorage/mocks"
+	"github.com/coinbase/chainsformer/internal/config"
+	"github.com/coinbase/chainsformer/internal/controller/internal/constant"
+	"github.com/coinbase/chainsformer/internal/errors"
+	"github.com/coinbase/chainsformer/internal/utils/fxparams"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+	api "github.com/coinbase/chainsformer/protos/coinbase/chainsformer"
+)
+
+const testBlockHash = "0x2b1d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c2d"
+
+type (
+	// testRowsPerBlockTable appends rowsPerBlock rows per block,
+	// or as many rows as the transaction types of the filter if the query has one.
+	testRowsPerBlockTable struct {
+		rowsPerBlock  int
+		filtersParsed *int32
+		// parseNativeBlock makes the transform parse the native block, like the native tables.
+		parseNativeBlock bool
+	}
+
+	// testRowsFilter is the filter parsed by testRowsPerBlockTable.
+	testRowsFilter struct {
+		rowsPerBlock int
+	}
+)
+
+func TestSizeEstimator(t *testing.T) {
+	require := require.New(t)
+
+	session, client, _ := newTestEstimatorSession(t, 10*estimateBucketSize)
+	var numSamples int32
+	client.EXPECT().GetBlock(gomock.Any(), gomock.Any(), "").
+		DoAndReturn(func(ctx context.Context, height uint64, hash string) (*chainstorageapi.Block, error) {
+			atomic.AddInt32(&numSamples, 1)
+			return &chainstorageapi.Block{
+				Metadata: &chainstorageapi.BlockMetadata{Height: height},
+			}, nil
+		}).AnyTimes()
+
+	estimator := newSizeEstimator(newTestBlockSampler(t, session), "test", constant.EstimateModeSampled, newTestRowsPerBlockSchema(), testRowsPerBlockTable{rowsPerBlock: 3})
+	query := newTestSampleQuery(t, estimator, nil, nil)
+	ranges := []heightRange{
+		{start: 0, end: 100},
+		{start: 100, end: estimateBucketSize + 50},
+	}
+	estimates, err := estimator.estimate(context.Background(), query, ranges)
+	require.NoError(err)
+	require.Equal(2, len(estimates))
+	require.Equal(int64(300), estimates[0].TotalRecords)
+	require.Equal(int64(3*(estimateBucketSize+50-100)), estimates[1].TotalRecords)
+	require.GreaterOrEqual(estimates[0].TotalBytes, estimates[0].TotalRecords*8)
+	require.Equal(int32(2*estimateSamplesPerBucket), atomic.LoadInt32(&numSamples))
+
+	// The sizes are cached per bucket.
+	estimates, err = estimator.estimate(context.Background(), query, ranges[:1])
+	require.NoError(err)
+	require.Equal(int64(300), estimates[0].TotalRecords)
+	require.Equal(int32(2*estimateSamplesPerBucket), atomic.LoadInt32(&numSamples))
+}
+
+func TestSizeEstimator_Query(t *testing.T) {
+	require := require.New(t)
+
+	session, client, _ := newTestEstimatorSession(t, 10*estimateBucketSize)
+	client.EXPECT().GetBlock(gomock.Any(), gomock.Any(), "").
+		DoAndReturn(func(ctx context.Context, height uint64, hash string) (*chainstorageapi.Block, error) {
+			return &chainstorageapi.Block{
+				Metadata: &chainstorageapi.BlockMetadata{Height: height, Hash: testBlockHash},
+			}, nil
+		}).Times(3 * estimateSamplesPerBucket)
+
+	sampler := newTestBlockSampler(t, session)
+	estimator := newSizeEstimator(sampler, "test", constant.EstimateModeTransactions, newTestRowsPerBlockSchema(), testRowsPerBlockTable{rowsPerBlock: 3})
+	r := []heightRange{{start: 0, end: 100}}
+	filter := &api.GetFlightInfoCmd_Filter{TransactionTypes: []uint64{1, 2}}
+	filtered, err := estimator.estimate(context.Background(), newTestSampleQuery(t, estimator, nil, filter), r)
+	require.NoError(err)
+	// The records of the filtered queries are counted from the transformed blocks.
+	require.Equal(int64(200), filtered[0].TotalRecords)
+
+	projected, err := estimator.estimate(context.Background(), newTestSampleQuery(t, estimator, []string{"height"}, filter), r)
+	require.NoError(err)
+	require.Equal(int64(200), projected[0].TotalRecords)
+	require.Less(projected[0].TotalBytes, filtered[0].TotalBytes)
+
+	// The sizes are cached by columns and filter.
+	projected, err = estimator.estimate(context.Background(), newTestSampleQuery(t, estimator, []string{"height"}, &api.GetFlightInfoCmd_Filter{TransactionTypes: []uint64{1}}), r)
+	require.NoError(err)
+	require.Equal(int64(100), projected[0].TotalRecords)
+	require.Equal(3, sampler.sizes.Len())
+
+	_, err = estimator.newSampleQuery([]string{"unknown"}, nil)
+	require.Error(err)
+	require.True(xerrors.Is(err, errors.ErrInvalidArgument))
+}
+
+func TestSizeEstimator_EstimateModes(t *testing.T) {
+	require := require.New(t)
+
+	session, client, parser := newTestEstimatorSession(t, 10*estimateBucketSize)
+	client.EXPECT().GetBlock(gomock.Any(), gomock.Any(), "").
+		DoAndReturn(func(ctx context.Context, height uint64, hash string) (*chainstorageapi.Block, error) {
+			return &chainstorageapi.Block{
+				Metadata: &chainstorageapi.BlockMetadata{Height: height},
+			}, nil
+		}).AnyTimes()
+	// Only the unfiltered queries of the transactions mode count the transactions of the blocks.
+	parser.EXPECT().ParseNativeBlock(gomock.Any(), gomock.Any()).
+		Return(&chainstorageapi.NativeBlock{NumTransactions: 5}, nil).
+		Times(estimateSamplesPerBucket)
+
+	sampler := newTestBlockSampler(t, session)
+	r := []heightRange{{start: 0, end: 100}}
+	blocksEstimator := newSizeEstimator(sampler, "blocks", constant.EstimateModeBlocks, newTestRowsPerBlockSchema(), testRowsPerBlockTable{rowsPerBlock: 3})
+	estimates, err := blocksEstimator.estimate(context.Background(), newTestSampleQuery(t, blocksEstimator, nil, nil), r)
+	require.NoError(err)
+	require.Equal(int64(100), estimates[0].TotalRecords)
+	require.Greater(estimates[0].TotalBytes, int64(0))
+
+	transactionsEstimator := newSizeEstimator(sampler, "transactions", constant.EstimateModeTransactions, newTestRowsPerBlockSchema(), testRowsPerBlockTable{rowsPerBlock: 3})
+	estimates, err = transactionsEstimator.estimate(context.Background(), newTestSampleQuery(t, transactionsEstimator, nil, nil), r)
+	require.NoError(err)
+	require.Equal(int64(500), estimates[0].TotalRecords)
+	require.Equal(2, sampler.sizes.Len())
+}
+
+func TestSizeEstimator_TransactionsModeParsesOnce(t *testing.T) {
+	require := require.New(t)
+
+	session, client, parser := newTestEstimatorSession(t, 10*estimateBucketSize)
+	client.EXPECT().GetBlock(gomock.Any(), gomock.Any(), "").
+		DoAndReturn(func(ctx context.Context, height uint64, hash string) (*chainstorageapi.Block, error) {
+			return &chainstorageapi.Block{
+				Metadata: &chainstorageapi.BlockMetadata{Height: height},
+			}, nil
+		}).AnyTimes()
+	// The native block parsed by the transform is reused to count the transactions.
+	parser.EXPECT().ParseNativeBlock(gomock.Any(), gomock.Any()).
+		Return(&chainstorageapi.NativeBlock{NumTransactions: 5}, nil).
+		Times(estimateSamplesPerBucket)
+
+	estimator := newSizeEstimator(newTestBlockSampler(t, session), "transactions", constant.EstimateModeTransactions, newTestRowsPerBlockSchema(), testRowsPerBlockTable{rowsPerBlock: 3, parseNativeBlock: true})
+	estimates, err := estimator.estimate(context.Background(), newTestSampleQuery(t, estimator, nil, nil), []heightRange{{start: 0, end: 100}})
+	require.NoError(err)
+	require.Equal(int64(500), estimates[0].TotalRecords)
+}
+
+func TestSizeEstimator_BucketCrossingTip(t *testing.T) {
+	require := require.New(t)
+
+	tipHeight := uint64(50)
+	ctrl := gomock.NewController(t)
+	session := csmocks.NewMockSession(ctrl)
+	client := sdkmocks.NewMockClient(ctrl)
+	session.EXPECT().Client().Return(client).AnyTimes()
+	session.EXPECT().Parser().Return(sdkmocks.NewMockParser(ctrl)).AnyTimes()
+	session.EXPECT().GetStaticChainMetadata(gomock.Any(), gomock.Any()).
+		Return(&chainstorageapi.GetChainMetadataResponse{}, nil).AnyTimes()
+	session.EXPECT().GetTipHeight(gomock.Any()).DoAndReturn(func(ctx context.Context) (uint64, error) {
+		return atomic.LoadUint64(&tipHeight), nil
+	}).AnyTimes()
+	var mu sync.Mutex
+	var heights []uint64
+	client.EXPECT().GetBlock(gomock.Any(), gomock.Any(), "").
+		DoAndReturn(func(ctx context.Context, height uint64, hash string) (*chainstorageapi.Block, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			heights = append(heights, height)
+			return &chainstorageapi.Block{
+				Metadata: &chainstorageapi.BlockMetadata{Height: height},
+			}, nil
+		}).AnyTimes()
+
+	sampler := newTestBlockSampler(t, session)
+	estimator := newSizeEstimator(sampler, "test", constant.EstimateModeSampled, newTestRowsPerBlockSchema(), testRowsPerBlockTable{rowsPerBlock: 1})
+	query := newTestSampleQuery(t, estimator, nil, nil)
+	for i := 0; i < 2; i++ {
+		// The bucket is only sampled up to the end of the range, which is below the tip and cached.
+		estimates, err := estimator.estimate(context.Background(), query, []heightRange{{start: 0, end: 30}})
+		require.NoError(err)
+		require.Equal(int64(30), estimates[0].TotalRecords)
+		require.Equal(1, sampler.sizes.Len())
+	}
+	require.ElementsMatch([]uint64{7, 22}, heights)
+
+	// The queries up to the tip share the same segment until the tip crosses the next step.
+	step := estimateBucketSize / estimateSegmentSteps
+	for _, tip := range []uint64{2*step + 10, 2*step + 20, 3*step - 1} {
+		atomic.StoreUint64(&tipHeight, tip)
+		_, err := estimator.estimate(context.Background(), query, []heightRange{{start: 0, end: tip}})
+		require.NoError(err)
+		require.Equal(2, sampler.sizes.Len())
+	}
+	require.Equal(2+estimateSamplesPerBucket, len(heights))
+	for _, height := range heights[2:] {
+		require.Less(height, 2*step)
+	}
+
+	atomic.StoreUint64(&tipHeight, 3*step)
+	_, err := estimator.estimate(context.Background(), query, []heightRange{{start: 0, end: 3 * step}})
+	require.NoError(err)
+	require.Equal(3, sampler.sizes.Len())
+}
+
+func TestSizeEstimator_AllBucketsSampled(t *testing.T) {
+	require := require.New(t)
+
+	// The bucket size is doubled 4 times to cover the range with 10 / 16 * maxEstimateBuckets buckets.
+	endHeight := 10 * maxEstimateBuckets * estimateBucketSize
+	numBuckets := 10 * maxEstimateBuckets / 16
+	session, client, parser := newTestEstimatorSession(t, endHeight)
+	client.EXPECT().GetBlock(gomock.Any(), gomock.Any(), "").
+		DoAndReturn(func(ctx context.Context, height uint64, hash string) (*chainstorageapi.Block, error) {
+			return &chainstorageapi.Block{
+				Metadata: &chainstorageapi.BlockMetadata{Height: height},
+			}, nil
+		}).AnyTimes()
+	parser.EXPECT().ParseNativeBlock(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, block *chainstorageapi.Block) (*chainstorageapi.NativeBlock, error) {
+			// The number of transactions grows with the height.
+			return &chainstorageapi.NativeBlock{NumTransactions: block.GetMetadata().GetHeight() / (16 * estimateBucketSize)}, nil
+		}).
+		Times(numBuckets * estimateSamplesPerBucket)
+
+	sampler := newTestBlockSampler(t, session)
+	estimator := newSizeEstimator(sampler, "test", constant.EstimateModeTransactions, newTestRowsPerBlockSchema(), testRowsPerBlockTable{rowsPerBlock: 1})
+	r := heightRange{start: 0, end: endHeight}
+	query := newTestSampleQuery(t, estimator, nil, nil)
+	sizes, err := estimator.getBlockSizes(context.Background(), query, r)
+	require.NoError(err)
+	require.Equal(16*estimateBucketSize, sizes.bucketSize)
+	require.Equal(numBuckets, len(sizes.sizes))
+	require.Equal(numBuckets, sampler.sizes.Len())
+	for bucket, size := range sizes.sizes {
+		require.Equal(float64(bucket), size.records)
+	}
+
+	// The plan of the same range is the same once the sizes are cached.
+	expected, err := sizes.plan(r, 1000000, false, maxNumOfEndpoints)
+	require.NoError(err)
+	sizes, err = estimator.getBlockSizes(context.Background(), query, r)
+	require.NoError(err)
+	actual, err := sizes.plan(r, 1000000, false, maxNumOfEndpoints)
+	require.NoError(err)
+	require.Equal(expected, actual)
+}
+
+func TestSizeEstimator_SkippedBlocks(t *testing.T) {
+	require := require.New(t)
+
+	session, client, _ := newTestEstimatorSession(t, 6)
+	client.EXPECT().GetBlock(gomock.Any(), gomock.Any(), "").
+		DoAndReturn(func(ctx context.Context, height uint64, hash string) (*chainstorageapi.Block, error) {
+			return &chainstorageapi.Block{
+				Metadata: &chainstorageapi.BlockMetadata{Height: height, Skipped: height%2 == 1},
+			}, nil
+		}).AnyTimes()
+
+	estimator := newSizeEstimator(newTestBlockSampler(t, session), "test", constant.EstimateModeBlocks, newTestRowsPerBlockSchema(), testRowsPerBlockTable{rowsPerBlock: 2})
+	// The sampled heights are 1 and 3.
+	estimates, err := estimator.estimate(context.Background(), newTestSampleQuery(t, estimator, nil, nil), []heightRange{{start: 0, end: 4}})
+	require.NoError(err)
+	require.Equal(int64(0), estimates[0].TotalRecords)
+	require.Equal(int64(0), estimates[0].TotalBytes)
+}
+
+func TestSizeEstimator_EstimateTable(t *testing.T) {
+	require := require.New(t)
+
+	session, client, parser := newTestEstimatorSession(t, 100)
+	client.EXPECT().GetBlock(gomock.Any(), gomock.Any(), "").
+		DoAndReturn(func(ctx context.Context, height uint64, hash string) (*chainstorageapi.Block, error) {
+			return &chainstorageapi.Block{
+				Metadata: &chainstorageapi.BlockMetadata{Height: height},
+			}, nil
+		}).AnyTimes()
+	parser.EXPECT().ParseNativeBlock(gomock.Any(), gomock.Any()).
+		Return(&chainstorageapi.NativeBlock{}, nil).
+		AnyTimes()
+
+	estimator := newSizeEstimator(newTestBlockSampler(t, session), "test", constant.EstimateModeBlocks, newTestRowsPerBlockSchema(), testRowsPerBlockTable{rowsPerBlock: 1})
+	// The first call does not wait for the estimate.
+	estimate, err := estimator.estimateTable()
+	require.NoError(err)
+	require.Nil(estimate)
+
+	require.Eventually(func() bool {
+		estimate, err = estimator.estimateTable()
+		return estimate != nil || err != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(err)
+	require.Equal(int64(100), estimate.TotalRecords)
+}
+
+func TestBlockSampler_CanceledCaller(t *testing.T) {
+	require := require.New(t)
+
+	sampler := newTestBlockSampler(t, nil)
+	key := sampleKey{table: "test", segment: heightRange{start: 0, end: 10}}
+	started := make(chan struct{})
+	release := make(chan struct{})
+	sample := func(ctx context.Context) (*blockSize, error) {
+		close(started)
+		<-release
+		// The shared sample is not canceled by the caller which started it.
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		return &blockSize{records: 1}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := sampler.getBlockSize(ctx, key, sample)
+		errCh <- err
+	}()
+	<-started
+	cancel()
+	require.ErrorIs(<-errCh, context.Canceled)
+
+	close(release)
+	size, err := sampler.getBlockSize(context.Background(), key, sample)
+	require.NoError(err)
+	require.Equal(float64(1), size.records)
+	require.Equal(1, sampler.sizes.Len())
+}
+
+func TestBlockSampler_FailedSampleNotCached(t *testing.T) {
+	require := require.New(t)
+
+	sampler := newTestBlockSampler(t, nil)
+	key := sampleKey{table: "test", segment: heightRange{start: 0, end: 10}}
+	_, err := sampler.getBlockSize(context.Background(), key, func(ctx context.Context) (*blockSize, error) {
+		return nil, context.DeadlineExceeded
+	})
+	require.ErrorIs(err, context.DeadlineExceeded)
+	require.Equal(0, sampler.sizes.Len())
+
+	_, ok := sampler.getCachedBlockSize(key)
+	require.False(ok)
+}
+
+func TestBlockSizes_Plan(t *testing.T) {
+	sizes := &blockSizes{
+		bucketSize: estimateBucketSize,
+		sizes: map[uint64]*blockSize{
+			0: {records: 1, bytes: 100},
+			1: {records: 10, bytes: 1000},
+			2: {records: 0, bytes: 0},
+		},
+	}
+	tests := []struct {
+		name      string
+		r         heightRange
+		target    uint64
+		byBytes   bool
+		maxRanges int
+		expected  []heightRange
+		valid     bool
+	}{
+		{
+			name:      "rows",
+			r:         heightRange{start: 0, end: 30},
+			target:    10,
+			maxRanges: 10,
+			expected: []heightRange{
+				{start: 0, end: 10},
+				{start: 10, end: 20},
+				{start: 20, end: 30},
+			},
+			valid: true,
+		},
+		{
+			name:      "bytes",
+			r:         heightRange{start: 0, end: 30},
+			target:    1500,
+			byBytes:   true,
+			maxRanges: 10,
+			expected: []heightRange{
+				{start: 0, end: 15},
+				{start: 15, end: 30},
+			},
+			valid: true,
+		},
+		{
+			name:      "across_buckets",
+			r:         heightRange{start: estimateBucketSize - 5, end: estimateBucketSize + 5},
+			target:    15,
+			maxRanges: 10,
+			expected: []heightRange{
+				{start: estimateBucketSize - 5, end: estimateBucketSize + 1},
+				{start: estimateBucketSize + 1, end: estimateBucketSize + 3},
+				{start: estimateBucketSize + 3, end: estimateBucketSize + 5},
+			},
+			valid: true,
+		},
+		{
+			name:      "empty_blocks",
+			r:         heightRange{start: 2*estimateBucketSize - 1, end: 2*estimateBucketSize + 100},
+			target:    15,
+			maxRanges: 10,
+			expected: []heightRange{
+				{start: 2*estimateBucketSize - 1, end: 2*estimateBucketSize + 100},
+			},
+			valid: true,
+		},
+		{
+			name:      "too_many_ranges",
+			r:         heightRange{start: 0, end: 30},
+			target:    1,
+			maxRanges: 10,
+			valid:     false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			actual, err := sizes.plan(test.r, test.target, test.byBytes, test.maxRanges)
+			if !test.valid {
+				require.Error(err)
+				return
+			}
+
+			require.NoError(err)
+			require.Equal(test.expected, actual)
+		})
+	}
+}
+
+func TestSampleHeights(t *testing.T) {
+	require := require.New(t)
+	require.Equal([]uint64{16, 50, 83}, sampleHeights(0, 100, 3))
+	require.Equal([]uint64{10, 11}, sampleHeights(10, 12, 3))
+	require.Empty(sampleHeights(10, 10, 3))
+}
+
+func TestGetSampleSegment(t *testing.T) {
+	require := require.New(t)
+	step := estimateBucketSize / estimateSegmentSteps
+	available := heightRange{start: 10, end: estimateBucketSize + 3*step + 10}
+	// The whole buckets below the tip are sampled over their available heights.
+	require.Equal(heightRange{start: 10, end: estimateBucketSize}, getSampleSegment(estimateBucketSize, 0, available, heightRange{start: 20, end: 30}))
+	// The bucket crossing the tip is sampled up to the end of the range, rounded down to a step.
+	require.Equal(heightRange{start: estimateBucketSize, end: estimateBucketSize + 3*step}, getSampleSegment(estimateBucketSize, 1, available, heightRange{start: 0, end: 2 * estimateBucketSize}))
+	require.Equal(heightRange{start: estimateBucketSize, end: estimateBucketSize + step}, getSampleSegment(estimateBucketSize, 1, available, heightRange{start: 0, end: estimateBucketSize + 2*step - 1}))
+	// The ranges shorter than a step are sampled exactly.
+	require.Equal(heightRange{start: estimateBucketSize, end: estimateBucketSize + 5}, getSampleSegment(estimateBucketSize, 1, available, heightRange{start: 0, end: estimateBucketSize + 5}))
+	// The buckets past the tip are empty.
+	segment := getSampleSegment(estimateBucketSize, 2, available, heightRange{start: 0, end: 3 * estimateBucketSize})
+	require.GreaterOrEqual(segment.start, segment.end)
+}
+
+func TestGetBucketSize(t *testing.T) {
+	require := require.New(t)
+	require.Equal(estimateBucketSize, getBucketSize(heightRange{start: 0, end: 100}))
+	require.Equal(estimateBucketSize, getBucketSize(heightRange{start: 0, end: maxEstimateBuckets * estimateBucketSize}))
+	require.Equal(2*estimateBucketSize, getBucketSize(heightRange{start: 1, end: maxEstimateBuckets*estimateBucketSize + 1}))
+	require.Equal(16*estimateBucketSize, getBucketSize(heightRange{start: 0, end: 10 * maxEstimateBuckets * estimateBucketSize}))
+}
+
+func newTestEstimatorSession(t *testing.T, tipHeight uint64) (*csmocks.MockSession, *sdkmocks.MockClient, *sdkmocks.MockParser) {
+	ctrl := gomock.NewController(t)
+	session := csmocks.NewMockSession(ctrl)
+	client := sdkmocks.NewMockClient(ctrl)
+	parser := sdkmocks.NewMockParser(ctrl)
+	session.EXPECT().Client().Return(client).AnyTimes()
+	session.EXPECT().Parser().Return(parser).AnyTimes()
+	session.EXPECT().GetStaticChainMetadata(gomock.Any(), gomock.Any()).
+		Return(&chainstorageapi.GetChainMetadataResponse{}, nil).AnyTimes()
+	session.EXPECT().GetTipHeight(gomock.Any()).Return(tipHeight, nil).AnyTimes()
+	return session, client, parser
+}
+
+func newTestBlockSampler(t *testing.T, session chainstorage.Session) *BlockSampler {
+	sampler, err := NewBlockSampler(BlockSamplerParams{
+		Params:  fxparams.Params{Config: &config.Config{}},
+		Session: session,
+	})
+	require.NoError(t, err)
+	return sampler
+}
+
+func newTestRowsPerBlockSchema() *arrow.Schema {
+	f := xarrow.NewSchemaFactory()
+	return f.NewSchema(
+		f.NewField("height", arrow.PrimitiveTypes.Uint64, "The height"),
+		f.NewField("hash", arrow.BinaryTypes.String, "The hash"),
+	)
+}
+
+func newTestSampleQuery(t *testing.T, estimator *sizeEstimator, columns []string, filter *api.GetFlightInfoCmd_Filter) *sampleQuery {
+	query, err := estimator.newSampleQuery(columns, filter)
+	require.NoError(t, err)
+	return query
+}
+
+func (t testRowsPerBlockTable) ParseFilter(filter *api.GetFlightInfoCmd_Filter) (Filter, error) {
+	if t.filtersParsed != nil {
+		atomic.AddInt32(t.filtersParsed, 1)
+	}
+
+	return &testRowsFilter{rowsPerBlock: len(filter.GetTransactionTypes())}, nil
+}
+
+func (t testRowsPerBlockTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter Filter, partitioner *partition.Partitioner) error {
+	if t.parseNativeBlock {
+		if _, err := parser.ParseNativeBlock(ctx, block); err != nil {
+			return err
+		}
+	}
+
+	rowsPerBlock := t.rowsPerBlock
+	if f, ok := filter.(*testRowsFilter); ok {
+		rowsPerBlock = f.rowsPerBlock
+	}
+
+	for i := 0; i < rowsPerBlock; i++ {
+		xarrow.NewRecordAppender(recordBuilder).
+			AppendUint64(block.GetMetadata().GetHeight()).
+			AppendString(block.GetMetadata().GetHash()).
+			Build()
+	}
+
+	return nil
+}
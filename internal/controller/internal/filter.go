@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/xerrors"
+
+	"github.com/coinbase/chainsformer/internal/errors"
+	api "github.com/coinbase/chainsformer/protos/coinbase/chainsformer"
+)
+
+type (
+	// Filter is the filter of a query as parsed by the FilterParser of the transformer.
+	// It is opaque to the tables, and nil if the query does not restrict any row.
+	Filter interface{}
+
+	// FilterParser is implemented by the transformers supporting predicate pushdown.
+	// The filter is parsed once per query, and the parsed filter is passed to every call of TransformBlock.
+	// Tables whose transformer does not implement it reject any non-empty filter.
+	FilterParser interface {
+		ParseFilter(filter *api.GetFlightInfoCmd_Filter) (Filter, error)
+	}
+)
+
+// IsEmptyFilter returns true if the filter does not restrict any row.
+func IsEmptyFilter(filter *api.GetFlightInfoCmd_Filter) bool {
+	return filter == nil || proto.Size(filter) == 0
+}
+
+func parseFilter(transformer interface{}, filter *api.GetFlightInfoCmd_Filter) (Filter, error) {
+	if IsEmptyFilter(filter) {
+		return nil, nil
+	}
+
+	parser, ok := transformer.(FilterParser)
+	if !ok {
+		return nil, xerrors.Errorf("filter is not supported by the table: %w", errors.ErrInvalidArgument)
+	}
+
+	parsed, err := parser.ParseFilter(filter)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid filter(%+v): %w", filter, err)
+	}
+
+	return parsed, nil
+}
@@ -4,14 +4,18 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"time"
 
+	"github.com/apache/arrow/go/v10/arrow"
 	"github.com/apache/arrow/go/v10/arrow/flight"
 	"github.com/apache/arrow/go/v10/arrow/memory"
+	"github.com/uber-go/tally/v4"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 	"golang.org/x/xerrors"
 
 	"github.com/coinbase/chainsformer/internal/chainstorage"
+	"github.com/coinbase/chainsformer/internal/config"
 	"github.com/coinbase/chainsformer/internal/controller/internal/constant"
 	"github.com/coinbase/chainsformer/internal/errors"
 	"github.com/coinbase/chainsformer/internal/utils/finalizer"
@@ -27,6 +31,7 @@ const (
 	flightActionEarliest       = "EARLIEST"
 	flightStreamActionTip      = "STREAM_TIP"
 	flightStreamActionEarliest = "STREAM_EARLIEST"
+	flightActionHeightAtTime   = "HEIGHT_AT_TIME"
 )
 
 type (
@@ -42,8 +47,11 @@ type (
 	handler struct {
 		flight.BaseFlightServer
 		SerializedSchemas map[string][]byte
+		schemaVersion     string
 		tables            map[string]Table
 		logger            *zap.Logger
+		metrics           tally.Scope
+		config            *config.Config
 		csSession         chainstorage.Session
 	}
 )
@@ -72,7 +80,10 @@ func NewHandler(params HandlerParams) (Handler, error) {
 	h := Handler(&handler{
 		tables:            tableByName,
 		SerializedSchemas: serializedSchemas,
+		schemaVersion:     getSchemaVersion(serializedSchemas),
 		logger:            logger,
+		metrics:           scope,
+		config:            params.Config,
 		csSession:         params.CSSession,
 	})
 	h = withErrorInterceptor(h)
@@ -81,15 +92,26 @@ func NewHandler(params HandlerParams) (Handler, error) {
 }
 
 func (h *handler) ListFlights(c *flight.Criteria, fs flight.FlightService_ListFlightsServer) error {
-	for table, schema := range h.SerializedSchemas {
+	for table := range h.SerializedSchemas {
+		totalRecords, totalBytes := int64(-1), int64(-1)
+		if estimator, ok := h.tables[table].(SizeEstimator); ok {
+			// The estimates are best effort and must not fail the listing.
+			estimate, err := estimator.EstimateTable(fs.Context())
+			if err != nil {
+				h.logger.Warn("failed to estimate table", zap.String("table_name", table), zap.Error(err))
+			} else if estimate != nil {
+				totalRecords, totalBytes = estimate.TotalRecords, estimate.TotalBytes
+			}
+		}
+
 		err := fs.Send(&flight.FlightInfo{
-			Schema: schema,
+			Schema: h.getDefaultSerializedSchema(h.tables[table]),
 			FlightDescriptor: &flight.FlightDescriptor{
 				Type: flight.DescriptorPATH,
 				Path: []string{table},
 			},
-			TotalRecords: -1,
-			TotalBytes:   -1,
+			TotalRecords: totalRecords,
+			TotalBytes:   totalBytes,
 		})
 		if err != nil {
 			return xerrors.Errorf("failed to send flight info: %w", err)
@@ -109,13 +131,13 @@ func (h *handler) GetSchema(ctx context.Context, in *flight.FlightDescriptor) (*
 		return nil, xerrors.Errorf("failed to decode cmd: %v :%w", err, errors.ErrInvalidArgument)
 	}
 
-	table := getTableNameFromGetSchemaCmd(&cmd)
-	serializedSchema := h.SerializedSchemas[table]
-	if serializedSchema == nil {
-		return nil, xerrors.Errorf("table(%v): %w", table, errors.ErrNotFound)
+	tableName := getTableNameFromGetSchemaCmd(&cmd)
+	table := h.tables[tableName]
+	if table == nil {
+		return nil, xerrors.Errorf("table(%v): %w", tableName, errors.ErrNotFound)
 	}
 
-	return &flight.SchemaResult{Schema: serializedSchema}, nil
+	return &flight.SchemaResult{Schema: h.getDefaultSerializedSchema(table)}, nil
 }
 
 func (h *handler) GetFlightInfo(ctx context.Context, in *flight.FlightDescriptor) (*flight.FlightInfo, error) {
@@ -140,17 +162,44 @@ func (h *handler) GetFlightInfo(ctx context.Context, in *flight.FlightDescriptor
 		return nil, xerrors.Errorf("schema for table(%v): %w", tableName, errors.ErrNotFound)
 	}
 
-	endpoints, err := table.GetEndpoints(ctx, &cmd)
+	if _, err := getCompressionFromGetFlightInfoCmd(&cmd); err != nil {
+		return nil, xerrors.Errorf("failed to parse compression: %w", err)
+	}
+
+	partitioner, err := getPartitionerFromGetFlightInfoCmd(&cmd)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get partitioner: %w", err)
+	}
+
+	schema, _, err := projectTableSchema(table, getColumnsFromGetFlightInfoCmd(&cmd))
+	if err != nil {
+		return nil, xerrors.Errorf("failed to project schema for table(%v): %w", tableName, err)
+	}
+
+	serializedSchema = flight.SerializeSchema(withPartitionMetadata(schema, partitioner), memory.DefaultAllocator)
+
+	var endpoints []*flight.FlightEndpoint
+	var estimate *Estimate
+	if estimator, ok := table.(SizeEstimator); ok {
+		endpoints, estimate, err = estimator.GetEndpointsWithEstimate(ctx, &cmd)
+	} else {
+		endpoints, err = table.GetEndpoints(ctx, &cmd)
+	}
 	if err != nil {
 		return nil, xerrors.Errorf("failed to get endpoints for table(%v): %w", tableName, err)
 	}
 
+	totalRecords, totalBytes := int64(-1), int64(-1)
+	if estimate != nil {
+		totalRecords, totalBytes = estimate.TotalRecords, estimate.TotalBytes
+	}
+
 	return &flight.FlightInfo{
 		Schema:           serializedSchema,
 		FlightDescriptor: in,
 		Endpoint:         endpoints,
-		TotalRecords:     -1,
-		TotalBytes:       -1,
+		TotalRecords:     totalRecords,
+		TotalBytes:       totalBytes,
 	}, nil
 }
 
@@ -185,6 +234,27 @@ func (h *handler) DoAction(action *flight.Action, fs flight.FlightService_DoActi
 			return xerrors.Errorf("failed to send chain tip height: %w", err)
 		}
 
+		return nil
+	case flightActionHeightAtTime:
+		// The body is the time in RFC 3339, e.g. "2024-01-01T00:00:00Z".
+		t, err := time.Parse(time.RFC3339, string(action.Body))
+		if err != nil {
+			return xerrors.Errorf("failed to parse time(%s): %v: %w", action.Body, err, errors.ErrInvalidArgument)
+		}
+
+		height, err := h.csSession.GetHeightAtTime(fs.Context(), t)
+		if err != nil {
+			return xerrors.Errorf("failed to get height at time(%v): %w", t, err)
+		}
+
+		err = fs.Send(
+			&flight.Result{
+				Body: []byte(strconv.FormatUint(height, 10)),
+			})
+		if err != nil {
+			return xerrors.Errorf("failed to send height at time: %w", err)
+		}
+
 		return nil
 	case flightStreamActionEarliest:
 		fallthrough
@@ -208,6 +278,14 @@ func (h *handler) DoAction(action *flight.Action, fs flight.FlightService_DoActi
 		}
 
 		return nil
+	case flightActionChainMetadata:
+		return h.doChainMetadataAction(fs)
+	case flightActionSequenceToHeight:
+		return h.doSequenceToHeightAction(action, fs)
+	case flightActionHeightToSequence:
+		return h.doHeightToSequenceAction(action, fs)
+	case flightActionVersion:
+		return h.doVersionAction(fs)
 	default:
 		return xerrors.Errorf("unsupported actionType(%v): %w", t, errors.ErrInvalidArgument)
 	}
@@ -228,9 +306,25 @@ func (h *handler) DoGet(tkt *flight.Ticket, fs flight.FlightService_DoGetServer)
 	if table == nil {
 		return xerrors.Errorf("table(%v): %w", tableName, errors.ErrNotFound)
 	}
-	tableSchema := table.GetSchema()
 
-	tableWriter, err := xarrow.NewTableWriter(h.logger, tableSchema, fs)
+	tableSchema, projection, err := projectTableSchema(table, getColumnsFromGetFlightInfoCmd(&cmd))
+	if err != nil {
+		return xerrors.Errorf("failed to project schema for table(%v): %w", tableName, err)
+	}
+
+	compression, err := getCompressionFromGetFlightInfoCmd(&cmd)
+	if err != nil {
+		return xerrors.Errorf("failed to parse compression: %w", err)
+	}
+
+	partitioner, err := getPartitionerFromGetFlightInfoCmd(&cmd)
+	if err != nil {
+		return xerrors.Errorf("failed to get partitioner: %w", err)
+	}
+	tableSchema = withPartitionMetadata(tableSchema, partitioner)
+
+	opts := append(h.getTableWriterOptions(&cmd, compression), xarrow.WithProjection(projection))
+	tableWriter, err := xarrow.NewTableWriter(h.logger, tableSchema, fs, opts...)
 	if err != nil {
 		return xerrors.Errorf("failed to create table writer for table(%s): %w", tableName, err)
 	}
@@ -249,7 +343,32 @@ func (h *handler) DoGet(tkt *flight.Ticket, fs flight.FlightService_DoGetServer)
 	return finalizer.Close()
 }
 
+func (h *handler) getTableWriterOptions(cmd *api.GetFlightInfoCmd, compression constant.Compression) []xarrow.TableWriterOption {
+	tableName, tableFormat, encoding := getTableAttributesFromGetFlightInfoCmd(cmd)
+	scope := h.metrics.SubScope("table_writer").Tagged(map[string]string{
+		"table_name":   tableName,
+		"table_format": tableFormat,
+		"encoding":     encoding,
+		"compression":  compression.String(),
+	})
+
+	opts := []xarrow.TableWriterOption{xarrow.WithMetrics(scope)}
+	switch compression {
+	case constant.CompressionLz4Frame:
+		opts = append(opts, xarrow.WithLZ4())
+	case constant.CompressionZstd:
+		opts = append(opts, xarrow.WithZstd())
+	}
+
+	return opts
+}
+
 func getTableNameFromGetFlightInfoCmd(cmd *api.GetFlightInfoCmd) string {
+	tableName, tableFormat, encoding := getTableAttributesFromGetFlightInfoCmd(cmd)
+	return fmt.Sprintf("table=%v/format=%v/encoding=%v", tableName, tableFormat, encoding)
+}
+
+func getTableAttributesFromGetFlightInfoCmd(cmd *api.GetFlightInfoCmd) (string, string, string) {
 	tableName := ""
 	tableFormat := ""
 	encoding := ""
@@ -273,7 +392,56 @@ func getTableNameFromGetFlightInfoCmd(cmd *api.GetFlightInfoCmd) string {
 		encoding = constant.EncodingNone.String()
 	}
 
-	return fmt.Sprintf("table=%v/format=%v/encoding=%v", tableName, tableFormat, encoding)
+	return tableName, tableFormat, encoding
+}
+
+func getCompressionFromGetFlightInfoCmd(cmd *api.GetFlightInfoCmd) (constant.Compression, error) {
+	compression := ""
+	if cmd.GetBatchQuery() != nil {
+		compression = cmd.GetBatchQuery().GetCompression()
+	}
+	if cmd.GetStreamQuery() != nil {
+		compression = cmd.GetStreamQuery().GetCompression()
+	}
+
+	if compression == "" {
+		return constant.CompressionNone, nil
+	}
+
+	res, err := constant.ParseCompression(compression)
+	if err != nil {
+		return constant.CompressionNone, xerrors.Errorf("unsupported compression(%v): %w", compression, errors.ErrInvalidArgument)
+	}
+
+	return res, nil
+}
+
+func getColumnsFromGetFlightInfoCmd(cmd *api.GetFlightInfoCmd) []string {
+	if cmd.GetBatchQuery() != nil {
+		return cmd.GetBatchQuery().GetColumns()
+	}
+	if cmd.GetStreamQuery() != nil {
+		return cmd.GetStreamQuery().GetColumns()
+	}
+
+	return nil
+}
+
+// projectTableSchema returns the schema of the table restricted to the given columns.
+// The whole schema is returned if no column is specified.
+func projectTableSchema(table Table, columns []string) (*arrow.Schema, *xarrow.Projection, error) {
+	schema, projection, err := xarrow.ProjectSchema(table.GetSchema(), columns)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("invalid columns(%v): %v: %w", columns, err, errors.ErrInvalidArgument)
+	}
+
+	return schema, projection, nil
+}
+
+// getDefaultSerializedSchema returns the serialized schema of the table annotated with its default partition strategy.
+// The schema version is computed from the schemas without the annotations.
+func (h *handler) getDefaultSerializedSchema(table Table) []byte {
+	return flight.SerializeSchema(withPartitionMetadata(table.GetSchema(), getDefaultPartitioner(table)), memory.DefaultAllocator)
 }
 
 func getTableNameFromGetSchemaCmd(cmd *api.GetSchemaCmd) string {
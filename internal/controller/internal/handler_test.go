@@ -2,7 +2,10 @@ package internal
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/apache/arrow/go/v10/arrow"
 	"github.com/apache/arrow/go/v10/arrow/flight"
@@ -13,9 +16,14 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/coinbase/chainstorage/protos/coinbase/c3/common"
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+
 	csmocks "github.com/coinbase/chainsformer/internal/chainstorage/mocks"
+	"github.com/coinbase/chainsformer/internal/config"
 	controllermocks "github.com/coinbase/chainsformer/internal/controller/mocks"
 	"github.com/coinbase/chainsformer/internal/errors"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
 	"github.com/coinbase/chainsformer/internal/utils/protoutil"
 	"github.com/coinbase/chainsformer/internal/utils/xarrow"
 	api "github.com/coinbase/chainsformer/protos/coinbase/chainsformer"
@@ -30,6 +38,21 @@ type (
 		tables            []*controllermocks.MockTable
 		serializedSchemas map[string][]byte
 	}
+
+	testDoActionServer struct {
+		flight.FlightService_DoActionServer
+		results []*flight.Result
+	}
+
+	testListActionsServer struct {
+		flight.FlightService_ListActionsServer
+		actionTypes []*flight.ActionType
+	}
+
+	testListFlightsServer struct {
+		flight.FlightService_ListFlightsServer
+		infos []*flight.FlightInfo
+	}
 )
 
 const (
@@ -37,9 +60,10 @@ const (
 	testSchemaFieldName1 = "field1"
 	testSchemaFieldName2 = "field2"
 
-	schema0Name = "schema0"
-	schema1Name = "schema1"
-	schema2Name = "schema2"
+	schema0Name          = "schema0"
+	schema0ProjectedName = "schema0_projected"
+	schema1Name          = "schema1"
+	schema2Name          = "schema2"
 )
 
 // TODO figure out how to mock FlightService_DoGetServer and add more tests.
@@ -67,7 +91,31 @@ func (s *handlerTestSuite) SetupTest() {
 	s.tables[2].EXPECT().GetTableName().AnyTimes().Return("table=table2/format=rosetta/encoding=raw")
 
 	f := xarrow.NewSchemaFactory()
-	schema0 := f.NewSchema(f.NewField(testSchemaFieldName0, arrow.BinaryTypes.String, "test field"))
+	schema0 := f.NewSchema(
+		f.NewField(testSchemaFieldName0, arrow.BinaryTypes.String, "test field"),
+		f.NewField("value", arrow.PrimitiveTypes.Uint64, "test field"),
+		f.NewField("receipt", f.NewStruct(
+			f.NewField("status", arrow.PrimitiveTypes.Uint64, "test nested field"),
+			f.NewField("logs", f.NewList(f.NewStruct(
+				f.NewField("address", arrow.BinaryTypes.String, "test nested field"),
+				f.NewField("data", arrow.BinaryTypes.String, "test nested field"),
+			)), "test nested field"),
+		), "test field"),
+		f.NewField("traces", f.NewList(f.NewStruct(
+			f.NewField("from", arrow.BinaryTypes.String, "test nested field"),
+			f.NewField("to", arrow.BinaryTypes.String, "test nested field"),
+		)), "test field"),
+	)
+	// schema0Projected is schema0 restricted to the columns "field0", "receipt.status" and "traces.to".
+	schema0Projected := f.NewSchema(
+		f.NewField(testSchemaFieldName0, arrow.BinaryTypes.String, "test field"),
+		f.NewField("receipt", f.NewStruct(
+			f.NewField("status", arrow.PrimitiveTypes.Uint64, "test nested field"),
+		), "test field"),
+		f.NewField("traces", f.NewList(f.NewStruct(
+			f.NewField("to", arrow.BinaryTypes.String, "test nested field"),
+		)), "test field"),
+	)
 	schema1 := f.NewSchema(f.NewField(testSchemaFieldName1, arrow.BinaryTypes.String, "test field"))
 	schema2 := f.NewSchema(f.NewField(testSchemaFieldName2, arrow.BinaryTypes.String, "test field"))
 
@@ -76,6 +124,7 @@ func (s *handlerTestSuite) SetupTest() {
 	s.tables[2].EXPECT().GetSchema().AnyTimes().Return(schema2)
 
 	s.serializedSchemas[schema0Name] = flight.SerializeSchema(schema0, memory.DefaultAllocator)
+	s.serializedSchemas[schema0ProjectedName] = flight.SerializeSchema(schema0Projected, memory.DefaultAllocator)
 	s.serializedSchemas[schema1Name] = flight.SerializeSchema(schema1, memory.DefaultAllocator)
 	s.serializedSchemas[schema2Name] = flight.SerializeSchema(schema2, memory.DefaultAllocator)
 
@@ -94,7 +143,9 @@ func (s *handlerTestSuite) SetupTest() {
 	s.handler = &handler{
 		tables:            tableByName,
 		SerializedSchemas: serializedSchemas,
+		schemaVersion:     getSchemaVersion(serializedSchemas),
 		logger:            zaptest.NewLogger(s.T()),
+		config:            &config.Config{Chain: config.ChainConfig{Blockchain: common.Blockchain_BLOCKCHAIN_ETHEREUM, Network: common.Network_NETWORK_ETHEREUM_MAINNET}},
 		csSession:         s.csSession,
 	}
 }
@@ -171,7 +222,12 @@ func (s *handlerTestSuite) TestGetSchema() {
 			schemaResult, err := s.handler.GetSchema(context.Background(), tc.descriptor)
 
 			if tc.expectedError == nil {
-				s.Require().Equal(tc.expectedSerializedSchema, schemaResult.Schema)
+				expectedSchema, err := flight.DeserializeSchema(tc.expectedSerializedSchema, memory.DefaultAllocator)
+				s.Require().NoError(err)
+				actualSchema, err := flight.DeserializeSchema(schemaResult.Schema, memory.DefaultAllocator)
+				s.Require().NoError(err)
+				s.Require().Equal(expectedSchema.Fields(), actualSchema.Fields())
+				s.requirePartitionMetadata(actualSchema, partition.StrategyHeight.String())
 			} else {
 				s.Require().Equal(status.Code(tc.expectedError), status.Code(err))
 			}
@@ -187,6 +243,8 @@ func (s *handlerTestSuite) TestGetFlightInfo() {
 		expectedEndpoints         []*flight.FlightEndpoint
 		expectedGetEndpointsError error
 		expectedSerializedSchema  []byte
+		expectedExcludedColumns   []string
+		expectedPartitionStrategy string
 		expectedError             error
 	}{
 		"batch: table0 returns expected endpoints": {
@@ -291,6 +349,123 @@ func (s *handlerTestSuite) TestGetFlightInfo() {
 			expectedSerializedSchema: s.serializedSchemas[schema2Name],
 		},
 
+		"batch: table0 with zstd compression returns expected endpoints": {
+			descriptor: &flight.FlightDescriptor{
+				Type: flight.DescriptorCMD,
+			},
+			inputCmd: &api.GetFlightInfoCmd{
+				Query: &api.GetFlightInfoCmd_BatchQuery_{
+					BatchQuery: &api.GetFlightInfoCmd_BatchQuery{
+						Table:       "table0",
+						Compression: "zstd",
+					},
+				},
+			},
+			expectedTable:            s.tables[0],
+			expectedEndpoints:        []*flight.FlightEndpoint{{}},
+			expectedSerializedSchema: s.serializedSchemas[schema0Name],
+		},
+
+		"batch: table0 with day partition strategy returns expected endpoints": {
+			descriptor: &flight.FlightDescriptor{
+				Type: flight.DescriptorCMD,
+			},
+			inputCmd: &api.GetFlightInfoCmd{
+				Query: &api.GetFlightInfoCmd_BatchQuery_{
+					BatchQuery: &api.GetFlightInfoCmd_BatchQuery{
+						Table:             "table0",
+						PartitionStrategy: "day",
+					},
+				},
+			},
+			expectedTable:             s.tables[0],
+			expectedEndpoints:         []*flight.FlightEndpoint{{}},
+			expectedSerializedSchema:  s.serializedSchemas[schema0Name],
+			expectedPartitionStrategy: "day",
+		},
+
+		"batch: table0 sequence partition strategy returns error": {
+			descriptor: &flight.FlightDescriptor{
+				Type: flight.DescriptorCMD,
+			},
+			inputCmd: &api.GetFlightInfoCmd{
+				Query: &api.GetFlightInfoCmd_BatchQuery_{
+					BatchQuery: &api.GetFlightInfoCmd_BatchQuery{
+						Table:             "table0",
+						PartitionStrategy: "sequence",
+					},
+				},
+			},
+			expectedError: errors.ErrInvalidArgument,
+		},
+
+		"batch: table0 unsupported compression returns error": {
+			descriptor: &flight.FlightDescriptor{
+				Type: flight.DescriptorCMD,
+			},
+			inputCmd: &api.GetFlightInfoCmd{
+				Query: &api.GetFlightInfoCmd_BatchQuery_{
+					BatchQuery: &api.GetFlightInfoCmd_BatchQuery{
+						Table:       "table0",
+						Compression: "gzip",
+					},
+				},
+			},
+			expectedError: errors.ErrInvalidArgument,
+		},
+
+		"stream: table1 with lz4_frame compression returns expected endpoints": {
+			descriptor: &flight.FlightDescriptor{
+				Type: flight.DescriptorCMD,
+			},
+			inputCmd: &api.GetFlightInfoCmd{
+				Query: &api.GetFlightInfoCmd_StreamQuery_{
+					StreamQuery: &api.GetFlightInfoCmd_StreamQuery{
+						Table:       "table1",
+						Format:      "rosetta",
+						Compression: "lz4_frame",
+					},
+				},
+			},
+			expectedTable:            s.tables[1],
+			expectedEndpoints:        []*flight.FlightEndpoint{{}},
+			expectedSerializedSchema: s.serializedSchemas[schema1Name],
+		},
+
+		"batch: table0 with columns returns projected schema": {
+			descriptor: &flight.FlightDescriptor{
+				Type: flight.DescriptorCMD,
+			},
+			inputCmd: &api.GetFlightInfoCmd{
+				Query: &api.GetFlightInfoCmd_BatchQuery_{
+					BatchQuery: &api.GetFlightInfoCmd_BatchQuery{
+						Table:   "table0",
+						Columns: []string{testSchemaFieldName0, "receipt.status", "traces.to"},
+					},
+				},
+			},
+			expectedTable:            s.tables[0],
+			expectedEndpoints:        []*flight.FlightEndpoint{{}},
+			expectedSerializedSchema: s.serializedSchemas[schema0ProjectedName],
+			expectedExcludedColumns:  []string{"value", "receipt.logs", "receipt.logs.address", "traces.from"},
+		},
+
+		"stream: table1 unknown column returns error": {
+			descriptor: &flight.FlightDescriptor{
+				Type: flight.DescriptorCMD,
+			},
+			inputCmd: &api.GetFlightInfoCmd{
+				Query: &api.GetFlightInfoCmd_StreamQuery_{
+					StreamQuery: &api.GetFlightInfoCmd_StreamQuery{
+						Table:   "table1",
+						Format:  "rosetta",
+						Columns: []string{"unknown_column"},
+					},
+				},
+			},
+			expectedError: errors.ErrInvalidArgument,
+		},
+
 		"batch: table0 unable to find table returns error": {
 			descriptor: &flight.FlightDescriptor{
 				Type: flight.DescriptorCMD,
@@ -435,10 +610,261 @@ func (s *handlerTestSuite) TestGetFlightInfo() {
 			schemaResult, err := s.handler.GetFlightInfo(context.Background(), tc.descriptor)
 
 			if tc.expectedError == nil {
-				s.Require().Equal(tc.expectedSerializedSchema, schemaResult.Schema)
+				expectedSchema, err := flight.DeserializeSchema(tc.expectedSerializedSchema, memory.DefaultAllocator)
+				s.Require().NoError(err)
+				actualSchema, err := flight.DeserializeSchema(schemaResult.Schema, memory.DefaultAllocator)
+				s.Require().NoError(err)
+				s.Require().Equal(expectedSchema.Fields(), actualSchema.Fields())
+				for _, column := range tc.expectedExcludedColumns {
+					s.Require().False(hasColumn(actualSchema.Fields(), strings.Split(column, ".")), column)
+				}
+
+				expectedPartitionStrategy := tc.expectedPartitionStrategy
+				if expectedPartitionStrategy == "" {
+					expectedPartitionStrategy = partition.StrategyHeight.String()
+					if tc.inputCmd.GetStreamQuery() != nil {
+						expectedPartitionStrategy = partition.StrategySequence.String()
+					}
+				}
+				i := actualSchema.Metadata().FindKey(SchemaMetadataPartitionStrategy)
+				s.Require().GreaterOrEqual(i, 0)
+				s.Require().Equal(expectedPartitionStrategy, actualSchema.Metadata().Values()[i])
 			} else {
 				s.Require().Equal(status.Code(tc.expectedError), status.Code(err))
 			}
 		})
 	}
 }
+
+func (s *handlerTestSuite) TestListFlights() {
+	fs := &testListFlightsServer{}
+	err := s.handler.ListFlights(&flight.Criteria{}, fs)
+	s.Require().NoError(err)
+	s.Require().Equal(len(s.tables), len(fs.infos))
+	for _, info := range fs.infos {
+		s.Require().Equal(int64(-1), info.TotalRecords)
+		s.Require().Equal(int64(-1), info.TotalBytes)
+
+		expectedSchema := s.handler.tables[info.FlightDescriptor.Path[0]].GetSchema()
+		actualSchema, err := flight.DeserializeSchema(info.Schema, memory.DefaultAllocator)
+		s.Require().NoError(err)
+		s.Require().Equal(expectedSchema.Fields(), actualSchema.Fields())
+		s.requirePartitionMetadata(actualSchema, partition.StrategyHeight.String())
+	}
+}
+
+func (s *handlerTestSuite) TestGetDefaultPartitioner() {
+	s.Require().Equal(partition.StrategyHeight, getDefaultPartitioner(s.tables[0]).Strategy())
+	s.Require().Equal(partition.StrategySequence, getDefaultPartitioner(&StreamTable{}).Strategy())
+}
+
+func (s *handlerTestSuite) requirePartitionMetadata(schema *arrow.Schema, expectedPartitionStrategy string) {
+	metadata := schema.Metadata()
+	i := metadata.FindKey(SchemaMetadataPartitionStrategy)
+	s.Require().GreaterOrEqual(i, 0)
+	s.Require().Equal(expectedPartitionStrategy, metadata.Values()[i])
+	i = metadata.FindKey(SchemaMetadataPartitionBySize)
+	s.Require().GreaterOrEqual(i, 0)
+	s.Require().Equal("0", metadata.Values()[i])
+}
+
+func (s *handlerTestSuite) TestDoAction_HeightAtTime() {
+	fs := &testDoActionServer{}
+	s.csSession.EXPECT().GetHeightAtTime(gomock.Any(), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)).Return(uint64(18908895), nil)
+
+	err := s.handler.DoAction(&flight.Action{Type: flightActionHeightAtTime, Body: []byte("2024-01-01T00:00:00Z")}, fs)
+	s.Require().NoError(err)
+	s.Require().Equal(1, len(fs.results))
+	s.Require().Equal("18908895", string(fs.results[0].Body))
+}
+
+func (s *handlerTestSuite) TestDoAction_HeightAtTime_InvalidTime() {
+	fs := &testDoActionServer{}
+
+	err := s.handler.DoAction(&flight.Action{Type: flightActionHeightAtTime, Body: []byte("1704067200")}, fs)
+	s.Require().ErrorIs(err, errors.ErrInvalidArgument)
+	s.Require().Empty(fs.results)
+}
+
+func (s *handlerTestSuite) TestListActions() {
+	fs := &testListActionsServer{}
+
+	err := s.handler.ListActions(&flight.Empty{}, fs)
+	s.Require().NoError(err)
+	var actionTypes []string
+	for _, actionType := range fs.actionTypes {
+		s.Require().NotEmpty(actionType.Description)
+		actionTypes = append(actionTypes, actionType.Type)
+	}
+	s.Require().Equal([]string{
+		flightActionTip,
+		flightActionEarliest,
+		flightStreamActionTip,
+		flightStreamActionEarliest,
+		flightActionHeightAtTime,
+		flightActionChainMetadata,
+		flightActionSequenceToHeight,
+		flightActionHeightToSequence,
+		flightActionVersion,
+	}, actionTypes)
+}
+
+func (s *handlerTestSuite) TestDoAction_ChainMetadata() {
+	fs := &testDoActionServer{}
+	s.csSession.EXPECT().GetStaticChainMetadata(gomock.Any(), &chainstorageapi.GetChainMetadataRequest{}).Return(&chainstorageapi.GetChainMetadataResponse{
+		BlockStartHeight:     1,
+		IrreversibleDistance: 12,
+		BlockTime:            "12s",
+		LatestEventTag:       2,
+	}, nil)
+
+	err := s.handler.DoAction(&flight.Action{Type: flightActionChainMetadata}, fs)
+	s.Require().NoError(err)
+	s.Require().Equal(1, len(fs.results))
+	var result chainMetadataResult
+	s.Require().NoError(json.Unmarshal(fs.results[0].Body, &result))
+	s.Require().Equal(chainMetadataResult{
+		Blockchain:           "ethereum",
+		Network:              "ethereum-mainnet",
+		BlockStartHeight:     1,
+		IrreversibleDistance: 12,
+		BlockTime:            "12s",
+		LatestEventTag:       2,
+	}, result)
+}
+
+func (s *handlerTestSuite) TestDoAction_SequenceToHeight() {
+	fs := &testDoActionServer{}
+	s.csSession.EXPECT().GetEventBySequence(gomock.Any(), int64(100)).Return(&chainstorageapi.BlockchainEvent{
+		SequenceNum: 100,
+		Type:        chainstorageapi.BlockchainEvent_BLOCK_ADDED,
+		Block: &chainstorageapi.BlockIdentifier{
+			Hash:   "0xabc",
+			Height: 12345,
+			Tag:    1,
+		},
+	}, nil)
+
+	err := s.handler.DoAction(&flight.Action{Type: flightActionSequenceToHeight, Body: []byte(`{"sequence":100}`)}, fs)
+	s.Require().NoError(err)
+	s.Require().Equal(1, len(fs.results))
+	var result eventResult
+	s.Require().NoError(json.Unmarshal(fs.results[0].Body, &result))
+	s.Require().Equal(eventResult{
+		Sequence: 100,
+		Type:     "BLOCK_ADDED",
+		Height:   12345,
+		Hash:     "0xabc",
+		Tag:      1,
+	}, result)
+}
+
+func (s *handlerTestSuite) TestDoAction_HeightToSequence() {
+	fs := &testDoActionServer{}
+	s.csSession.EXPECT().GetFirstEventByHeight(gomock.Any(), uint64(12345)).Return(&chainstorageapi.BlockchainEvent{
+		SequenceNum: 100,
+		Type:        chainstorageapi.BlockchainEvent_BLOCK_ADDED,
+		Block: &chainstorageapi.BlockIdentifier{
+			Hash:   "0xabc",
+			Height: 12345,
+		},
+	}, nil)
+
+	err := s.handler.DoAction(&flight.Action{Type: flightActionHeightToSequence, Body: []byte(`{"height":12345}`)}, fs)
+	s.Require().NoError(err)
+	s.Require().Equal(1, len(fs.results))
+	var result eventResult
+	s.Require().NoError(json.Unmarshal(fs.results[0].Body, &result))
+	s.Require().Equal(int64(100), result.Sequence)
+	s.Require().Equal(uint64(12345), result.Height)
+}
+
+func (s *handlerTestSuite) TestDoAction_HeightToSequence_InvalidBody() {
+	fs := &testDoActionServer{}
+
+	err := s.handler.DoAction(&flight.Action{Type: flightActionHeightToSequence, Body: []byte(`{"height":"abc"}`)}, fs)
+	s.Require().ErrorIs(err, errors.ErrInvalidArgument)
+	s.Require().Empty(fs.results)
+}
+
+func (s *handlerTestSuite) TestDoAction_Version() {
+	fs := &testDoActionServer{}
+
+	err := s.handler.DoAction(&flight.Action{Type: flightActionVersion}, fs)
+	s.Require().NoError(err)
+	s.Require().Equal(1, len(fs.results))
+	var result versionResult
+	s.Require().NoError(json.Unmarshal(fs.results[0].Body, &result))
+	s.Require().NotEmpty(result.ServerVersion)
+	s.Require().Equal(s.handler.schemaVersion, result.SchemaVersion)
+	s.Require().Len(result.SchemaVersion, 2*schemaVersionLength)
+}
+
+func (s *handlerTestSuite) TestGetSchemaVersion() {
+	f := xarrow.NewSchemaFactory()
+	schema := f.NewSchema(f.NewField(testSchemaFieldName0, arrow.BinaryTypes.String, "test field"))
+	serializedSchemas := map[string][]byte{
+		"table0": flight.SerializeSchema(schema, memory.DefaultAllocator),
+		"table1": flight.SerializeSchema(schema, memory.DefaultAllocator),
+	}
+	version := getSchemaVersion(serializedSchemas)
+	s.Require().Equal(version, getSchemaVersion(serializedSchemas))
+
+	// The version changes with the schema of any table.
+	changedSchema := f.NewSchema(f.NewField(testSchemaFieldName0, arrow.PrimitiveTypes.Uint64, "test field"))
+	serializedSchemas["table1"] = flight.SerializeSchema(changedSchema, memory.DefaultAllocator)
+	s.Require().NotEqual(version, getSchemaVersion(serializedSchemas))
+
+	// The version changes with the tables.
+	delete(serializedSchemas, "table1")
+	s.Require().NotEqual(version, getSchemaVersion(serializedSchemas))
+}
+
+func (s *testDoActionServer) Context() context.Context {
+	return context.Background()
+}
+
+func (s *testDoActionServer) Send(result *flight.Result) error {
+	s.results = append(s.results, result)
+	return nil
+}
+
+func (s *testListFlightsServer) Context() context.Context {
+	return context.Background()
+}
+
+func (s *testListFlightsServer) Send(info *flight.FlightInfo) error {
+	s.infos = append(s.infos, info)
+	return nil
+}
+
+func (s *testListActionsServer) Send(actionType *flight.ActionType) error {
+	s.actionTypes = append(s.actionTypes, actionType)
+	return nil
+}
+
+// hasColumn returns whether the column at the path is in the fields, looking through structs and lists of structs.
+func hasColumn(fields []arrow.Field, path []string) bool {
+	for _, field := range fields {
+		if field.Name != path[0] {
+			continue
+		}
+
+		if len(path) == 1 {
+			return true
+		}
+
+		dataType := field.Type
+		if list, ok := dataType.(*arrow.ListType); ok {
+			dataType = list.Elem()
+		}
+
+		if st, ok := dataType.(*arrow.StructType); ok {
+			return hasColumn(st.Fields(), path[1:])
+		}
+
+		return false
+	}
+
+	return false
+}
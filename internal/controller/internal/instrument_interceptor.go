@@ -23,6 +23,7 @@ type (
 		instrumentGetSchema     instrument.Call
 		instrumentGetFlightInfo instrument.Call
 		instrumentDoAction      instrument.Call
+		instrumentListActions   instrument.Call
 		instrumentDoGet         instrument.Call
 	}
 
@@ -36,6 +37,11 @@ type (
 		ctx context.Context
 	}
 
+	ListActionsServer struct {
+		flight.FlightService_ListActionsServer
+		ctx context.Context
+	}
+
 	DoGetServer struct {
 		flight.FlightService_DoGetServer
 		ctx context.Context
@@ -50,6 +56,7 @@ func withInstrumentInterceptor(next Handler, scope tally.Scope, logger *zap.Logg
 		instrumentGetSchema:     newInstrument("get_schema", scope, logger),
 		instrumentGetFlightInfo: newInstrument("get_flight_info", scope, logger),
 		instrumentDoAction:      newInstrument("do_action", scope, logger),
+		instrumentListActions:   newInstrument("list_actions", scope, logger),
 		instrumentDoGet:         newInstrument("do_get", scope, logger),
 	}
 }
@@ -112,6 +119,12 @@ func (i *instrumentInterceptor) DoAction(action *flight.Action, fs flight.Flight
 	})
 }
 
+func (i *instrumentInterceptor) ListActions(in *flight.Empty, fs flight.FlightService_ListActionsServer) error {
+	return i.instrumentListActions.Instrument(fs.Context(), func(ctx context.Context) error {
+		return i.next.ListActions(in, ListActionsServer{fs, ctx})
+	})
+}
+
 func (i *instrumentInterceptor) DoGet(tkt *flight.Ticket, fs flight.FlightService_DoGetServer) error {
 	return i.instrumentDoGet.Instrument(fs.Context(), func(ctx context.Context) error {
 		return i.next.DoGet(tkt, DoGetServer{fs, ctx})
@@ -126,6 +139,10 @@ func (s DoActionServer) Context() context.Context {
 	return s.ctx
 }
 
+func (s ListActionsServer) Context() context.Context {
+	return s.ctx
+}
+
 func (s DoGetServer) Context() context.Context {
 	return s.ctx
 }
@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"strconv"
+
+	"github.com/apache/arrow/go/v10/arrow"
+	"golang.org/x/xerrors"
+
+	"github.com/coinbase/chainsformer/internal/errors"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	api "github.com/coinbase/chainsformer/protos/coinbase/chainsformer"
+)
+
+const (
+	// SchemaMetadataPartitionStrategy is the schema metadata key of the strategy used to compute the _partition_by column.
+	SchemaMetadataPartitionStrategy = "chainsformer.partition_strategy"
+	// SchemaMetadataPartitionBySize is the schema metadata key of the bucket size of the height and sequence strategies.
+	SchemaMetadataPartitionBySize = "chainsformer.partition_by_size"
+)
+
+// getPartitionerFromGetFlightInfoCmd returns the partitioner of the query.
+// Batch queries default to the height strategy and cannot use the sequence strategy,
+// while stream queries default to the sequence strategy.
+func getPartitionerFromGetFlightInfoCmd(cmd *api.GetFlightInfoCmd) (*partition.Partitioner, error) {
+	var strategy string
+	var size uint64
+	defaultStrategy := partition.StrategyHeight
+	if cmd.GetBatchQuery() != nil {
+		strategy = cmd.GetBatchQuery().GetPartitionStrategy()
+		size = cmd.GetBatchQuery().GetPartitionBySize()
+	}
+	if cmd.GetStreamQuery() != nil {
+		strategy = cmd.GetStreamQuery().GetPartitionStrategy()
+		size = cmd.GetStreamQuery().GetPartitionBySize()
+		defaultStrategy = partition.StrategySequence
+	}
+
+	if strategy == "" {
+		return partition.NewPartitioner(defaultStrategy, size), nil
+	}
+
+	res, err := partition.ParseStrategy(strategy)
+	if err != nil {
+		return nil, xerrors.Errorf("unsupported partition strategy(%v): %w", strategy, errors.ErrInvalidArgument)
+	}
+
+	if res == partition.StrategySequence && cmd.GetStreamQuery() == nil {
+		return nil, xerrors.Errorf("partition strategy(%v) is only supported by stream queries: %w", strategy, errors.ErrInvalidArgument)
+	}
+
+	return partition.NewPartitioner(res, size), nil
+}
+
+// getDefaultPartitioner returns the partitioner of the queries of the table without a partition strategy.
+// Stream tables default to the sequence strategy, while the other tables default to the height strategy.
+func getDefaultPartitioner(table Table) *partition.Partitioner {
+	if _, ok := table.(*StreamTable); ok {
+		return partition.NewPartitioner(partition.StrategySequence, 0)
+	}
+
+	return partition.NewPartitioner(partition.StrategyHeight, 0)
+}
+
+// withPartitionMetadata returns the schema annotated with how the _partition_by column is derived.
+func withPartitionMetadata(schema *arrow.Schema, partitioner *partition.Partitioner) *arrow.Schema {
+	metadata := schema.Metadata()
+	keys := append([]string{SchemaMetadataPartitionStrategy, SchemaMetadataPartitionBySize}, metadata.Keys()...)
+	values := append([]string{partitioner.Strategy().String(), strconv.FormatUint(partitioner.Size(), 10)}, metadata.Values()...)
+	md := arrow.NewMetadata(keys, values)
+	return arrow.NewSchema(schema.Fields(), &md)
+}
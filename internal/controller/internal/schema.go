@@ -0,0 +1,35 @@
+package internal
+
+import (
+	"github.com/apache/arrow/go/v10/arrow"
+
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+const (
+	// RepartitionByRangeDescription is the description of the _repartition_by_range column
+	// of the tables range partitioned by the block height.
+	RepartitionByRangeDescription = "Records will be range partitioned based on the _repartition_by_range column"
+)
+
+// NewTableSchema returns the schema of a table with the given fields followed by the _partition_by and
+// _repartition_by_range columns. repartitionDescription documents what the _repartition_by_range column holds.
+func NewTableSchema(repartitionDescription string, fields ...arrow.Field) *arrow.Schema {
+	f := xarrow.NewSchemaFactory()
+	return f.NewSchema(append(
+		fields,
+		f.NewField("_partition_by", arrow.PrimitiveTypes.Uint64, "Records with the same _partition_by value will be stored in the same s3 directory"),
+		f.NewField("_repartition_by_range", arrow.PrimitiveTypes.Uint64, repartitionDescription),
+	)...)
+}
+
+// NewStreamedSchema returns the schema of the streamed table with the _sequence_number and _event_type columns
+// prepended to the fields of the given table schema.
+func NewStreamedSchema(schema *arrow.Schema) *arrow.Schema {
+	f := xarrow.NewSchemaFactory()
+	fields := []arrow.Field{
+		f.NewField("_sequence_number", arrow.PrimitiveTypes.Int64, "Monotonically increasing event sequence number"),
+		f.NewField("_event_type", arrow.BinaryTypes.String, "Event type UNKNOWN, BLOCK_ADDED, BLOCK_REMOVED"),
+	}
+	return f.NewSchema(append(fields, schema.Fields()...)...)
+}
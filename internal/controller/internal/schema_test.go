@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v10/arrow"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+func TestNewTableSchema(t *testing.T) {
+	require := require.New(t)
+
+	f := xarrow.NewSchemaFactory()
+	schema := NewTableSchema(
+		"Records will be range partitioned based on the _repartition_by_range column, i.e. the version",
+		f.NewField("hash", arrow.BinaryTypes.String, "The block hash"),
+	)
+
+	require.Equal([]string{"hash", "_partition_by", "_repartition_by_range"}, testFieldNames(schema))
+	require.Equal(arrow.PrimitiveTypes.Uint64, schema.Field(2).Type)
+	metadata := schema.Field(2).Metadata
+	require.Equal("Records will be range partitioned based on the _repartition_by_range column, i.e. the version", metadata.Values()[metadata.FindKey("description")])
+}
+
+func TestNewStreamedSchema(t *testing.T) {
+	require := require.New(t)
+
+	f := xarrow.NewSchemaFactory()
+	schema := NewStreamedSchema(f.NewSchema(
+		f.NewField("hash", arrow.BinaryTypes.String, "The block hash"),
+	))
+
+	require.Equal([]string{"_sequence_number", "_event_type", "hash"}, testFieldNames(schema))
+	require.Equal(arrow.PrimitiveTypes.Int64, schema.Field(0).Type)
+	require.Equal(arrow.BinaryTypes.String, schema.Field(1).Type)
+}
+
+func testFieldNames(schema *arrow.Schema) []string {
+	var names []string
+	for _, field := range schema.Fields() {
+		names = append(names, field.Name)
+	}
+	return names
+}
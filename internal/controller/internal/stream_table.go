@@ -5,7 +5,6 @@ import (
 	"math"
 
 	"github.com/apache/arrow/go/v10/arrow"
-	"github.com/apache/arrow/go/v10/arrow/array"
 	"github.com/apache/arrow/go/v10/arrow/flight"
 	"github.com/golang/protobuf/proto"
 	"golang.org/x/xerrors"
@@ -16,6 +15,7 @@ import (
 	"github.com/coinbase/chainsformer/internal/chainstorage"
 	"github.com/coinbase/chainsformer/internal/config"
 	"github.com/coinbase/chainsformer/internal/errors"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
 	"github.com/coinbase/chainsformer/internal/utils/protoutil"
 	"github.com/coinbase/chainsformer/internal/utils/syncgroup"
 	"github.com/coinbase/chainsformer/internal/utils/xarrow"
@@ -24,7 +24,7 @@ import (
 
 type (
 	StreamTransformer interface {
-		TransformBlock(ctx context.Context, blockAndEvent *BlockAndEvent, parser sdk.Parser, recordBuilder *array.RecordBuilder, partitionBySize uint64) error
+		TransformBlock(ctx context.Context, blockAndEvent *BlockAndEvent, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter Filter, partitioner *partition.Partitioner) error
 	}
 
 	StreamTable struct {
@@ -62,6 +62,14 @@ func (t *StreamTable) GetEndpoints(ctx context.Context, cmd *api.GetFlightInfoCm
 			return xerrors.Errorf("streamQuery is not provided: %w", errors.ErrInvalidArgument)
 		}
 
+		if _, err := parseFilter(t.transformer, streamQuery.GetFilter()); err != nil {
+			return xerrors.Errorf("failed to parse filter: %w", err)
+		}
+
+		if _, err := getPartitionerFromGetFlightInfoCmd(cmd); err != nil {
+			return xerrors.Errorf("failed to get partitioner: %w", err)
+		}
+
 		seqInfo, err := t.getSequenceInfo(ctx, streamQuery.GetStartSequence(), streamQuery.GetEndSequence())
 		if err != nil {
 			return xerrors.Errorf("failed to get sequence info: %w", err)
@@ -107,6 +115,17 @@ func (t *StreamTable) DoGet(ctx context.Context, cmd *api.GetFlightInfoCmd, tabl
 			return xerrors.Errorf("streamQuery is not provided: %w", errors.ErrInvalidArgument)
 		}
 
+		// The filter is parsed once for all the blocks of the query.
+		filter, err := parseFilter(t.transformer, streamQuery.GetFilter())
+		if err != nil {
+			return xerrors.Errorf("failed to parse filter: %w", err)
+		}
+
+		partitioner, err := getPartitionerFromGetFlightInfoCmd(cmd)
+		if err != nil {
+			return xerrors.Errorf("failed to get partitioner: %w", err)
+		}
+
 		if streamQuery.StartSequence >= streamQuery.EndSequence {
 			return xerrors.Errorf("(startSequence=%d) must be less than or equal to (endSequence=%d): %w", streamQuery.StartSequence, streamQuery.EndSequence, errors.ErrInvalidArgument)
 		}
@@ -129,7 +148,7 @@ func (t *StreamTable) DoGet(ctx context.Context, cmd *api.GetFlightInfoCmd, tabl
 			}
 
 			for _, blockAndEvent := range blockAndEvents {
-				if err := t.transformer.TransformBlock(ctx, blockAndEvent, t.session.Parser(), tableWriter.RecordBuilder(), cmd.GetStreamQuery().PartitionBySize); err != nil {
+				if err := t.transformer.TransformBlock(ctx, blockAndEvent, t.session.Parser(), tableWriter.RecordBuilder(), filter, partitioner); err != nil {
 					return xerrors.Errorf("failed to process block and event: %w", err)
 				}
 
@@ -9,7 +9,6 @@ import (
 	"testing"
 
 	"github.com/apache/arrow/go/v10/arrow"
-	"github.com/apache/arrow/go/v10/arrow/array"
 	"github.com/apache/arrow/go/v10/arrow/flight"
 	"github.com/apache/arrow/go/v10/arrow/memory"
 	"github.com/stretchr/testify/assert"
@@ -38,13 +37,17 @@ import (
 type (
 	testStreamedBlocksTable struct{}
 
+	testFilterableStreamedBlocksTable struct {
+		testStreamedBlocksTable
+	}
+
 	testMocks struct {
 		ctrl          *gomock.Controller
 		session       *csmocks.MockSession
 		client        *sdkmocks.MockClient
 		parser        *sdkmocks.MockParser
 		tableWriter   *xarrowmocks.MockTableWriter
-		recordBuilder *array.RecordBuilder
+		recordBuilder *xarrow.RecordBuilder
 		streamTable   *StreamTable
 	}
 
@@ -211,6 +214,58 @@ func (s *streamTableTestSuite) TestGetEndpoints() {
 	}
 }
 
+func (s *streamTableTestSuite) TestGetEndpoints_UnsupportedFilterShouldReturnError() {
+	testMocks := newTestMocks(s.T())
+	cmd := &api.GetFlightInfoCmd{
+		Query: &api.GetFlightInfoCmd_StreamQuery_{
+			StreamQuery: &api.GetFlightInfoCmd_StreamQuery{
+				StartSequence: 1,
+				EndSequence:   2,
+				Filter: &api.GetFlightInfoCmd_Filter{
+					TransactionTypes: []uint64{2},
+				},
+			},
+		},
+	}
+
+	_, err := testMocks.streamTable.GetEndpoints(context.Background(), cmd)
+	assert.True(s.T(), errors.Is(err, internalerrors.ErrInvalidArgument))
+}
+
+func (s *streamTableTestSuite) TestGetEndpoints_FilterIsEchoedIntoTickets() {
+	testMocks := newTestMocks(s.T())
+	testMocks.streamTable.transformer = testFilterableStreamedBlocksTable{}
+	testMocks.session.EXPECT().
+		GetEventSequenceByPosition(gomock.Any(), chainstorage.EarliestEventPosition).
+		Return(int64(0), nil)
+	testMocks.session.EXPECT().
+		GetEventSequenceByPosition(gomock.Any(), chainstorage.LatestEventPosition).
+		Return(int64(10), nil)
+
+	filter := &api.GetFlightInfoCmd_Filter{
+		LogAddresses: []string{"0xdac17f958d2ee523a2206206994597c13d831ec7"},
+	}
+	cmd := &api.GetFlightInfoCmd{
+		Query: &api.GetFlightInfoCmd_StreamQuery_{
+			StreamQuery: &api.GetFlightInfoCmd_StreamQuery{
+				StartSequence:      1,
+				EndSequence:        5,
+				EventsPerPartition: 2,
+				Filter:             filter,
+			},
+		},
+	}
+
+	endpoints, err := testMocks.streamTable.GetEndpoints(context.Background(), cmd)
+	s.Require().NoError(err)
+	s.Require().Equal(2, len(endpoints))
+	for _, endpoint := range endpoints {
+		var ticket api.GetFlightInfoCmd
+		s.Require().NoError(protoutil.UnmarshalJSON(endpoint.GetTicket().GetTicket(), &ticket))
+		s.Require().Equal(filter.LogAddresses, ticket.GetStreamQuery().GetFilter().GetLogAddresses())
+	}
+}
+
 func (s *streamTableTestSuite) TestDoGet_NotProvideGetStreamFlightInfoCommandShouldReturnError() {
 	testMocks := newTestMocks(s.T())
 	err := testMocks.streamTable.DoGet(context.Background(), nil, testMocks.tableWriter)
@@ -380,6 +435,7 @@ func newTestMocks(t *testing.T) testMocks {
 		fx.Provide(func() chainstorage.Session {
 			return session
 		}),
+		fx.Provide(NewBlockSampler),
 		fx.Populate(&tableParams),
 	)
 	defer app.Close()
@@ -390,7 +446,7 @@ func newTestMocks(t *testing.T) testMocks {
 		client:        sdkmocks.NewMockClient(ctrl),
 		parser:        sdkmocks.NewMockParser(ctrl),
 		tableWriter:   xarrowmocks.NewMockTableWriter(ctrl),
-		recordBuilder: array.NewRecordBuilder(mem, tableSchema),
+		recordBuilder: xarrow.NewRecordBuilder(mem, tableSchema, nil),
 		streamTable: NewStreamTable(
 			&tableParams,
 			NewTableAttributes(TableNameStreamedBlocks),
@@ -547,7 +603,7 @@ func testStreamedBlocksDataType() arrow.DataType {
 	)
 }
 
-func (t testStreamedBlocksTable) TransformBlock(ctx context.Context, blockAndEvent *BlockAndEvent, parser sdk.Parser, recordBuilder *array.RecordBuilder, partitionBySize uint64) error {
+func (t testStreamedBlocksTable) TransformBlock(ctx context.Context, blockAndEvent *BlockAndEvent, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter Filter, partitioner *partition.Partitioner) error {
 	nativeBlock, err := parser.ParseNativeBlock(ctx, blockAndEvent.Block)
 	if err != nil {
 		return xerrors.Errorf("failed to parse raw block to native block: %w", err)
@@ -558,14 +614,14 @@ func (t testStreamedBlocksTable) TransformBlock(ctx context.Context, blockAndEve
 		return xerrors.New("failed to extract ethereum block from native block")
 	}
 
-	if err := testTransformStreamedBlocks(recordBuilder, ethereumBlock, blockAndEvent.BlockChainEvent, partitionBySize); err != nil {
+	if err := testTransformStreamedBlocks(recordBuilder, ethereumBlock, blockAndEvent.BlockChainEvent, partitioner); err != nil {
 		return xerrors.Errorf("failed to transform blocks: %w", err)
 	}
 
 	return nil
 }
 
-func testTransformStreamedBlocks(recordBuilder *array.RecordBuilder, block *chainstorageapi.EthereumBlock, event *chainstorageapi.BlockchainEvent, partitionSize uint64) error {
+func testTransformStreamedBlocks(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.EthereumBlock, event *chainstorageapi.BlockchainEvent, partitioner *partition.Partitioner) error {
 	header := block.GetHeader()
 	if header == nil {
 		return xerrors.New("header is required")
@@ -578,9 +634,13 @@ func testTransformStreamedBlocks(recordBuilder *array.RecordBuilder, block *chai
 			sa.AppendString(header.Hash).
 				AppendUint64(header.Number)
 		}).
-		AppendUint64(partition.GetPartitionByNumber(header.Number, partitionSize)).
+		AppendUint64(partitioner.GetPartitionBy(header.Number, event.GetSequenceNum(), header.GetTimestamp().AsTime())).
 		AppendUint64(header.Number).
 		Build()
 
 	return nil
 }
+
+func (t testFilterableStreamedBlocksTable) ParseFilter(filter *api.GetFlightInfoCmd_Filter) (Filter, error) {
+	return filter, nil
+}
@@ -22,6 +22,8 @@ const (
 	DefaultEventsPerRecord    = 1
 	defaultBlocksPerPartition = uint64(100)
 	defaultEventsPerPartition = uint64(100)
+	defaultRowsPerPartition   = uint64(1000000)
+	defaultBytesPerPartition  = uint64(256 << 20)
 	maxNumOfEndpoints         = 50000
 )
 
@@ -29,7 +31,8 @@ type (
 	CommonTableParams struct {
 		fx.In
 		fxparams.Params
-		Session chainstorage.Session
+		Session      chainstorage.Session
+		BlockSampler *BlockSampler
 	}
 
 	Table interface {
@@ -46,6 +49,8 @@ type (
 		TableName   string
 		TableFormat constant.TableFormat
 		Encoding    constant.Encoding
+		// EstimateMode is how the number of rows produced by a block is estimated.
+		EstimateMode constant.EstimateMode
 	}
 
 	baseTable struct {
@@ -54,6 +59,9 @@ type (
 		instrumentGetEndpoints instrument.Call
 		instrumentDoGet        instrument.Call
 		counterBlocksProcessed tally.Counter
+		counterBlocksSkipped   tally.Counter
+		timerFetchWait         tally.Timer
+		timerTransform         tally.Timer
 	}
 )
 
@@ -70,6 +78,9 @@ func newBaseTable(commonParams *CommonTableParams, attributes *TableAttributes,
 		instrumentGetEndpoints: instrument.NewCall(scope, "get_endpoints"),
 		instrumentDoGet:        instrument.NewCall(scope, "do_get"),
 		counterBlocksProcessed: scope.Counter("blocks_processed"),
+		counterBlocksSkipped:   scope.Counter("blocks_skipped"),
+		timerFetchWait:         scope.Timer("fetch_wait"),
+		timerTransform:         scope.Timer("transform"),
 	}
 }
 
@@ -110,3 +121,10 @@ func WithEncoding(encoding constant.Encoding) TableAttributesOption {
 		t.Encoding = encoding
 	}
 }
+
+// WithEstimateMode estimates the rows of the table from the block metadata instead of transforming the sampled blocks.
+func WithEstimateMode(estimateMode constant.EstimateMode) TableAttributesOption {
+	return func(t *TableAttributes) {
+		t.EstimateMode = estimateMode
+	}
+}
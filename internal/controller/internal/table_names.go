@@ -1,8 +1,28 @@
 package internal
 
 const (
-	TableNameBlocks               = "blocks"
-	TableNameTransactions         = "transactions"
-	TableNameStreamedBlocks       = "streamed_blocks"
-	TableNameStreamedTransactions = "streamed_transactions"
+	TableNameBlocks                 = "blocks"
+	TableNameTransactions           = "transactions"
+	TableNameStreamedBlocks         = "streamed_blocks"
+	TableNameStreamedTransactions   = "streamed_transactions"
+	TableNameLogs                   = "logs"
+	TableNameStreamedLogs           = "streamed_logs"
+	TableNameTraces                 = "traces"
+	TableNameStreamedTraces         = "streamed_traces"
+	TableNameTokenTransfers         = "token_transfers"
+	TableNameStreamedTokenTransfers = "streamed_token_transfers"
+	TableNameDecodedLogs            = "decoded_logs"
+	TableNameDecodedCalls           = "decoded_calls"
+	TableNameContracts              = "contracts"
+	TableNameWithdrawals            = "withdrawals"
+	TableNameStreamedWithdrawals    = "streamed_withdrawals"
+	TableNameUncleBlocks            = "uncle_blocks"
+	TableNameStreamedUncleBlocks    = "streamed_uncle_blocks"
+	TableNameInputs                 = "inputs"
+	TableNameOutputs                = "outputs"
+	TableNameOperations             = "operations"
+	TableNameStreamedOperations     = "streamed_operations"
+	TableNameInstructions           = "instructions"
+	TableNameRewards                = "rewards"
+	TableNameEvents                 = "events"
 )
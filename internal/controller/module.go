@@ -3,16 +3,21 @@ package controller
 import (
 	"go.uber.org/fx"
 
+	"github.com/coinbase/chainsformer/internal/controller/aptos"
 	"github.com/coinbase/chainsformer/internal/controller/bitcoin"
 	"github.com/coinbase/chainsformer/internal/controller/ethereum"
 	"github.com/coinbase/chainsformer/internal/controller/internal"
 	"github.com/coinbase/chainsformer/internal/controller/rosetta"
+	"github.com/coinbase/chainsformer/internal/controller/solana"
 )
 
 var Module = fx.Options(
 	fx.Provide(NewController),
 	fx.Provide(internal.NewHandler),
+	fx.Provide(internal.NewBlockSampler),
+	aptos.Module,
 	bitcoin.Module,
 	ethereum.Module,
 	rosetta.Module,
+	solana.Module,
 )
@@ -3,7 +3,6 @@ package tables
 import (
 	"context"
 
-	"github.com/apache/arrow/go/v10/arrow/array"
 	"golang.org/x/xerrors"
 
 	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
@@ -11,22 +10,45 @@ import (
 
 	"github.com/coinbase/chainsformer/internal/controller/internal"
 	"github.com/coinbase/chainsformer/internal/controller/internal/constant"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
 )
 
 type (
-	rosettaBlocksTable struct{}
+	rosettaBlocksTable struct {
+		encoder metadataEncoder
+	}
+
+	rosettaStreamedBlocksTable struct{}
 )
 
 func NewRosettaBlocksTable(params internal.CommonTableParams) internal.Table {
 	return internal.NewBatchTable(
 		&params,
-		internal.NewTableAttributes(internal.TableNameBlocks, internal.WithFormat(constant.TableFormatRosetta)),
+		internal.NewTableAttributes(internal.TableNameBlocks,
+			internal.WithFormat(constant.TableFormatRosetta),
+			internal.WithEstimateMode(constant.EstimateModeBlocks)),
 		newBlockSchema(),
-		rosettaBlocksTable{},
+		rosettaBlocksTable{encoder: jsonMetadataEncoder},
+	)
+}
+
+// NewTypedRosettaBlocksTable creates the blocks table of the typed encoding,
+// whose metadata is a map<string, string> followed by the promoted metadata columns.
+func NewTypedRosettaBlocksTable(params internal.CommonTableParams) internal.Table {
+	columns := params.Params.Config.Table.Rosetta.BlockMetadataColumns
+	return internal.NewBatchTable(
+		&params,
+		internal.NewTableAttributes(internal.TableNameBlocks,
+			internal.WithFormat(constant.TableFormatRosetta),
+			internal.WithEncoding(constant.EncodingTyped),
+			internal.WithEstimateMode(constant.EstimateModeBlocks)),
+		newTypedBlockSchema(columns),
+		rosettaBlocksTable{encoder: newTypedMetadataEncoder(columns)},
 	)
 }
 
-func (t rosettaBlocksTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *array.RecordBuilder, partitionBySize uint64) error {
+func (t rosettaBlocksTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
 	rosettaBlock, err := parser.ParseRosettaBlock(ctx, block)
 	if err != nil {
 		return xerrors.Errorf("failed to parse raw block to rosetta block: %w", err)
@@ -34,10 +56,38 @@ func (t rosettaBlocksTable) TransformBlock(ctx context.Context, block *chainstor
 
 	rosettaBlockData := rosettaBlock.GetBlock()
 	if rosettaBlockData == nil {
-		return xerrors.New("failed to extract ethereum block from rosetta block")
+		return xerrors.New("failed to extract block from rosetta block")
+	}
+
+	if err := transformBlocks(recordBuilder, rosettaBlockData, partitioner, t.encoder); err != nil {
+		return xerrors.Errorf("failed to transform blocks: %w", err)
+	}
+
+	return nil
+}
+
+func NewRosettaStreamedBlocksTable(params internal.CommonTableParams) internal.Table {
+	return internal.NewStreamTable(
+		&params,
+		internal.NewTableAttributes(internal.TableNameStreamedBlocks, internal.WithFormat(constant.TableFormatRosetta)),
+		newStreamedBlockSchema(),
+		rosettaStreamedBlocksTable{},
+		params.Params.Config.Table.StreamTable,
+	)
+}
+
+func (t rosettaStreamedBlocksTable) TransformBlock(ctx context.Context, blockAndEvent *internal.BlockAndEvent, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
+	rosettaBlock, err := parser.ParseRosettaBlock(ctx, blockAndEvent.Block)
+	if err != nil {
+		return xerrors.Errorf("failed to parse raw block to rosetta block: %w", err)
+	}
+
+	block := rosettaBlock.GetBlock()
+	if block == nil {
+		return xerrors.New("failed to extract block from rosetta block")
 	}
 
-	if err := transformBlocks(recordBuilder, rosettaBlockData, partitionBySize); err != nil {
+	if err := transformStreamedBlocks(recordBuilder, block, blockAndEvent.BlockChainEvent, partitioner); err != nil {
 		return xerrors.Errorf("failed to transform blocks: %w", err)
 	}
 
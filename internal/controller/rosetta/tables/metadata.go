@@ -0,0 +1,263 @@
+package tables
+
+import (
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apache/arrow/go/v10/arrow"
+	"golang.org/x/xerrors"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/coinbase/chainsformer/internal/config"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+type (
+	// metadataEncoder encodes the rosetta metadata either as a JSON string,
+	// or, for the typed encoding, as a map<string, string> followed by the promoted metadata columns.
+	metadataEncoder struct {
+		typed   bool
+		columns []config.MetadataColumnConfig
+	}
+
+	encodedMetadata struct {
+		encoder metadataEncoder
+		json    string
+		values  map[string]string
+	}
+)
+
+var (
+	jsonMetadataEncoder = metadataEncoder{}
+)
+
+func newTypedMetadataEncoder(columns []config.MetadataColumnConfig) metadataEncoder {
+	return metadataEncoder{
+		typed:   true,
+		columns: columns,
+	}
+}
+
+func (e metadataEncoder) encode(metadata map[string]*anypb.Any) (*encodedMetadata, error) {
+	if !e.typed {
+		res, err := toMetadata(metadata)
+		if err != nil {
+			return nil, err
+		}
+
+		return &encodedMetadata{encoder: e, json: res}, nil
+	}
+
+	values, err := toMetadataValues(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return &encodedMetadata{encoder: e, values: values}, nil
+}
+
+// appendRecord appends the metadata column, followed by the promoted metadata columns if any.
+func (m *encodedMetadata) appendRecord(ra *xarrow.RecordAppender) {
+	if !m.encoder.typed {
+		ra.AppendString(m.json)
+		return
+	}
+
+	ra.AppendMap(m.appendValues)
+	for _, column := range m.encoder.columns {
+		appendMetadataColumn(ra, column, m.values)
+	}
+}
+
+// appendStruct appends the metadata field of a struct, e.g. an operation.
+// The promoted metadata columns only apply to the top-level metadata.
+func (m *encodedMetadata) appendStruct(sa *xarrow.StructAppender) {
+	if !m.encoder.typed {
+		sa.AppendString(m.json)
+		return
+	}
+
+	sa.AppendMap(m.appendValues)
+}
+
+func (m *encodedMetadata) appendValues(ma *xarrow.MapAppender) {
+	keys := make([]string, 0, len(m.values))
+	for key := range m.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		ma.AppendString(key, m.values[key])
+	}
+}
+
+// withTypedMetadata changes the type of the metadata field to map<string, string>,
+// and inserts the promoted metadata columns after it.
+func withTypedMetadata(fields []arrow.Field, columns []config.MetadataColumnConfig) []arrow.Field {
+	f := xarrow.NewSchemaFactory()
+	res := make([]arrow.Field, 0, len(fields)+len(columns))
+	for _, field := range fields {
+		if field.Name != "metadata" {
+			res = append(res, field)
+			continue
+		}
+
+		field.Type = f.NewMap(arrow.BinaryTypes.String, arrow.BinaryTypes.String)
+		res = append(res, field)
+		for _, column := range columns {
+			res = append(res, f.NewField(column.GetName(), metadataColumnDataType(column.Type), "The value of the metadata key "+column.Key))
+		}
+	}
+
+	return res
+}
+
+func metadataColumnDataType(columnType string) arrow.DataType {
+	switch columnType {
+	case config.MetadataColumnTypeInt64:
+		return arrow.PrimitiveTypes.Int64
+	case config.MetadataColumnTypeUint64:
+		return arrow.PrimitiveTypes.Uint64
+	case config.MetadataColumnTypeFloat64:
+		return arrow.PrimitiveTypes.Float64
+	case config.MetadataColumnTypeBool:
+		return arrow.FixedWidthTypes.Boolean
+	case config.MetadataColumnTypeDecimal:
+		return xarrow.DecimalTypes.Decimal128
+	default:
+		return arrow.BinaryTypes.String
+	}
+}
+
+// appendMetadataColumn appends the value of a promoted metadata column,
+// or a null if the key is missing or its value cannot be converted to the type of the column.
+// Integers are parsed as decimal, or as hex if prefixed by 0x.
+func appendMetadataColumn(ra *xarrow.RecordAppender, column config.MetadataColumnConfig, values map[string]string) {
+	value, ok := values[column.Key]
+	if !ok {
+		ra.AppendNull()
+		return
+	}
+
+	switch column.Type {
+	case config.MetadataColumnTypeInt64:
+		if v, ok := parseMetadataInt(value); ok && v.IsInt64() {
+			ra.AppendInt64(v.Int64())
+			return
+		}
+	case config.MetadataColumnTypeUint64:
+		if v, ok := parseMetadataInt(value); ok && v.IsUint64() {
+			ra.AppendUint64(v.Uint64())
+			return
+		}
+	case config.MetadataColumnTypeFloat64:
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			ra.AppendFloat64(v)
+			return
+		}
+	case config.MetadataColumnTypeBool:
+		if v, err := strconv.ParseBool(value); err == nil {
+			ra.AppendBool(v)
+			return
+		}
+	case config.MetadataColumnTypeDecimal:
+		if v, ok := parseMetadataInt(value); ok {
+			if d, err := xarrow.Decimal128FromString(v.String()); err == nil {
+				ra.AppendDecimal128(d)
+				return
+			}
+		}
+	default:
+		ra.AppendString(value)
+		return
+	}
+
+	ra.AppendNull()
+}
+
+func parseMetadataInt(value string) (*big.Int, bool) {
+	if hex := strings.TrimPrefix(value, "0x"); hex != value {
+		return new(big.Int).SetString(hex, 16)
+	}
+
+	return new(big.Int).SetString(value, 10)
+}
+
+// toMetadataValues converts the metadata into strings.
+// The string, number and bool values are unwrapped, while the other values are marshaled into JSON.
+// The null values are skipped.
+func toMetadataValues(metadata map[string]*anypb.Any) (map[string]string, error) {
+	res := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		if v == nil {
+			continue
+		}
+
+		message, err := v.UnmarshalNew()
+		if err != nil {
+			return nil, xerrors.Errorf("failed to unmarshal metadata at key %v: %w", k, err)
+		}
+
+		if isNullMetadataValue(message) {
+			continue
+		}
+
+		value, ok := unwrapMetadataValue(message)
+		if !ok {
+			data, err := protojson.Marshal(message)
+			if err != nil {
+				return nil, xerrors.Errorf("failed to marshal metadata at key %v: %w", k, err)
+			}
+
+			value = string(data)
+		}
+
+		res[k] = value
+	}
+
+	return res, nil
+}
+
+func isNullMetadataValue(message proto.Message) bool {
+	value, ok := message.(*structpb.Value)
+	if !ok {
+		return false
+	}
+
+	_, ok = value.GetKind().(*structpb.Value_NullValue)
+	return ok
+}
+
+// unwrapMetadataValue returns the string representation of the scalar values.
+func unwrapMetadataValue(message proto.Message) (string, bool) {
+	switch m := message.(type) {
+	case *structpb.Value:
+		switch kind := m.GetKind().(type) {
+		case *structpb.Value_StringValue:
+			return kind.StringValue, true
+		case *structpb.Value_NumberValue:
+			return strconv.FormatFloat(kind.NumberValue, 'f', -1, 64), true
+		case *structpb.Value_BoolValue:
+			return strconv.FormatBool(kind.BoolValue), true
+		}
+	case *wrapperspb.StringValue:
+		return m.GetValue(), true
+	case *wrapperspb.Int64Value:
+		return strconv.FormatInt(m.GetValue(), 10), true
+	case *wrapperspb.UInt64Value:
+		return strconv.FormatUint(m.GetValue(), 10), true
+	case *wrapperspb.DoubleValue:
+		return strconv.FormatFloat(m.GetValue(), 'f', -1, 64), true
+	case *wrapperspb.BoolValue:
+		return strconv.FormatBool(m.GetValue()), true
+	}
+
+	return "", false
+}
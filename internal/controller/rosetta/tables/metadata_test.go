@@ -0,0 +1,135 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v10/arrow/array"
+	"github.com/apache/arrow/go/v10/arrow/memory"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	rosettaType "github.com/coinbase/chainstorage/protos/coinbase/crypto/rosetta/types"
+
+	"github.com/coinbase/chainsformer/internal/config"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+func TestTransformTypedBlocks(t *testing.T) {
+	require := require.New(t)
+
+	block := newTestBlock()
+	block.ParentBlockIdentifier = &rosettaType.BlockIdentifier{Index: 99, Hash: "0xabb"}
+	block.Metadata = map[string]*anypb.Any{
+		"gas_limit":  newTestMetadataValue(t, structpb.NewStringValue("0x1c9c380")),
+		"gas_used":   newTestMetadataValue(t, structpb.NewNumberValue(21000)),
+		"difficulty": newTestMetadataValue(t, structpb.NewStringValue("invalid")),
+		"extra":      newTestMetadataValue(t, structpb.NewListValue(&structpb.ListValue{Values: []*structpb.Value{structpb.NewBoolValue(true)}})),
+		"nil":        newTestMetadataValue(t, structpb.NewNullValue()),
+	}
+	columns := []config.MetadataColumnConfig{
+		{Key: "gas_limit", Type: config.MetadataColumnTypeUint64},
+		{Key: "gas_used", Name: "gas", Type: config.MetadataColumnTypeInt64},
+		{Key: "difficulty", Type: config.MetadataColumnTypeDecimal},
+		{Key: "unknown", Type: config.MetadataColumnTypeString},
+	}
+	schema := newTypedBlockSchema(columns)
+	recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), schema, nil)
+	defer recordBuilder.Release()
+
+	require.NoError(transformBlocks(recordBuilder, block, partition.NewPartitioner(partition.StrategyHeight, 0), newTypedMetadataEncoder(columns)))
+
+	rec := recordBuilder.NewRecord()
+	defer rec.Release()
+	require.Equal(int64(1), rec.NumRows())
+
+	metadata := rec.Column(schema.FieldIndices("metadata")[0]).(*array.Map)
+	keys := metadata.Keys().(*array.String)
+	items := metadata.Items().(*array.String)
+	require.Equal(4, keys.Len())
+	expected := map[string]string{
+		"difficulty": "invalid",
+		"extra":      "[true]",
+		"gas_limit":  "0x1c9c380",
+		"gas_used":   "21000",
+	}
+	for i := 0; i < keys.Len(); i++ {
+		require.Equal(expected[keys.Value(i)], items.Value(i))
+	}
+
+	require.Equal(uint64(30000000), rec.Column(schema.FieldIndices("metadata_gas_limit")[0]).(*array.Uint64).Value(0))
+	require.Equal(int64(21000), rec.Column(schema.FieldIndices("gas")[0]).(*array.Int64).Value(0))
+	require.True(rec.Column(schema.FieldIndices("metadata_difficulty")[0]).IsNull(0))
+	require.True(rec.Column(schema.FieldIndices("metadata_unknown")[0]).IsNull(0))
+	require.Equal(uint64(100), rec.Column(schema.FieldIndices("_repartition_by_range")[0]).(*array.Uint64).Value(0))
+}
+
+func TestTransformTypedTransactions(t *testing.T) {
+	require := require.New(t)
+
+	block := newTestBlock()
+	block.Transactions[0].Metadata = map[string]*anypb.Any{
+		"size": newTestMetadataValue(t, structpb.NewNumberValue(250)),
+	}
+	block.Transactions[0].Operations[0].Metadata = map[string]*anypb.Any{
+		"error": newTestMetadataValue(t, structpb.NewStringValue("reverted")),
+	}
+	columns := []config.MetadataColumnConfig{
+		{Key: "size", Type: config.MetadataColumnTypeFloat64},
+	}
+	schema := newTypedTransactionSchema(columns)
+	recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), schema, nil)
+	defer recordBuilder.Release()
+
+	require.NoError(transformTransactions(recordBuilder, block, partition.NewPartitioner(partition.StrategyHeight, 0), newTypedMetadataEncoder(columns)))
+
+	rec := recordBuilder.NewRecord()
+	defer rec.Release()
+	require.Equal(int64(2), rec.NumRows())
+
+	metadata := rec.Column(schema.FieldIndices("metadata")[0]).(*array.Map)
+	require.Equal("size", metadata.Keys().(*array.String).Value(0))
+	require.Equal("250", metadata.Items().(*array.String).Value(0))
+	require.True(metadata.IsNull(1))
+
+	size := rec.Column(schema.FieldIndices("metadata_size")[0]).(*array.Float64)
+	require.Equal(float64(250), size.Value(0))
+	require.True(size.IsNull(1))
+
+	operations := rec.Column(schema.FieldIndices("operations")[0]).(*array.List).ListValues().(*array.Struct)
+	operationMetadata := operations.Field(operations.NumField() - 1).(*array.Map)
+	require.Equal(3, operationMetadata.Len())
+	require.Equal("error", operationMetadata.Keys().(*array.String).Value(0))
+	require.Equal("reverted", operationMetadata.Items().(*array.String).Value(0))
+	require.True(operationMetadata.IsNull(1))
+	require.True(operationMetadata.IsNull(2))
+}
+
+func TestTransformTransactions_InvalidOperationMetadata(t *testing.T) {
+	require := require.New(t)
+
+	block := newTestBlock()
+	block.Transactions[1].Operations[0].Metadata = map[string]*anypb.Any{
+		"unknown": {TypeUrl: "type.googleapis.com/unknown.Type"},
+	}
+	schema := newTransactionSchema()
+	recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), schema, nil)
+	defer recordBuilder.Release()
+
+	require.Error(transformTransactions(recordBuilder, block, partition.NewPartitioner(partition.StrategyHeight, 0), jsonMetadataEncoder))
+
+	// The failed transaction must not leave a partially appended row behind.
+	rec := recordBuilder.NewRecord()
+	defer rec.Release()
+	require.Equal(int64(1), rec.NumRows())
+	for i := 0; i < int(rec.NumCols()); i++ {
+		require.Equal(1, rec.Column(i).Len())
+	}
+}
+
+func newTestMetadataValue(t *testing.T, value *structpb.Value) *anypb.Any {
+	res, err := anypb.New(value)
+	require.NoError(t, err)
+	return res
+}
@@ -13,4 +13,32 @@ var Module = fx.Options(
 		Group:  "rosetta",
 		Target: NewRosettaBlocksTable,
 	}),
+	fx.Provide(fx.Annotated{
+		Group:  "rosetta",
+		Target: NewTypedRosettaTransactionsTable,
+	}),
+	fx.Provide(fx.Annotated{
+		Group:  "rosetta",
+		Target: NewTypedRosettaBlocksTable,
+	}),
+	fx.Provide(fx.Annotated{
+		Group:  "rosetta",
+		Target: NewRosettaStreamedTransactionsTable,
+	}),
+	fx.Provide(fx.Annotated{
+		Group:  "rosetta",
+		Target: NewRosettaStreamedBlocksTable,
+	}),
+	fx.Provide(fx.Annotated{
+		Group:  "rosetta",
+		Target: NewRawRosettaStreamedTransactionsTable,
+	}),
+	fx.Provide(fx.Annotated{
+		Group:  "rosetta",
+		Target: NewRosettaOperationsTable,
+	}),
+	fx.Provide(fx.Annotated{
+		Group:  "rosetta",
+		Target: NewRosettaStreamedOperationsTable,
+	}),
 )
@@ -0,0 +1,75 @@
+package tables
+
+import (
+	"context"
+
+	"golang.org/x/xerrors"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+	"github.com/coinbase/chainstorage/sdk"
+
+	"github.com/coinbase/chainsformer/internal/controller/internal"
+	"github.com/coinbase/chainsformer/internal/controller/internal/constant"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+type (
+	rosettaOperationsTable         struct{}
+	rosettaStreamedOperationsTable struct{}
+)
+
+func NewRosettaOperationsTable(params internal.CommonTableParams) internal.Table {
+	return internal.NewBatchTable(
+		&params,
+		internal.NewTableAttributes(internal.TableNameOperations, internal.WithFormat(constant.TableFormatRosetta)),
+		newOperationSchema(),
+		rosettaOperationsTable{},
+	)
+}
+
+func (t rosettaOperationsTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
+	rosettaBlock, err := parser.ParseRosettaBlock(ctx, block)
+	if err != nil {
+		return xerrors.Errorf("failed to parse raw block to rosetta block: %w", err)
+	}
+
+	rosettaBlockData := rosettaBlock.GetBlock()
+	if rosettaBlockData == nil {
+		return xerrors.New("failed to extract block from rosetta block")
+	}
+
+	if err := transformOperationRows(recordBuilder, rosettaBlockData, partitioner); err != nil {
+		return xerrors.Errorf("failed to transform operations: %w", err)
+	}
+
+	return nil
+}
+
+func NewRosettaStreamedOperationsTable(params internal.CommonTableParams) internal.Table {
+	return internal.NewStreamTable(
+		&params,
+		internal.NewTableAttributes(internal.TableNameStreamedOperations, internal.WithFormat(constant.TableFormatRosetta)),
+		newStreamedOperationSchema(),
+		rosettaStreamedOperationsTable{},
+		params.Params.Config.Table.StreamTable,
+	)
+}
+
+func (t rosettaStreamedOperationsTable) TransformBlock(ctx context.Context, blockAndEvent *internal.BlockAndEvent, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
+	rosettaBlock, err := parser.ParseRosettaBlock(ctx, blockAndEvent.Block)
+	if err != nil {
+		return xerrors.Errorf("failed to parse raw block to rosetta block: %w", err)
+	}
+
+	rosettaBlockData := rosettaBlock.GetBlock()
+	if rosettaBlockData == nil {
+		return xerrors.New("failed to extract block from rosetta block")
+	}
+
+	if err := transformStreamedOperationRows(recordBuilder, rosettaBlockData, blockAndEvent.BlockChainEvent, partitioner); err != nil {
+		return xerrors.Errorf("failed to transform operations: %w", err)
+	}
+
+	return nil
+}
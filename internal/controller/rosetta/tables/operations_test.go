@@ -0,0 +1,104 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v10/arrow/array"
+	"github.com/apache/arrow/go/v10/arrow/memory"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+	rosettaType "github.com/coinbase/chainstorage/protos/coinbase/crypto/rosetta/types"
+
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+func TestTransformOperationRows(t *testing.T) {
+	require := require.New(t)
+
+	block := newTestBlock()
+	schema := newOperationSchema()
+	recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), schema, nil)
+	defer recordBuilder.Release()
+
+	require.NoError(transformOperationRows(recordBuilder, block, partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+	rec := recordBuilder.NewRecord()
+	defer rec.Release()
+	require.Equal(int64(3), rec.NumRows())
+
+	amountValue := rec.Column(schema.FieldIndices("amount_value")[0]).(*array.Decimal128)
+	require.Equal("-1000", amountValue.Value(0).BigInt().String())
+	require.Equal("1000", amountValue.Value(1).BigInt().String())
+	require.True(amountValue.IsNull(2))
+	require.Equal("invalid", rec.Column(schema.FieldIndices("amount_string")[0]).(*array.String).Value(2))
+
+	expectedTransactionIndex := []uint64{0, 0, 1}
+	expectedAddress := []string{"0xa", "0xb", "0xc"}
+	for i := range expectedAddress {
+		require.Equal(expectedAddress[i], rec.Column(schema.FieldIndices("account_address")[0]).(*array.String).Value(i))
+		require.Equal(expectedTransactionIndex[i], rec.Column(schema.FieldIndices("transaction_index")[0]).(*array.Uint64).Value(i))
+		require.Equal("ETH", rec.Column(schema.FieldIndices("amount_symbol")[0]).(*array.String).Value(i))
+		require.Equal(uint64(18), rec.Column(schema.FieldIndices("amount_decimals")[0]).(*array.Uint64).Value(i))
+		require.Equal(uint64(100), rec.Column(schema.FieldIndices("block_number")[0]).(*array.Uint64).Value(i))
+	}
+}
+
+func TestTransformStreamedOperationRows(t *testing.T) {
+	require := require.New(t)
+
+	block := newTestBlock()
+	event := &chainstorageapi.BlockchainEvent{
+		SequenceNum: 42,
+		Type:        chainstorageapi.BlockchainEvent_BLOCK_REMOVED,
+	}
+	schema := newStreamedOperationSchema()
+	recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), schema, nil)
+	defer recordBuilder.Release()
+
+	require.NoError(transformStreamedOperationRows(recordBuilder, block, event, partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+	rec := recordBuilder.NewRecord()
+	defer rec.Release()
+	require.Equal(int64(3), rec.NumRows())
+	for i := 0; i < 3; i++ {
+		require.Equal(int64(42), rec.Column(schema.FieldIndices("_sequence_number")[0]).(*array.Int64).Value(i))
+		require.Equal("BLOCK_REMOVED", rec.Column(schema.FieldIndices("_event_type")[0]).(*array.String).Value(i))
+		require.Equal(uint64(42), rec.Column(schema.FieldIndices("_repartition_by_range")[0]).(*array.Uint64).Value(i))
+	}
+}
+
+func newTestBlock() *rosettaType.Block {
+	eth := &rosettaType.Currency{Symbol: "ETH", Decimals: 18}
+	newOperation := func(index int64, address string, value string) *rosettaType.Operation {
+		return &rosettaType.Operation{
+			OperationIdentifier: &rosettaType.OperationIdentifier{Index: index},
+			Type:                "CALL",
+			Status:              "SUCCESS",
+			Account:             &rosettaType.AccountIdentifier{Address: address},
+			Amount:              &rosettaType.Amount{Value: value, Currency: eth},
+		}
+	}
+
+	return &rosettaType.Block{
+		BlockIdentifier: &rosettaType.BlockIdentifier{Index: 100, Hash: "0xabc"},
+		Timestamp:       &timestamppb.Timestamp{Seconds: 1700000000},
+		Transactions: []*rosettaType.Transaction{
+			{
+				TransactionIdentifier: &rosettaType.TransactionIdentifier{Hash: "0x1"},
+				Operations: []*rosettaType.Operation{
+					newOperation(0, "0xa", "-1000"),
+					newOperation(1, "0xb", "1000"),
+				},
+			},
+			{
+				TransactionIdentifier: &rosettaType.TransactionIdentifier{Hash: "0x2"},
+				Operations: []*rosettaType.Operation{
+					newOperation(0, "0xc", "invalid"),
+				},
+			},
+		},
+	}
+}
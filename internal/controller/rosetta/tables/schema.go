@@ -3,6 +3,8 @@ package tables
 import (
 	"github.com/apache/arrow/go/v10/arrow"
 
+	"github.com/coinbase/chainsformer/internal/config"
+	"github.com/coinbase/chainsformer/internal/controller/internal"
 	"github.com/coinbase/chainsformer/internal/utils/xarrow"
 )
 
@@ -23,6 +25,51 @@ func newTransactionSchema() *arrow.Schema {
 	)
 }
 
+func newStreamedTransactionSchema() *arrow.Schema {
+	return internal.NewStreamedSchema(newTransactionSchema())
+}
+
+func newTypedTransactionSchema(columns []config.MetadataColumnConfig) *arrow.Schema {
+	f := xarrow.NewSchemaFactory()
+	fields := newTransactionSchema().Fields()
+	for i, field := range fields {
+		if field.Name == "operations" {
+			fields[i].Type = f.NewList(newTypedOperationDataType())
+		}
+	}
+
+	return f.NewSchema(withTypedMetadata(fields, columns)...)
+}
+
+func newOperationSchema() *arrow.Schema {
+	f := xarrow.NewSchemaFactory()
+	fields := append(
+		newOperationDataType().(*arrow.StructType).Fields(),
+		f.NewField("transaction_hash", arrow.BinaryTypes.String, "Hash of the transaction where this operation was in"),
+		f.NewField("transaction_index", arrow.PrimitiveTypes.Uint64, "Zero-based index of the transaction"),
+		f.NewField("block_hash", arrow.BinaryTypes.String, "Hash of the block where this operation was in"),
+		f.NewField("block_number", arrow.PrimitiveTypes.Uint64, "Block number where this operation was in"),
+		f.NewField("block_timestamp", arrow.PrimitiveTypes.Uint64, "The unix timestamp for when the block was collated"),
+		f.NewField("_partition_by", arrow.PrimitiveTypes.Uint64, "Records with the same _partition_by value will be stored in the same s3 directory"),
+		f.NewField("_repartition_by_range", arrow.PrimitiveTypes.Uint64, "Records will be range partitioned base on the _repartition_by_range column"),
+	)
+	return f.NewSchema(fields...)
+}
+
+func newStreamedOperationSchema() *arrow.Schema {
+	operationSchema := newOperationSchema()
+	f := xarrow.NewSchemaFactory()
+
+	metadataFields := []arrow.Field{
+		f.NewField("_sequence_number", arrow.PrimitiveTypes.Int64, "Monotonically increasing event sequence number"),
+		f.NewField("_event_type", arrow.BinaryTypes.String, "Event type UNKNOWN, BLOCK_ADDED, BLOCK_REMOVED"),
+	}
+
+	return f.NewSchema(
+		append(metadataFields, operationSchema.Fields()...)...,
+	)
+}
+
 func newBlockSchema() *arrow.Schema {
 	f := xarrow.NewSchemaFactory()
 	return f.NewSchema(
@@ -39,6 +86,15 @@ func newBlockSchema() *arrow.Schema {
 	)
 }
 
+func newStreamedBlockSchema() *arrow.Schema {
+	return internal.NewStreamedSchema(newBlockSchema())
+}
+
+func newTypedBlockSchema(columns []config.MetadataColumnConfig) *arrow.Schema {
+	f := xarrow.NewSchemaFactory()
+	return f.NewSchema(withTypedMetadata(newBlockSchema().Fields(), columns)...)
+}
+
 func newRawTransactionSchema() *arrow.Schema {
 	f := xarrow.NewSchemaFactory()
 	transaction := f.NewSchema(
@@ -102,6 +158,11 @@ func newOperationDataType() arrow.DataType {
 	)
 }
 
+func newTypedOperationDataType() arrow.DataType {
+	f := xarrow.NewSchemaFactory()
+	return f.NewStruct(withTypedMetadata(newOperationDataType().(*arrow.StructType).Fields(), nil)...)
+}
+
 func newRelatedOperationDataType() arrow.DataType {
 	f := xarrow.NewSchemaFactory()
 
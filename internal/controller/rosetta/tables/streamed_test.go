@@ -0,0 +1,108 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v10/arrow/array"
+	"github.com/apache/arrow/go/v10/arrow/memory"
+	"github.com/stretchr/testify/require"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+	rosettaType "github.com/coinbase/chainstorage/protos/coinbase/crypto/rosetta/types"
+
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+func TestTransformStreamedTransactions(t *testing.T) {
+	tests := []struct {
+		name      string
+		eventType chainstorageapi.BlockchainEvent_Type
+	}{
+		{
+			name:      "block_added",
+			eventType: chainstorageapi.BlockchainEvent_BLOCK_ADDED,
+		},
+		{
+			name:      "block_removed",
+			eventType: chainstorageapi.BlockchainEvent_BLOCK_REMOVED,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			block := newTestBlock()
+			event := &chainstorageapi.BlockchainEvent{
+				SequenceNum: 42,
+				Type:        test.eventType,
+			}
+			schema := newStreamedTransactionSchema()
+			recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), schema, nil)
+			defer recordBuilder.Release()
+
+			require.NoError(transformStreamedTransactions(recordBuilder, block, event, partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+			rec := recordBuilder.NewRecord()
+			defer rec.Release()
+			require.Equal(int64(2), rec.NumRows())
+
+			expectedHash := []string{"0x1", "0x2"}
+			expectedOperationCount := []uint64{2, 1}
+			for i := range expectedHash {
+				require.Equal(int64(42), rec.Column(schema.FieldIndices("_sequence_number")[0]).(*array.Int64).Value(i))
+				require.Equal(test.eventType.String(), rec.Column(schema.FieldIndices("_event_type")[0]).(*array.String).Value(i))
+				require.Equal(expectedHash[i], rec.Column(schema.FieldIndices("transaction_hash")[0]).(*array.String).Value(i))
+				require.Equal(uint64(i), rec.Column(schema.FieldIndices("transaction_index")[0]).(*array.Uint64).Value(i))
+				require.Equal("0xabc", rec.Column(schema.FieldIndices("block_hash")[0]).(*array.String).Value(i))
+				require.Equal(uint64(100), rec.Column(schema.FieldIndices("block_number")[0]).(*array.Uint64).Value(i))
+				require.Equal(expectedOperationCount[i], rec.Column(schema.FieldIndices("operation_count")[0]).(*array.Uint64).Value(i))
+				require.Equal(uint64(42), rec.Column(schema.FieldIndices("_repartition_by_range")[0]).(*array.Uint64).Value(i))
+			}
+		})
+	}
+}
+
+func TestTransformStreamedBlocks(t *testing.T) {
+	tests := []struct {
+		name      string
+		eventType chainstorageapi.BlockchainEvent_Type
+	}{
+		{
+			name:      "block_added",
+			eventType: chainstorageapi.BlockchainEvent_BLOCK_ADDED,
+		},
+		{
+			name:      "block_removed",
+			eventType: chainstorageapi.BlockchainEvent_BLOCK_REMOVED,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			block := newTestBlock()
+			block.ParentBlockIdentifier = &rosettaType.BlockIdentifier{Index: 99, Hash: "0xabb"}
+			event := &chainstorageapi.BlockchainEvent{
+				SequenceNum: 42,
+				Type:        test.eventType,
+			}
+			schema := newStreamedBlockSchema()
+			recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), schema, nil)
+			defer recordBuilder.Release()
+
+			require.NoError(transformStreamedBlocks(recordBuilder, block, event, partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+			rec := recordBuilder.NewRecord()
+			defer rec.Release()
+			require.Equal(int64(1), rec.NumRows())
+			require.Equal(int64(42), rec.Column(schema.FieldIndices("_sequence_number")[0]).(*array.Int64).Value(0))
+			require.Equal(test.eventType.String(), rec.Column(schema.FieldIndices("_event_type")[0]).(*array.String).Value(0))
+			require.Equal("0xabc", rec.Column(schema.FieldIndices("hash")[0]).(*array.String).Value(0))
+			require.Equal("0xabb", rec.Column(schema.FieldIndices("parent_hash")[0]).(*array.String).Value(0))
+			require.Equal(uint64(100), rec.Column(schema.FieldIndices("number")[0]).(*array.Uint64).Value(0))
+			require.Equal(uint64(2), rec.Column(schema.FieldIndices("transaction_count")[0]).(*array.Uint64).Value(0))
+			require.Equal(uint64(42), rec.Column(schema.FieldIndices("_repartition_by_range")[0]).(*array.Uint64).Value(0))
+		})
+	}
+}
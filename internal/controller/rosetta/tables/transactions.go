@@ -3,7 +3,6 @@ package tables
 import (
 	"context"
 
-	"github.com/apache/arrow/go/v10/arrow/array"
 	"golang.org/x/xerrors"
 
 	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
@@ -11,13 +10,28 @@ import (
 
 	"github.com/coinbase/chainsformer/internal/controller/internal"
 	"github.com/coinbase/chainsformer/internal/controller/internal/constant"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
 )
 
 type (
-	rosettaTransactionsTable            struct{}
+	rosettaTransactionsTable struct {
+		encoder metadataEncoder
+	}
+	rosettaStreamedTransactionsTable    struct{}
 	rawRosettaStreamedTransactionsTable struct{}
 )
 
+func NewRosettaStreamedTransactionsTable(params internal.CommonTableParams) internal.Table {
+	return internal.NewStreamTable(
+		&params,
+		internal.NewTableAttributes(internal.TableNameStreamedTransactions, internal.WithFormat(constant.TableFormatRosetta)),
+		newStreamedTransactionSchema(),
+		rosettaStreamedTransactionsTable{},
+		params.Params.Config.Table.StreamTable,
+	)
+}
+
 func NewRawRosettaStreamedTransactionsTable(params internal.CommonTableParams) internal.Table {
 	return internal.NewStreamTable(
 		&params,
@@ -35,11 +49,26 @@ func NewRosettaTransactionsTable(params internal.CommonTableParams) internal.Tab
 		&params,
 		internal.NewTableAttributes(internal.TableNameTransactions, internal.WithFormat(constant.TableFormatRosetta)),
 		newTransactionSchema(),
-		rosettaTransactionsTable{},
+		rosettaTransactionsTable{encoder: jsonMetadataEncoder},
 	)
 }
 
-func (t rosettaTransactionsTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *array.RecordBuilder, partitionBySize uint64) error {
+// NewTypedRosettaTransactionsTable creates the transactions table of the typed encoding,
+// whose metadata, including the metadata of the operations, is a map<string, string>.
+// The transaction metadata is followed by the promoted metadata columns.
+func NewTypedRosettaTransactionsTable(params internal.CommonTableParams) internal.Table {
+	columns := params.Params.Config.Table.Rosetta.TransactionMetadataColumns
+	return internal.NewBatchTable(
+		&params,
+		internal.NewTableAttributes(internal.TableNameTransactions,
+			internal.WithFormat(constant.TableFormatRosetta),
+			internal.WithEncoding(constant.EncodingTyped)),
+		newTypedTransactionSchema(columns),
+		rosettaTransactionsTable{encoder: newTypedMetadataEncoder(columns)},
+	)
+}
+
+func (t rosettaTransactionsTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
 	rosettaBlock, err := parser.ParseRosettaBlock(ctx, block)
 	if err != nil {
 		return xerrors.Errorf("failed to parse raw block to rosetta block: %w", err)
@@ -47,17 +76,35 @@ func (t rosettaTransactionsTable) TransformBlock(ctx context.Context, block *cha
 
 	rosettaBlockData := rosettaBlock.GetBlock()
 	if rosettaBlockData == nil {
-		return xerrors.New("failed to extract ethereum block from rosetta block")
+		return xerrors.New("failed to extract block from rosetta block")
+	}
+
+	if err := transformTransactions(recordBuilder, rosettaBlockData, partitioner, t.encoder); err != nil {
+		return xerrors.Errorf("failed to transform transactions: %w", err)
+	}
+
+	return nil
+}
+
+func (t rosettaStreamedTransactionsTable) TransformBlock(ctx context.Context, blockAndEvent *internal.BlockAndEvent, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
+	rosettaBlock, err := parser.ParseRosettaBlock(ctx, blockAndEvent.Block)
+	if err != nil {
+		return xerrors.Errorf("failed to parse raw block to rosetta block: %w", err)
+	}
+
+	block := rosettaBlock.GetBlock()
+	if block == nil {
+		return xerrors.New("failed to extract block from rosetta block")
 	}
 
-	if err := transformTransactions(recordBuilder, rosettaBlockData, partitionBySize); err != nil {
+	if err := transformStreamedTransactions(recordBuilder, block, blockAndEvent.BlockChainEvent, partitioner); err != nil {
 		return xerrors.Errorf("failed to transform transactions: %w", err)
 	}
 
 	return nil
 }
 
-func (t rawRosettaStreamedTransactionsTable) TransformBlock(ctx context.Context, blockAndEvent *internal.BlockAndEvent, parser sdk.Parser, recordBuilder *array.RecordBuilder, partitionBySize uint64) error {
+func (t rawRosettaStreamedTransactionsTable) TransformBlock(ctx context.Context, blockAndEvent *internal.BlockAndEvent, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
 	rosettaBlock, err := parser.ParseRosettaBlock(ctx, blockAndEvent.Block)
 	if err != nil {
 		return xerrors.Errorf("failed to parse raw block to rosetta block: %w", err)
@@ -68,7 +115,7 @@ func (t rawRosettaStreamedTransactionsTable) TransformBlock(ctx context.Context,
 		return xerrors.New("failed to extract rosetta block from raw block")
 	}
 
-	if err := transformRawRosettaStreamedTransactions(recordBuilder, block, blockAndEvent.BlockChainEvent, partitionBySize); err != nil {
+	if err := transformRawRosettaStreamedTransactions(recordBuilder, block, blockAndEvent.BlockChainEvent, partitioner); err != nil {
 		return xerrors.Errorf("failed to transform transactions: %w", err)
 	}
 
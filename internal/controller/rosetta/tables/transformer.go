@@ -3,7 +3,6 @@ package tables
 import (
 	"encoding/json"
 
-	"github.com/apache/arrow/go/v10/arrow/array"
 	"github.com/golang/protobuf/proto"
 	"golang.org/x/xerrors"
 	"google.golang.org/protobuf/encoding/protojson"
@@ -18,71 +17,106 @@ import (
 	"github.com/coinbase/chainsformer/internal/utils/xarrow"
 )
 
-func transformTransactions(recordBuilder *array.RecordBuilder, block *rosettaType.Block, partitionBySize uint64) error {
-	transactions := block.GetTransactions()
-	if len(transactions) == 0 {
-		return nil
-	}
+func transformTransactions(recordBuilder *xarrow.RecordBuilder, block *rosettaType.Block, partitioner *partition.Partitioner, encoder metadataEncoder) error {
+	partitionBy := partitioner.GetPartitionBy(uint64(block.GetBlockIdentifier().Index), 0, block.GetTimestamp().AsTime())
+	return appendTransactions(recordBuilder, block, encoder, nil, partitionBy, uint64(block.GetBlockIdentifier().Index))
+}
 
-	for transactionIndex, transaction := range transactions {
-		transactionMetadata, err := toMetadata(transaction.Metadata)
+func transformStreamedTransactions(recordBuilder *xarrow.RecordBuilder, block *rosettaType.Block, event *chainstorageapi.BlockchainEvent, partitioner *partition.Partitioner) error {
+	partitionBy := partitioner.GetPartitionBy(uint64(block.GetBlockIdentifier().Index), event.GetSequenceNum(), block.GetTimestamp().AsTime())
+	return appendTransactions(recordBuilder, block, jsonMetadataEncoder, appendEventMetadata(event), partitionBy, uint64(event.GetSequenceNum()))
+}
+
+// appendTransactions appends one row per transaction, preceded by the columns of appendMetadata if any.
+func appendTransactions(recordBuilder *xarrow.RecordBuilder, block *rosettaType.Block, encoder metadataEncoder, appendMetadata func(ra *xarrow.RecordAppender), partitionBy uint64, repartitionByRange uint64) error {
+	for transactionIndex, transaction := range block.GetTransactions() {
+		transactionMetadata, err := encoder.encode(transaction.Metadata)
 		if err != nil {
 			return xerrors.New("failed to marshal transaction metadata to string")
 		}
 
-		xarrow.NewRecordAppender(recordBuilder).
-			AppendString(transaction.GetTransactionIdentifier().Hash).
+		operationsMetadata, err := encodeOperationsMetadata(transaction, encoder)
+		if err != nil {
+			return err
+		}
+
+		ra := xarrow.NewRecordAppender(recordBuilder)
+		if appendMetadata != nil {
+			appendMetadata(ra)
+		}
+
+		ra.AppendString(transaction.GetTransactionIdentifier().Hash).
 			AppendUint64(uint64(transactionIndex)).
 			AppendString(block.GetBlockIdentifier().Hash).
 			AppendUint64(uint64(block.GetBlockIdentifier().Index)).
 			AppendUint64(uint64(block.GetTimestamp().Seconds)).
 			AppendList(func(la *xarrow.ListAppender) {
-				err = transformOperations(la, transaction)
+				transformOperations(la, transaction, operationsMetadata)
 			}).
 			AppendUint64(uint64(len(transaction.GetOperations()))).
 			AppendList(func(la *xarrow.ListAppender) {
 				transformRelatedTransactions(la, transaction)
-			}).
-			AppendString(transactionMetadata).
-			AppendUint64(partition.GetPartitionByNumber(uint64(block.GetBlockIdentifier().Index), partitionBySize)).
-			AppendUint64(uint64(block.GetBlockIdentifier().Index)).
+			})
+		transactionMetadata.appendRecord(ra)
+		ra.AppendUint64(partitionBy).
+			AppendUint64(repartitionByRange).
 			Build()
-
-		if err != nil {
-			return err
-		}
 	}
 
 	return nil
 }
 
-func transformBlocks(recordBuilder *array.RecordBuilder, block *rosettaType.Block, partitionBySize uint64) error {
-	metadata, err := toMetadata(block.Metadata)
+func transformBlocks(recordBuilder *xarrow.RecordBuilder, block *rosettaType.Block, partitioner *partition.Partitioner, encoder metadataEncoder) error {
+	partitionBy := partitioner.GetPartitionBy(uint64(block.GetBlockIdentifier().Index), 0, block.GetTimestamp().AsTime())
+	return appendBlock(recordBuilder, block, encoder, nil, partitionBy, uint64(block.GetBlockIdentifier().Index))
+}
+
+func transformStreamedBlocks(recordBuilder *xarrow.RecordBuilder, block *rosettaType.Block, event *chainstorageapi.BlockchainEvent, partitioner *partition.Partitioner) error {
+	partitionBy := partitioner.GetPartitionBy(uint64(block.GetBlockIdentifier().Index), event.GetSequenceNum(), block.GetTimestamp().AsTime())
+	return appendBlock(recordBuilder, block, jsonMetadataEncoder, appendEventMetadata(event), partitionBy, uint64(event.GetSequenceNum()))
+}
+
+// appendBlock appends the row of the block, preceded by the columns of appendMetadata if any.
+func appendBlock(recordBuilder *xarrow.RecordBuilder, block *rosettaType.Block, encoder metadataEncoder, appendMetadata func(ra *xarrow.RecordAppender), partitionBy uint64, repartitionByRange uint64) error {
+	metadata, err := encoder.encode(block.Metadata)
 	if err != nil {
 		return xerrors.New("failed to marshal block metadata to string")
 	}
 
-	xarrow.NewRecordAppender(recordBuilder).
-		AppendString(block.GetBlockIdentifier().Hash).
-		AppendString(block.GetParentBlockIdentifier().Hash).
-		AppendUint64(uint64(block.GetBlockIdentifier().Index)).
-		AppendUint64(uint64(block.GetParentBlockIdentifier().Index)).
-		AppendUint64(uint64(block.GetTimestamp().Seconds)).
+	ra := xarrow.NewRecordAppender(recordBuilder)
+	if appendMetadata != nil {
+		appendMetadata(ra)
+	}
+
+	ra.AppendString(block.GetBlockIdentifier().GetHash()).
+		AppendString(block.GetParentBlockIdentifier().GetHash()).
+		AppendUint64(uint64(block.GetBlockIdentifier().GetIndex())).
+		AppendUint64(uint64(block.GetParentBlockIdentifier().GetIndex())).
+		AppendUint64(uint64(block.GetTimestamp().GetSeconds())).
 		AppendUint64(uint64(len(block.GetTransactions()))).
 		AppendList(func(la *xarrow.ListAppender) {
 			for _, transaction := range block.Transactions {
 				la.AppendString(transaction.GetTransactionIdentifier().Hash)
 			}
-		}).
-		AppendString(metadata).
-		AppendUint64(partition.GetPartitionByNumber(uint64(block.GetBlockIdentifier().Index), partitionBySize)).
-		AppendUint64(uint64(block.GetBlockIdentifier().Index)).
+		})
+	metadata.appendRecord(ra)
+	ra.AppendUint64(partitionBy).
+		AppendUint64(repartitionByRange).
 		Build()
 
 	return nil
 }
 
-func transformRawRosettaStreamedTransactions(recordBuilder *array.RecordBuilder, block *rosettaType.Block, event *chainstorageapi.BlockchainEvent, partitionBySize uint64) error {
+// appendEventMetadata appends the _sequence_number and _event_type columns of the streamed tables.
+// The rows of a BLOCK_REMOVED event carry the identifiers of the removed block, so that they can be retracted.
+func appendEventMetadata(event *chainstorageapi.BlockchainEvent) func(ra *xarrow.RecordAppender) {
+	return func(ra *xarrow.RecordAppender) {
+		ra.AppendInt64(event.GetSequenceNum()).
+			AppendString(event.GetType().String())
+	}
+}
+
+func transformRawRosettaStreamedTransactions(recordBuilder *xarrow.RecordBuilder, block *rosettaType.Block, event *chainstorageapi.BlockchainEvent, partitioner *partition.Partitioner) error {
 	transactions := block.GetTransactions()
 	if len(transactions) == 0 {
 		return nil
@@ -100,7 +134,7 @@ func transformRawRosettaStreamedTransactions(recordBuilder *array.RecordBuilder,
 			AppendStruct(transformStreamedBlock(block)).
 			AppendUint64(uint64(i)).
 			AppendBinary(data).
-			AppendUint64(partition.GetPartitionByNumber(uint64(event.GetSequenceNum()), partitionBySize)).
+			AppendUint64(partitioner.GetPartitionBy(uint64(block.GetBlockIdentifier().Index), event.GetSequenceNum(), block.GetTimestamp().AsTime())).
 			AppendUint64(uint64(event.GetSequenceNum())).
 			Build()
 	}
@@ -108,6 +142,67 @@ func transformRawRosettaStreamedTransactions(recordBuilder *array.RecordBuilder,
 	return nil
 }
 
+func transformOperationRows(recordBuilder *xarrow.RecordBuilder, block *rosettaType.Block, partitioner *partition.Partitioner) error {
+	partitionBy := partitioner.GetPartitionBy(uint64(block.GetBlockIdentifier().Index), 0, block.GetTimestamp().AsTime())
+	return appendOperations(recordBuilder, block, nil, partitionBy, uint64(block.GetBlockIdentifier().Index))
+}
+
+func transformStreamedOperationRows(recordBuilder *xarrow.RecordBuilder, block *rosettaType.Block, event *chainstorageapi.BlockchainEvent, partitioner *partition.Partitioner) error {
+	partitionBy := partitioner.GetPartitionBy(uint64(block.GetBlockIdentifier().Index), event.GetSequenceNum(), block.GetTimestamp().AsTime())
+	return appendOperations(recordBuilder, block, appendEventMetadata(event), partitionBy, uint64(event.GetSequenceNum()))
+}
+
+// appendOperations appends one row per operation, preceded by the columns of appendMetadata if any.
+func appendOperations(recordBuilder *xarrow.RecordBuilder, block *rosettaType.Block, appendMetadata func(ra *xarrow.RecordAppender), partitionBy uint64, repartitionByRange uint64) error {
+	for transactionIndex, transaction := range block.GetTransactions() {
+		for _, operation := range transaction.GetOperations() {
+			metadata, err := toMetadata(operation.Metadata)
+			if err != nil {
+				return xerrors.Errorf("failed to marshal operation metadata to string: %w", err)
+			}
+
+			ra := xarrow.NewRecordAppender(recordBuilder)
+			if appendMetadata != nil {
+				appendMetadata(ra)
+			}
+
+			ra.AppendUint64(uint64(operation.GetOperationIdentifier().GetIndex())).
+				AppendUint64(uint64(operation.GetOperationIdentifier().GetNetworkIndex())).
+				AppendList(func(la *xarrow.ListAppender) {
+					transformRelatedOperations(la, operation)
+				}).
+				AppendString(operation.Type).
+				AppendString(operation.Status).
+				AppendString(operation.GetAccount().GetAddress()).
+				AppendString(operation.GetAccount().GetSubAccount().GetAddress())
+
+			value, err := xarrow.Decimal128FromString(operation.GetAmount().GetValue())
+			if err != nil {
+				ra.AppendDecimal128Null()
+			} else {
+				ra.AppendDecimal128(value)
+			}
+
+			ra.AppendString(operation.GetAmount().GetValue()).
+				AppendString(operation.GetAmount().GetCurrency().GetSymbol()).
+				AppendUint64(uint64(operation.GetAmount().GetCurrency().GetDecimals())).
+				AppendString(operation.GetCoinChange().GetCoinIdentifier().GetIdentifier()).
+				AppendString(operation.GetCoinChange().GetCoinAction().String()).
+				AppendString(metadata).
+				AppendString(transaction.GetTransactionIdentifier().GetHash()).
+				AppendUint64(uint64(transactionIndex)).
+				AppendString(block.GetBlockIdentifier().GetHash()).
+				AppendUint64(uint64(block.GetBlockIdentifier().GetIndex())).
+				AppendUint64(uint64(block.GetTimestamp().GetSeconds())).
+				AppendUint64(partitionBy).
+				AppendUint64(repartitionByRange).
+				Build()
+		}
+	}
+
+	return nil
+}
+
 func transformStreamedBlock(block *rosettaType.Block) func(*xarrow.StructAppender) {
 	return func(sa *xarrow.StructAppender) {
 		sa.AppendStruct(transformBlockIdentifier(block.GetBlockIdentifier())).
@@ -123,13 +218,25 @@ func transformBlockIdentifier(id *rosettaType.BlockIdentifier) func(sa *xarrow.S
 	}
 }
 
-func transformOperations(la *xarrow.ListAppender, transaction *rosettaType.Transaction) error {
-	for _, operation := range transaction.Operations {
-		metadata, err := toMetadata(operation.Metadata)
+// encodeOperationsMetadata encodes the metadata of the operations of the transaction,
+// so that an invalid metadata fails the transaction before any of its columns is appended.
+func encodeOperationsMetadata(transaction *rosettaType.Transaction, encoder metadataEncoder) ([]*encodedMetadata, error) {
+	operationsMetadata := make([]*encodedMetadata, len(transaction.Operations))
+	for i, operation := range transaction.Operations {
+		metadata, err := encoder.encode(operation.Metadata)
 		if err != nil {
-			return xerrors.New("failed to marshal operation metadata to string")
+			return nil, xerrors.New("failed to marshal operation metadata to string")
 		}
 
+		operationsMetadata[i] = metadata
+	}
+
+	return operationsMetadata, nil
+}
+
+func transformOperations(la *xarrow.ListAppender, transaction *rosettaType.Transaction, operationsMetadata []*encodedMetadata) {
+	for i, operation := range transaction.Operations {
+		metadata := operationsMetadata[i]
 		la.AppendStruct(func(sa *xarrow.StructAppender) {
 			sa.AppendUint64(uint64(operation.OperationIdentifier.Index)).
 				AppendUint64(uint64(operation.OperationIdentifier.NetworkIndex)).
@@ -152,12 +259,10 @@ func transformOperations(la *xarrow.ListAppender, transaction *rosettaType.Trans
 				AppendString(operation.GetAmount().GetCurrency().GetSymbol()).
 				AppendUint64(uint64(operation.GetAmount().GetCurrency().GetDecimals())).
 				AppendString(operation.GetCoinChange().GetCoinIdentifier().GetIdentifier()).
-				AppendString(operation.GetCoinChange().GetCoinAction().String()).
-				AppendString(metadata)
+				AppendString(operation.GetCoinChange().GetCoinAction().String())
+			metadata.appendStruct(sa)
 		})
 	}
-
-	return nil
 }
 
 func transformRelatedOperations(la *xarrow.ListAppender, operation *rosettaType.Operation) {
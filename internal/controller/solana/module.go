@@ -0,0 +1,15 @@
+package solana
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/coinbase/chainsformer/internal/controller/solana/tables"
+)
+
+var Module = fx.Options(
+	fx.Provide(fx.Annotated{
+		Name:   "solana",
+		Target: NewController,
+	}),
+	tables.Module,
+)
@@ -0,0 +1,57 @@
+package tables
+
+import (
+	"context"
+
+	"golang.org/x/xerrors"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+	"github.com/coinbase/chainstorage/sdk"
+
+	"github.com/coinbase/chainsformer/internal/controller/internal"
+	"github.com/coinbase/chainsformer/internal/controller/internal/constant"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+type (
+	blocksTable struct{}
+)
+
+func NewBlocksTable(params internal.CommonTableParams) internal.Table {
+	return internal.NewBatchTable(
+		&params,
+		internal.NewTableAttributes(internal.TableNameBlocks, internal.WithEstimateMode(constant.EstimateModeBlocks)),
+		newBlockSchema(),
+		blocksTable{},
+	)
+}
+
+func (t blocksTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
+	solanaBlock, err := parseSolanaBlock(ctx, block, parser)
+	if err != nil {
+		return xerrors.Errorf("failed to parse solana block: %w", err)
+	}
+
+	if err := transformBlocks(recordBuilder, solanaBlock, partitioner); err != nil {
+		return xerrors.Errorf("failed to transform blocks: %w", err)
+	}
+
+	return nil
+}
+
+// parseSolanaBlock parses the raw block into the solana block of the native format.
+// The skipped slots do not have any block and are not passed to the transformers by the batch tables.
+func parseSolanaBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser) (*chainstorageapi.SolanaBlockV2, error) {
+	nativeBlock, err := parser.ParseNativeBlock(ctx, block)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to parse raw block to native block: %w", err)
+	}
+
+	solanaBlock := nativeBlock.GetSolanaV2()
+	if solanaBlock == nil {
+		return nil, xerrors.New("failed to extract solana block from native block")
+	}
+
+	return solanaBlock, nil
+}
@@ -0,0 +1,24 @@
+package tables
+
+import (
+	"go.uber.org/fx"
+)
+
+var Module = fx.Options(
+	fx.Provide(fx.Annotated{
+		Group:  "solana",
+		Target: NewBlocksTable,
+	}),
+	fx.Provide(fx.Annotated{
+		Group:  "solana",
+		Target: NewTransactionsTable,
+	}),
+	fx.Provide(fx.Annotated{
+		Group:  "solana",
+		Target: NewInstructionsTable,
+	}),
+	fx.Provide(fx.Annotated{
+		Group:  "solana",
+		Target: NewRewardsTable,
+	}),
+)
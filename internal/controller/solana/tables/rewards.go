@@ -0,0 +1,40 @@
+package tables
+
+import (
+	"context"
+
+	"golang.org/x/xerrors"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+	"github.com/coinbase/chainstorage/sdk"
+
+	"github.com/coinbase/chainsformer/internal/controller/internal"
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+type (
+	rewardsTable struct{}
+)
+
+func NewRewardsTable(params internal.CommonTableParams) internal.Table {
+	return internal.NewBatchTable(
+		&params,
+		internal.NewTableAttributes(internal.TableNameRewards),
+		newRewardSchema(),
+		rewardsTable{},
+	)
+}
+
+func (t rewardsTable) TransformBlock(ctx context.Context, block *chainstorageapi.Block, parser sdk.Parser, recordBuilder *xarrow.RecordBuilder, filter internal.Filter, partitioner *partition.Partitioner) error {
+	solanaBlock, err := parseSolanaBlock(ctx, block, parser)
+	if err != nil {
+		return xerrors.Errorf("failed to parse solana block: %w", err)
+	}
+
+	if err := transformRewards(recordBuilder, solanaBlock, partitioner); err != nil {
+		return xerrors.Errorf("failed to transform rewards: %w", err)
+	}
+
+	return nil
+}
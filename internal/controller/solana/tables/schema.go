@@ -0,0 +1,105 @@
+package tables
+
+import (
+	"github.com/apache/arrow/go/v10/arrow"
+
+	"github.com/coinbase/chainsformer/internal/controller/internal"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+func newBlockSchema() *arrow.Schema {
+	f := xarrow.NewSchemaFactory()
+	return internal.NewTableSchema(
+		internal.RepartitionByRangeDescription,
+		f.NewField("hash", arrow.BinaryTypes.String, "Hash of the block"),
+		f.NewField("parent_hash", arrow.BinaryTypes.String, "Hash of the parent block"),
+		f.NewField("slot", arrow.PrimitiveTypes.Uint64, "The slot of the block"),
+		f.NewField("parent_slot", arrow.PrimitiveTypes.Uint64, "The slot of the parent block, which may be lower than slot-1 if slots were skipped"),
+		f.NewField("block_height", arrow.PrimitiveTypes.Uint64, "The number of blocks beneath this block"),
+		f.NewField("timestamp", arrow.PrimitiveTypes.Uint64, "The unix timestamp for when the block was produced"),
+		f.NewField("transaction_count", arrow.PrimitiveTypes.Uint64, "The number of transactions in the block"),
+		f.NewField("reward_count", arrow.PrimitiveTypes.Uint64, "The number of rewards in the block"),
+	)
+}
+
+func newTransactionSchema() *arrow.Schema {
+	f := xarrow.NewSchemaFactory()
+	return internal.NewTableSchema(
+		internal.RepartitionByRangeDescription,
+		f.NewField("transaction_id", arrow.BinaryTypes.String, "The first signature of the transaction"),
+		f.NewField("transaction_index", arrow.PrimitiveTypes.Uint64, "Zero-based index of the transaction in the block"),
+		f.NewField("version", arrow.PrimitiveTypes.Int32, "The version of the transaction, -1 for the legacy transactions"),
+		f.NewField("signatures", arrow.ListOf(arrow.BinaryTypes.String), "The signatures of the transaction"),
+		f.NewField("recent_block_hash", arrow.BinaryTypes.String, "The recent block hash used to prevent duplicated transactions"),
+		f.NewField("account_keys", arrow.ListOf(newAccountKeyDataType()), "The accounts used by the transaction"),
+		f.NewField("error", arrow.BinaryTypes.String, "The error of the transaction, empty if the transaction succeeded"),
+		f.NewField("fee", arrow.PrimitiveTypes.Uint64, "The fee charged for the transaction in lamports"),
+		f.NewField("pre_balances", arrow.ListOf(arrow.PrimitiveTypes.Uint64), "The balances of the accounts before the transaction, in the order of account_keys"),
+		f.NewField("post_balances", arrow.ListOf(arrow.PrimitiveTypes.Uint64), "The balances of the accounts after the transaction, in the order of account_keys"),
+		f.NewField("pre_token_balances", arrow.ListOf(newTokenBalanceDataType()), "The token balances of the accounts before the transaction"),
+		f.NewField("post_token_balances", arrow.ListOf(newTokenBalanceDataType()), "The token balances of the accounts after the transaction"),
+		f.NewField("log_messages", arrow.ListOf(arrow.BinaryTypes.String), "The log messages of the transaction"),
+		f.NewField("instruction_count", arrow.PrimitiveTypes.Uint64, "The number of top-level instructions in the transaction"),
+		f.NewField("block_hash", arrow.BinaryTypes.String, "Hash of the block where this transaction was in"),
+		f.NewField("block_slot", arrow.PrimitiveTypes.Uint64, "Slot of the block where this transaction was in"),
+		f.NewField("block_timestamp", arrow.PrimitiveTypes.Uint64, "The unix timestamp for when the block was produced"),
+	)
+}
+
+func newInstructionSchema() *arrow.Schema {
+	f := xarrow.NewSchemaFactory()
+	return internal.NewTableSchema(
+		internal.RepartitionByRangeDescription,
+		f.NewField("transaction_id", arrow.BinaryTypes.String, "The first signature of the transaction where this instruction was in"),
+		f.NewField("transaction_index", arrow.PrimitiveTypes.Uint64, "Zero-based index of the transaction in the block"),
+		f.NewField("instruction_index", arrow.PrimitiveTypes.Uint64, "Zero-based index of the top-level instruction, or of the top-level instruction invoking the inner instruction"),
+		f.NewField("inner_instruction_index", arrow.PrimitiveTypes.Uint64, "Zero-based index of the inner instruction, null for the top-level instructions"),
+		f.NewField("program", arrow.BinaryTypes.String, "The program parsed by ChainStorage, e.g. SYSTEM or SPL_TOKEN, or RAW if it is not parsed"),
+		f.NewField("program_id", arrow.BinaryTypes.String, "The address of the program"),
+		f.NewField("accounts", arrow.ListOf(arrow.BinaryTypes.String), "The accounts of the raw instruction, null for the parsed instructions"),
+		f.NewField("data", arrow.BinaryTypes.String, "The base58 encoded data of the raw instruction, null for the parsed instructions"),
+		f.NewField("instruction_type", arrow.BinaryTypes.String, "The type of the parsed instruction, e.g. TRANSFER, null for the raw instructions"),
+		f.NewField("parsed_instruction", arrow.BinaryTypes.String, "The JSON of the parsed instruction, null for the raw instructions"),
+		f.NewField("block_hash", arrow.BinaryTypes.String, "Hash of the block where this instruction was in"),
+		f.NewField("block_slot", arrow.PrimitiveTypes.Uint64, "Slot of the block where this instruction was in"),
+		f.NewField("block_timestamp", arrow.PrimitiveTypes.Uint64, "The unix timestamp for when the block was produced"),
+	)
+}
+
+func newRewardSchema() *arrow.Schema {
+	f := xarrow.NewSchemaFactory()
+	return internal.NewTableSchema(
+		internal.RepartitionByRangeDescription,
+		f.NewField("pubkey", arrow.BinaryTypes.String, "The account receiving the reward"),
+		f.NewField("lamports", arrow.PrimitiveTypes.Int64, "The reward in lamports, negative if debited"),
+		f.NewField("post_balance", arrow.PrimitiveTypes.Uint64, "The balance of the account after the reward in lamports"),
+		f.NewField("reward_type", arrow.BinaryTypes.String, "The type of the reward, e.g. Fee, Rent, Voting or Staking"),
+		f.NewField("commission", arrow.PrimitiveTypes.Uint64, "The vote account commission, only set for the voting and staking rewards"),
+		f.NewField("block_hash", arrow.BinaryTypes.String, "Hash of the block where this reward was in"),
+		f.NewField("block_slot", arrow.PrimitiveTypes.Uint64, "Slot of the block where this reward was in"),
+		f.NewField("block_timestamp", arrow.PrimitiveTypes.Uint64, "The unix timestamp for when the block was produced"),
+	)
+}
+
+func newAccountKeyDataType() arrow.DataType {
+	f := xarrow.NewSchemaFactory()
+	return f.NewStruct(
+		f.NewField("pubkey", arrow.BinaryTypes.String, "The address of the account"),
+		f.NewField("signer", arrow.FixedWidthTypes.Boolean, "True if the account signed the transaction"),
+		f.NewField("writable", arrow.FixedWidthTypes.Boolean, "True if the account is writable by the transaction"),
+		f.NewField("source", arrow.BinaryTypes.String, "The source of the account, transaction or lookupTable"),
+	)
+}
+
+func newTokenBalanceDataType() arrow.DataType {
+	f := xarrow.NewSchemaFactory()
+	return f.NewStruct(
+		f.NewField("account_index", arrow.PrimitiveTypes.Uint64, "The index of the account in account_keys"),
+		f.NewField("mint", arrow.BinaryTypes.String, "The mint address of the token"),
+		f.NewField("owner", arrow.BinaryTypes.String, "The owner of the token account"),
+		f.NewField("amount", xarrow.DecimalTypes.Decimal128, "The raw amount of tokens as decimal, null when it cannot be represented as decimal"),
+		f.NewField("amount_string", arrow.BinaryTypes.String, "The raw amount of tokens as string"),
+		f.NewField("decimals", arrow.PrimitiveTypes.Uint64, "The number of decimals of the token"),
+		f.NewField("ui_amount_string", arrow.BinaryTypes.String, "The amount of tokens as string, adjusted by the decimals"),
+	)
+}
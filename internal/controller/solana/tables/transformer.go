@@ -0,0 +1,286 @@
+package tables
+
+import (
+	"github.com/mr-tron/base58"
+	"golang.org/x/xerrors"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+const (
+	programDataOneof     = "program_data"
+	instructionOneof     = "instruction"
+	instructionTypeField = "instruction_type"
+)
+
+func transformBlocks(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.SolanaBlockV2, partitioner *partition.Partitioner) error {
+	header := block.GetHeader()
+	if header == nil {
+		return xerrors.New("header is required")
+	}
+
+	xarrow.NewRecordAppender(recordBuilder).
+		AppendString(header.BlockHash).
+		AppendString(header.PreviousBlockHash).
+		AppendUint64(header.Slot).
+		AppendUint64(header.ParentSlot).
+		AppendUint64(header.BlockHeight).
+		AppendUint64(uint64(header.GetBlockTime().GetSeconds())).
+		AppendUint64(uint64(len(block.GetTransactions()))).
+		AppendUint64(uint64(len(block.GetRewards()))).
+		AppendUint64(partitioner.GetPartitionBy(header.Slot, 0, header.GetBlockTime().AsTime())).
+		AppendUint64(header.Slot).
+		Build()
+
+	return nil
+}
+
+func transformTransactions(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.SolanaBlockV2, partitioner *partition.Partitioner) error {
+	header := block.GetHeader()
+	if header == nil {
+		return xerrors.New("header is required")
+	}
+
+	partitionBy := partitioner.GetPartitionBy(header.Slot, 0, header.GetBlockTime().AsTime())
+	for transactionIndex, transaction := range block.GetTransactions() {
+		message := transaction.GetPayload().GetMessage()
+		meta := transaction.GetMeta()
+		xarrow.NewRecordAppender(recordBuilder).
+			AppendString(transaction.TransactionId).
+			AppendUint64(uint64(transactionIndex)).
+			AppendInt32(transaction.Version).
+			AppendList(func(la *xarrow.ListAppender) {
+				for _, signature := range transaction.GetPayload().GetSignatures() {
+					la.AppendString(signature)
+				}
+			}).
+			AppendString(message.GetRecentBlockHash()).
+			AppendList(func(la *xarrow.ListAppender) {
+				transformAccountKeys(la, message.GetAccountKeys())
+			}).
+			AppendString(meta.GetErr()).
+			AppendUint64(meta.GetFee()).
+			AppendList(func(la *xarrow.ListAppender) {
+				for _, balance := range meta.GetPreBalances() {
+					la.AppendUint64(balance)
+				}
+			}).
+			AppendList(func(la *xarrow.ListAppender) {
+				for _, balance := range meta.GetPostBalances() {
+					la.AppendUint64(balance)
+				}
+			}).
+			AppendList(func(la *xarrow.ListAppender) {
+				transformTokenBalances(la, meta.GetPreTokenBalances())
+			}).
+			AppendList(func(la *xarrow.ListAppender) {
+				transformTokenBalances(la, meta.GetPostTokenBalances())
+			}).
+			AppendList(func(la *xarrow.ListAppender) {
+				for _, logMessage := range meta.GetLogMessages() {
+					la.AppendString(logMessage)
+				}
+			}).
+			AppendUint64(uint64(len(message.GetInstructions()))).
+			AppendString(header.BlockHash).
+			AppendUint64(header.Slot).
+			AppendUint64(uint64(header.GetBlockTime().GetSeconds())).
+			AppendUint64(partitionBy).
+			AppendUint64(header.Slot).
+			Build()
+	}
+
+	return nil
+}
+
+// transformInstructions appends one row per instruction, including the inner instructions invoked by the programs.
+// The inner instructions follow the top-level instruction invoking them.
+func transformInstructions(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.SolanaBlockV2, partitioner *partition.Partitioner) error {
+	header := block.GetHeader()
+	if header == nil {
+		return xerrors.New("header is required")
+	}
+
+	partitionBy := partitioner.GetPartitionBy(header.Slot, 0, header.GetBlockTime().AsTime())
+	for transactionIndex, transaction := range block.GetTransactions() {
+		innerInstructions := make(map[uint64][]*chainstorageapi.SolanaInstructionV2)
+		for _, inner := range transaction.GetMeta().GetInnerInstructions() {
+			innerInstructions[inner.Index] = append(innerInstructions[inner.Index], inner.Instructions...)
+		}
+
+		appendInstruction := func(instruction *chainstorageapi.SolanaInstructionV2, instructionIndex uint64, innerInstructionIndex *uint64) error {
+			ra := xarrow.NewRecordAppender(recordBuilder).
+				AppendString(transaction.TransactionId).
+				AppendUint64(uint64(transactionIndex)).
+				AppendUint64(instructionIndex)
+			if innerInstructionIndex != nil {
+				ra.AppendUint64(*innerInstructionIndex)
+			} else {
+				ra.AppendNull()
+			}
+
+			ra.AppendString(instruction.Program.String()).
+				AppendString(instruction.ProgramId)
+			if err := appendInstructionData(ra, instruction); err != nil {
+				return xerrors.Errorf("failed to append instruction data (transaction_id=%v, instruction_index=%v): %w", transaction.TransactionId, instructionIndex, err)
+			}
+
+			ra.AppendString(header.BlockHash).
+				AppendUint64(header.Slot).
+				AppendUint64(uint64(header.GetBlockTime().GetSeconds())).
+				AppendUint64(partitionBy).
+				AppendUint64(header.Slot).
+				Build()
+			return nil
+		}
+
+		for i, instruction := range transaction.GetPayload().GetMessage().GetInstructions() {
+			instructionIndex := uint64(i)
+			if err := appendInstruction(instruction, instructionIndex, nil); err != nil {
+				return err
+			}
+
+			for j, inner := range innerInstructions[instructionIndex] {
+				innerInstructionIndex := uint64(j)
+				if err := appendInstruction(inner, instructionIndex, &innerInstructionIndex); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func transformRewards(recordBuilder *xarrow.RecordBuilder, block *chainstorageapi.SolanaBlockV2, partitioner *partition.Partitioner) error {
+	header := block.GetHeader()
+	if header == nil {
+		return xerrors.New("header is required")
+	}
+
+	partitionBy := partitioner.GetPartitionBy(header.Slot, 0, header.GetBlockTime().AsTime())
+	for _, reward := range block.GetRewards() {
+		ra := xarrow.NewRecordAppender(recordBuilder).
+			AppendString(base58.Encode(reward.Pubkey)).
+			AppendInt64(reward.Lamports).
+			AppendUint64(reward.PostBalance).
+			AppendString(reward.RewardType)
+		if commission, ok := reward.GetOptionalCommission().(*chainstorageapi.SolanaReward_Commission); ok {
+			ra.AppendUint64(commission.Commission)
+		} else {
+			ra.AppendNull()
+		}
+
+		ra.AppendString(header.BlockHash).
+			AppendUint64(header.Slot).
+			AppendUint64(uint64(header.GetBlockTime().GetSeconds())).
+			AppendUint64(partitionBy).
+			AppendUint64(header.Slot).
+			Build()
+	}
+
+	return nil
+}
+
+// appendInstructionData appends the accounts and data of a raw instruction,
+// or the type and the JSON of an instruction parsed by ChainStorage.
+func appendInstructionData(ra *xarrow.RecordAppender, instruction *chainstorageapi.SolanaInstructionV2) error {
+	if raw := instruction.GetRawInstruction(); raw != nil {
+		ra.AppendList(func(la *xarrow.ListAppender) {
+			for _, account := range raw.Accounts {
+				la.AppendString(account)
+			}
+		}).
+			AppendString(base58.Encode(raw.Data)).
+			AppendNull().
+			AppendNull()
+		return nil
+	}
+
+	ra.AppendNull().
+		AppendNull()
+
+	// Every parsed program defines the instruction_type field and the instruction oneof.
+	program := getOneofMessage(instruction.ProtoReflect(), programDataOneof)
+	if program == nil {
+		ra.AppendNull().
+			AppendNull()
+		return nil
+	}
+
+	if field := program.Descriptor().Fields().ByName(instructionTypeField); field != nil && field.Enum() != nil {
+		if value := field.Enum().Values().ByNumber(program.Get(field).Enum()); value != nil {
+			ra.AppendString(string(value.Name()))
+		} else {
+			ra.AppendNull()
+		}
+	} else {
+		ra.AppendNull()
+	}
+
+	parsed := getOneofMessage(program, instructionOneof)
+	if parsed == nil {
+		ra.AppendNull()
+		return nil
+	}
+
+	data, err := protojson.Marshal(parsed.Interface())
+	if err != nil {
+		return xerrors.Errorf("failed to marshal parsed instruction: %w", err)
+	}
+
+	ra.AppendString(string(data))
+	return nil
+}
+
+// getOneofMessage returns the message set in the oneof of the given name, or nil if none is set.
+func getOneofMessage(message protoreflect.Message, name protoreflect.Name) protoreflect.Message {
+	oneof := message.Descriptor().Oneofs().ByName(name)
+	if oneof == nil {
+		return nil
+	}
+
+	field := message.WhichOneof(oneof)
+	if field == nil || field.Message() == nil {
+		return nil
+	}
+
+	return message.Get(field).Message()
+}
+
+func transformAccountKeys(la *xarrow.ListAppender, accountKeys []*chainstorageapi.AccountKey) {
+	for _, accountKey := range accountKeys {
+		la.AppendStruct(func(sa *xarrow.StructAppender) {
+			sa.AppendString(accountKey.Pubkey).
+				AppendBool(accountKey.Signer).
+				AppendBool(accountKey.Writable).
+				AppendString(accountKey.Source)
+		})
+	}
+}
+
+func transformTokenBalances(la *xarrow.ListAppender, tokenBalances []*chainstorageapi.SolanaTokenBalance) {
+	for _, tokenBalance := range tokenBalances {
+		la.AppendStruct(func(sa *xarrow.StructAppender) {
+			sa.AppendUint64(tokenBalance.AccountIndex).
+				AppendString(tokenBalance.Mint).
+				AppendString(tokenBalance.Owner)
+
+			amount, err := xarrow.Decimal128FromString(tokenBalance.GetTokenAmount().GetAmount())
+			if err != nil {
+				sa.AppendDecimal128Null()
+			} else {
+				sa.AppendDecimal128(amount)
+			}
+
+			sa.AppendString(tokenBalance.GetTokenAmount().GetAmount()).
+				AppendUint64(tokenBalance.GetTokenAmount().GetDecimals()).
+				AppendString(tokenBalance.GetTokenAmount().GetUiAmountString())
+		})
+	}
+}
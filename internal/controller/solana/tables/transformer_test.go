@@ -0,0 +1,246 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v10/arrow/array"
+	"github.com/apache/arrow/go/v10/arrow/memory"
+	"github.com/mr-tron/base58"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	chainstorageapi "github.com/coinbase/chainstorage/protos/coinbase/chainstorage"
+
+	"github.com/coinbase/chainsformer/internal/utils/partition"
+	"github.com/coinbase/chainsformer/internal/utils/xarrow"
+)
+
+func TestTransformBlocks(t *testing.T) {
+	require := require.New(t)
+
+	block := newTestBlock()
+	schema := newBlockSchema()
+	recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), schema, nil)
+	defer recordBuilder.Release()
+
+	require.NoError(transformBlocks(recordBuilder, block, partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+	rec := recordBuilder.NewRecord()
+	defer rec.Release()
+	require.Equal(int64(1), rec.NumRows())
+	require.Equal("hash", rec.Column(schema.FieldIndices("hash")[0]).(*array.String).Value(0))
+	require.Equal("parent", rec.Column(schema.FieldIndices("parent_hash")[0]).(*array.String).Value(0))
+	require.Equal(uint64(200), rec.Column(schema.FieldIndices("slot")[0]).(*array.Uint64).Value(0))
+	require.Equal(uint64(198), rec.Column(schema.FieldIndices("parent_slot")[0]).(*array.Uint64).Value(0))
+	require.Equal(uint64(1700000000), rec.Column(schema.FieldIndices("timestamp")[0]).(*array.Uint64).Value(0))
+	require.Equal(uint64(1), rec.Column(schema.FieldIndices("transaction_count")[0]).(*array.Uint64).Value(0))
+	require.Equal(uint64(2), rec.Column(schema.FieldIndices("reward_count")[0]).(*array.Uint64).Value(0))
+	require.Equal(uint64(200), rec.Column(schema.FieldIndices("_repartition_by_range")[0]).(*array.Uint64).Value(0))
+}
+
+func TestTransformBlocks_MissingHeader(t *testing.T) {
+	require := require.New(t)
+
+	recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), newBlockSchema(), nil)
+	defer recordBuilder.Release()
+
+	err := transformBlocks(recordBuilder, &chainstorageapi.SolanaBlockV2{}, partition.NewPartitioner(partition.StrategyHeight, 0))
+	require.Error(err)
+	require.Contains(err.Error(), "header is required")
+}
+
+func TestTransformTransactions(t *testing.T) {
+	require := require.New(t)
+
+	block := newTestBlock()
+	schema := newTransactionSchema()
+	recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), schema, nil)
+	defer recordBuilder.Release()
+
+	require.NoError(transformTransactions(recordBuilder, block, partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+	rec := recordBuilder.NewRecord()
+	defer rec.Release()
+	require.Equal(int64(1), rec.NumRows())
+	require.Equal("tx1", rec.Column(schema.FieldIndices("transaction_id")[0]).(*array.String).Value(0))
+	require.Equal(int32(-1), rec.Column(schema.FieldIndices("version")[0]).(*array.Int32).Value(0))
+	require.Equal(uint64(5000), rec.Column(schema.FieldIndices("fee")[0]).(*array.Uint64).Value(0))
+	require.Equal(uint64(2), rec.Column(schema.FieldIndices("instruction_count")[0]).(*array.Uint64).Value(0))
+	require.Equal("hash", rec.Column(schema.FieldIndices("block_hash")[0]).(*array.String).Value(0))
+
+	accountKeys := rec.Column(schema.FieldIndices("account_keys")[0]).(*array.List).ListValues().(*array.Struct)
+	require.Equal(2, accountKeys.Len())
+	require.Equal("signer", accountKeys.Field(0).(*array.String).Value(0))
+	require.True(accountKeys.Field(1).(*array.Boolean).Value(0))
+	require.False(accountKeys.Field(1).(*array.Boolean).Value(1))
+
+	tokenBalances := rec.Column(schema.FieldIndices("post_token_balances")[0]).(*array.List).ListValues().(*array.Struct)
+	require.Equal(1, tokenBalances.Len())
+	require.Equal("mint", tokenBalances.Field(1).(*array.String).Value(0))
+	require.Equal("1500000", tokenBalances.Field(4).(*array.String).Value(0))
+	require.Equal("1.5", tokenBalances.Field(6).(*array.String).Value(0))
+}
+
+func TestTransformInstructions(t *testing.T) {
+	require := require.New(t)
+
+	block := newTestBlock()
+	schema := newInstructionSchema()
+	recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), schema, nil)
+	defer recordBuilder.Release()
+
+	require.NoError(transformInstructions(recordBuilder, block, partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+	rec := recordBuilder.NewRecord()
+	defer rec.Release()
+	require.Equal(int64(3), rec.NumRows())
+
+	instructionIndex := rec.Column(schema.FieldIndices("instruction_index")[0]).(*array.Uint64)
+	innerInstructionIndex := rec.Column(schema.FieldIndices("inner_instruction_index")[0]).(*array.Uint64)
+	program := rec.Column(schema.FieldIndices("program")[0]).(*array.String)
+	data := rec.Column(schema.FieldIndices("data")[0]).(*array.String)
+	instructionType := rec.Column(schema.FieldIndices("instruction_type")[0]).(*array.String)
+	parsedInstruction := rec.Column(schema.FieldIndices("parsed_instruction")[0]).(*array.String)
+
+	// The raw top-level instruction.
+	require.Equal(uint64(0), instructionIndex.Value(0))
+	require.True(innerInstructionIndex.IsNull(0))
+	require.Equal("RAW", program.Value(0))
+	require.Equal(base58.Encode([]byte{1, 2, 3}), data.Value(0))
+	require.True(instructionType.IsNull(0))
+	require.True(parsedInstruction.IsNull(0))
+
+	// The inner instruction invoked by the raw top-level instruction.
+	require.Equal(uint64(0), instructionIndex.Value(1))
+	require.Equal(uint64(0), innerInstructionIndex.Value(1))
+	require.Equal("SYSTEM", program.Value(1))
+	require.True(data.IsNull(1))
+	require.Equal("TRANSFER", instructionType.Value(1))
+	require.JSONEq(`{"source":"a","destination":"b","lamports":"10"}`, parsedInstruction.Value(1))
+
+	// The parsed top-level instruction.
+	require.Equal(uint64(1), instructionIndex.Value(2))
+	require.True(innerInstructionIndex.IsNull(2))
+	require.Equal("SYSTEM", program.Value(2))
+	require.Equal("TRANSFER", instructionType.Value(2))
+}
+
+func TestTransformRewards(t *testing.T) {
+	require := require.New(t)
+
+	block := newTestBlock()
+	schema := newRewardSchema()
+	recordBuilder := xarrow.NewRecordBuilder(memory.NewGoAllocator(), schema, nil)
+	defer recordBuilder.Release()
+
+	require.NoError(transformRewards(recordBuilder, block, partition.NewPartitioner(partition.StrategyHeight, 0)))
+
+	rec := recordBuilder.NewRecord()
+	defer rec.Release()
+	require.Equal(int64(2), rec.NumRows())
+
+	pubkey := rec.Column(schema.FieldIndices("pubkey")[0]).(*array.String)
+	require.Equal(base58.Encode([]byte{4, 5, 6}), pubkey.Value(0))
+	require.Equal(int64(-100), rec.Column(schema.FieldIndices("lamports")[0]).(*array.Int64).Value(0))
+
+	commission := rec.Column(schema.FieldIndices("commission")[0]).(*array.Uint64)
+	require.True(commission.IsNull(0))
+	require.Equal(uint64(10), commission.Value(1))
+}
+
+func newTestBlock() *chainstorageapi.SolanaBlockV2 {
+	transfer := &chainstorageapi.SolanaInstructionV2{
+		Program:   chainstorageapi.SolanaProgram_SYSTEM,
+		ProgramId: "11111111111111111111111111111111",
+		ProgramData: &chainstorageapi.SolanaInstructionV2_SystemProgram{
+			SystemProgram: &chainstorageapi.SolanaSystemProgram{
+				InstructionType: chainstorageapi.SolanaSystemProgram_TRANSFER,
+				Instruction: &chainstorageapi.SolanaSystemProgram_Transfer{
+					Transfer: &chainstorageapi.SolanaSystemTransferInstruction{
+						Source:      "a",
+						Destination: "b",
+						Lamports:    10,
+					},
+				},
+			},
+		},
+	}
+
+	return &chainstorageapi.SolanaBlockV2{
+		Header: &chainstorageapi.SolanaHeader{
+			BlockHash:         "hash",
+			PreviousBlockHash: "parent",
+			Slot:              200,
+			ParentSlot:        198,
+			BlockTime:         &timestamppb.Timestamp{Seconds: 1700000000},
+			BlockHeight:       180,
+		},
+		Transactions: []*chainstorageapi.SolanaTransactionV2{
+			{
+				TransactionId: "tx1",
+				Version:       -1,
+				Payload: &chainstorageapi.SolanaTransactionPayloadV2{
+					Signatures: []string{"tx1"},
+					Message: &chainstorageapi.SolanaMessageV2{
+						AccountKeys: []*chainstorageapi.AccountKey{
+							{Pubkey: "signer", Signer: true, Writable: true, Source: "transaction"},
+							{Pubkey: "program", Source: "transaction"},
+						},
+						RecentBlockHash: "recent",
+						Instructions: []*chainstorageapi.SolanaInstructionV2{
+							{
+								Program:   chainstorageapi.SolanaProgram_RAW,
+								ProgramId: "program",
+								ProgramData: &chainstorageapi.SolanaInstructionV2_RawInstruction{
+									RawInstruction: &chainstorageapi.SolanaRawInstruction{
+										Accounts: []string{"signer"},
+										Data:     []byte{1, 2, 3},
+									},
+								},
+							},
+							transfer,
+						},
+					},
+				},
+				Meta: &chainstorageapi.SolanaTransactionMetaV2{
+					Fee:          5000,
+					PreBalances:  []uint64{100000, 1},
+					PostBalances: []uint64{95000, 1},
+					PostTokenBalances: []*chainstorageapi.SolanaTokenBalance{
+						{
+							AccountIndex: 0,
+							Mint:         "mint",
+							Owner:        "signer",
+							TokenAmount: &chainstorageapi.SolanaTokenAmount{
+								Amount:         "1500000",
+								Decimals:       6,
+								UiAmountString: "1.5",
+							},
+						},
+					},
+					InnerInstructions: []*chainstorageapi.SolanaInnerInstructionV2{
+						{
+							Index:        0,
+							Instructions: []*chainstorageapi.SolanaInstructionV2{transfer},
+						},
+					},
+				},
+			},
+		},
+		Rewards: []*chainstorageapi.SolanaReward{
+			{
+				Pubkey:      []byte{4, 5, 6},
+				Lamports:    -100,
+				PostBalance: 1000,
+				RewardType:  "Rent",
+			},
+			{
+				Pubkey:             []byte{7, 8, 9},
+				Lamports:           200,
+				PostBalance:        2000,
+				RewardType:         "Voting",
+				OptionalCommission: &chainstorageapi.SolanaReward_Commission{Commission: 10},
+			},
+		},
+	}
+}
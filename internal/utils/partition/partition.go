@@ -1,5 +1,59 @@
 package partition
 
+import (
+	"time"
+)
+
+type (
+	// Partitioner computes the _partition_by value of the records of a block.
+	// A nil Partitioner uses the height strategy without bucketing, i.e. always returns 0.
+	Partitioner struct {
+		strategy Strategy
+		size     uint64
+	}
+)
+
+func NewPartitioner(strategy Strategy, size uint64) *Partitioner {
+	return &Partitioner{
+		strategy: strategy,
+		size:     size,
+	}
+}
+
+func (p *Partitioner) Strategy() Strategy {
+	if p == nil {
+		return StrategyHeight
+	}
+
+	return p.strategy
+}
+
+func (p *Partitioner) Size() uint64 {
+	if p == nil {
+		return 0
+	}
+
+	return p.size
+}
+
+// GetPartitionBy returns the _partition_by value of the records of a block.
+// The height and sequence strategies bucket the number by the partition size,
+// while the day and hour strategies return the UTC time of the block as YYYYMMDD and YYYYMMDDHH.
+func (p *Partitioner) GetPartitionBy(height uint64, sequence int64, timestamp time.Time) uint64 {
+	switch p.Strategy() {
+	case StrategySequence:
+		return GetPartitionByNumber(uint64(sequence), p.Size())
+	case StrategyDay:
+		t := timestamp.UTC()
+		return uint64(t.Year()*10000 + int(t.Month())*100 + t.Day())
+	case StrategyHour:
+		t := timestamp.UTC()
+		return uint64((t.Year()*10000+int(t.Month())*100+t.Day())*100 + t.Hour())
+	default:
+		return GetPartitionByNumber(height, p.Size())
+	}
+}
+
 func GetPartitionByNumber(inputNumber uint64, partitionBySize uint64) (partitionByNumber uint64) {
 	if partitionBySize > 0 {
 		partitionByNumber = inputNumber / partitionBySize * partitionBySize
@@ -0,0 +1,49 @@
+package partition
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitioner(t *testing.T) {
+	timestamp := time.Date(2023, time.March, 7, 21, 30, 0, 0, time.FixedZone("UTC-5", -5*3600))
+	tests := []struct {
+		name        string
+		partitioner *Partitioner
+		expected    uint64
+	}{
+		{
+			name:        "nil",
+			partitioner: nil,
+			expected:    0,
+		},
+		{
+			name:        "height",
+			partitioner: NewPartitioner(StrategyHeight, 1000),
+			expected:    12000,
+		},
+		{
+			name:        "sequence",
+			partitioner: NewPartitioner(StrategySequence, 1000),
+			expected:    45000,
+		},
+		{
+			name:        "day",
+			partitioner: NewPartitioner(StrategyDay, 1000),
+			expected:    20230308,
+		},
+		{
+			name:        "hour",
+			partitioner: NewPartitioner(StrategyHour, 0),
+			expected:    2023030802,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+			require.Equal(test.expected, test.partitioner.GetPartitionBy(12345, 45678, timestamp))
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package partition
+
+//go:generate go-enum -f=$GOFILE --marshal
+type (
+	// ENUM(height, sequence, day, hour)
+	Strategy int
+)
@@ -45,6 +45,8 @@ var (
 		"bsc-mainnet",
 		"arbitrum-mainnet",
 		"optimism-mainnet",
+		"solana-mainnet",
+		"aptos-mainnet",
 	}
 )
 
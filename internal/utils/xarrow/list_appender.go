@@ -8,6 +8,7 @@ import (
 type (
 	ListAppender struct {
 		listBuilder *array.ListBuilder
+		projection  *Projection
 		index       int
 	}
 
@@ -15,8 +16,14 @@ type (
 )
 
 func NewListAppender(listBuilder *array.ListBuilder) *ListAppender {
+	return newListAppender(listBuilder, nil)
+}
+
+// newListAppender creates a ListAppender whose elements are built with the given projection.
+func newListAppender(listBuilder *array.ListBuilder, projection *Projection) *ListAppender {
 	return &ListAppender{
 		listBuilder: listBuilder,
+		projection:  projection,
 		index:       0,
 	}
 }
@@ -52,14 +59,14 @@ func (a *ListAppender) AppendDecimal128(value decimal128.Num) *ListAppender {
 }
 
 func (a *ListAppender) AppendStruct(cb func(sa *StructAppender)) *ListAppender {
-	sa := NewStructAppender(a.next().(*array.StructBuilder))
+	sa := newStructAppender(a.next().(*array.StructBuilder), a.projection)
 	cb(sa)
 	sa.build()
 	return a
 }
 
 func (a *ListAppender) AppendList(cb func(la *ListAppender)) *ListAppender {
-	la := NewListAppender(a.next().(*array.ListBuilder))
+	la := newListAppender(a.next().(*array.ListBuilder), a.projection)
 	cb(la)
 	la.build()
 	return a
@@ -0,0 +1,44 @@
+package xarrow
+
+import (
+	"github.com/apache/arrow/go/v10/arrow/array"
+)
+
+type (
+	// MapAppender appends the entries of a map.
+	// Like lists, a map without any entry is appended as a null.
+	MapAppender struct {
+		mapBuilder *array.MapBuilder
+		index      int
+	}
+)
+
+func NewMapAppender(mapBuilder *array.MapBuilder) *MapAppender {
+	return &MapAppender{
+		mapBuilder: mapBuilder,
+		index:      0,
+	}
+}
+
+// AppendString appends an entry to a map<string, string>.
+func (a *MapAppender) AppendString(key string, value string) *MapAppender {
+	a.next()
+	a.mapBuilder.KeyBuilder().(*array.StringBuilder).Append(key)
+	a.mapBuilder.ItemBuilder().(*array.StringBuilder).Append(value)
+	return a
+}
+
+func (a *MapAppender) next() {
+	if a.index == 0 {
+		a.mapBuilder.Append(true)
+	}
+	a.index += 1
+}
+
+func (a *MapAppender) build() {
+	if a.index == 0 {
+		a.mapBuilder.AppendNull()
+	}
+
+	a.index = 0
+}
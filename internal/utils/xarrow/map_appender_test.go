@@ -0,0 +1,65 @@
+package xarrow
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v10/arrow"
+	"github.com/apache/arrow/go/v10/arrow/array"
+	"github.com/apache/arrow/go/v10/arrow/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapAppender(t *testing.T) {
+	require := require.New(t)
+
+	f := NewSchemaFactory()
+	schema := f.NewSchema(
+		f.NewField("metadata", f.NewMap(arrow.BinaryTypes.String, arrow.BinaryTypes.String), "The metadata"),
+		f.NewField("receipt", f.NewStruct(
+			f.NewField("metadata", f.NewMap(arrow.BinaryTypes.String, arrow.BinaryTypes.String), "The receipt metadata"),
+		), "The receipt"),
+	)
+	recordBuilder := NewRecordBuilder(memory.NewGoAllocator(), schema, nil)
+	defer recordBuilder.Release()
+
+	NewRecordAppender(recordBuilder).
+		AppendMap(func(ma *MapAppender) {
+			ma.AppendString("a", "1").
+				AppendString("b", "2")
+		}).
+		AppendStruct(func(sa *StructAppender) {
+			sa.AppendMap(func(ma *MapAppender) {
+				ma.AppendString("c", "3")
+			})
+		}).
+		Build()
+	NewRecordAppender(recordBuilder).
+		AppendMap(func(ma *MapAppender) {}).
+		AppendStruct(func(sa *StructAppender) {
+			sa.AppendMap(func(ma *MapAppender) {})
+		}).
+		Build()
+
+	rec := recordBuilder.NewRecord()
+	defer rec.Release()
+	require.Equal(int64(2), rec.NumRows())
+
+	metadata := rec.Column(0).(*array.Map)
+	require.True(metadata.IsValid(0))
+	require.True(metadata.IsNull(1))
+	start, end := metadata.ValueOffsets(0)
+	require.Equal(int64(0), start)
+	require.Equal(int64(2), end)
+	keys := metadata.Keys().(*array.String)
+	items := metadata.Items().(*array.String)
+	require.Equal("a", keys.Value(0))
+	require.Equal("1", items.Value(0))
+	require.Equal("b", keys.Value(1))
+	require.Equal("2", items.Value(1))
+
+	receiptMetadata := rec.Column(1).(*array.Struct).Field(0).(*array.Map)
+	require.True(receiptMetadata.IsValid(0))
+	require.True(receiptMetadata.IsNull(1))
+	require.Equal("c", receiptMetadata.Keys().(*array.String).Value(0))
+	require.Equal("3", receiptMetadata.Items().(*array.String).Value(0))
+}
@@ -12,7 +12,7 @@ package xarrowmocks
 import (
 	reflect "reflect"
 
-	array "github.com/apache/arrow/go/v10/arrow/array"
+	xarrow "github.com/coinbase/chainsformer/internal/utils/xarrow"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -68,10 +68,10 @@ func (mr *MockTableWriterMockRecorder) Flush() *gomock.Call {
 }
 
 // RecordBuilder mocks base method.
-func (m *MockTableWriter) RecordBuilder() *array.RecordBuilder {
+func (m *MockTableWriter) RecordBuilder() *xarrow.RecordBuilder {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "RecordBuilder")
-	ret0, _ := ret[0].(*array.RecordBuilder)
+	ret0, _ := ret[0].(*xarrow.RecordBuilder)
 	return ret0
 }
 
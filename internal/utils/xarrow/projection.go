@@ -0,0 +1,157 @@
+package xarrow
+
+import (
+	"strings"
+
+	"github.com/apache/arrow/go/v10/arrow"
+	"golang.org/x/xerrors"
+)
+
+type (
+	// Projection maps the fields of a schema (or struct) to the fields of its projected counterpart.
+	// A nil Projection selects every field.
+	Projection struct {
+		// indexes[i] is the index of the i-th field in the projected fields, or -1 if the field is skipped.
+		indexes []int
+		// children[i] is the projection of the i-th field if it is a struct or a list of structs.
+		children []*Projection
+	}
+
+	projectionNode struct {
+		all      bool
+		children map[string]*projectionNode
+		names    []string
+	}
+)
+
+const (
+	columnPathSeparator = "."
+)
+
+// ProjectSchema returns the schema with only the given columns and the projection to be used by the appenders.
+// Nested columns are referenced by their paths, e.g. "receipt.status" or "receipt.logs.address".
+// An empty list of columns selects the whole schema.
+func ProjectSchema(schema *arrow.Schema, columns []string) (*arrow.Schema, *Projection, error) {
+	if len(columns) == 0 {
+		return schema, nil, nil
+	}
+
+	root := newProjectionNode()
+	for _, column := range columns {
+		if column == "" {
+			return nil, nil, xerrors.New("column cannot be empty")
+		}
+
+		root.add(strings.Split(column, columnPathSeparator))
+	}
+
+	fields, projection, err := projectFields(schema.Fields(), root, "")
+	if err != nil {
+		return nil, nil, xerrors.Errorf("failed to project schema: %w", err)
+	}
+
+	metadata := schema.Metadata()
+	return arrow.NewSchema(fields, &metadata), projection, nil
+}
+
+func projectFields(fields []arrow.Field, node *projectionNode, prefix string) ([]arrow.Field, *Projection, error) {
+	fieldByName := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		fieldByName[field.Name] = true
+	}
+	for _, name := range node.names {
+		if !fieldByName[name] {
+			return nil, nil, xerrors.Errorf("column not found: %v%v", prefix, name)
+		}
+	}
+
+	projection := &Projection{
+		indexes:  make([]int, len(fields)),
+		children: make([]*Projection, len(fields)),
+	}
+	var projectedFields []arrow.Field
+	for i, field := range fields {
+		child, ok := node.children[field.Name]
+		if !ok {
+			projection.indexes[i] = -1
+			continue
+		}
+
+		if !child.all {
+			dataType, childProjection, err := projectDataType(field.Type, child, prefix+field.Name+columnPathSeparator)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			field.Type = dataType
+			projection.children[i] = childProjection
+		}
+
+		projection.indexes[i] = len(projectedFields)
+		projectedFields = append(projectedFields, field)
+	}
+
+	return projectedFields, projection, nil
+}
+
+func projectDataType(dataType arrow.DataType, node *projectionNode, prefix string) (arrow.DataType, *Projection, error) {
+	switch dt := dataType.(type) {
+	case *arrow.StructType:
+		fields, projection, err := projectFields(dt.Fields(), node, prefix)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return arrow.StructOf(fields...), projection, nil
+	case *arrow.ListType:
+		// The projection of a list is the projection of its elements.
+		elem, projection, err := projectDataType(dt.Elem(), node, prefix)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return arrow.ListOf(elem), projection, nil
+	default:
+		return nil, nil, xerrors.Errorf("column is not a struct: %v", strings.TrimSuffix(prefix, columnPathSeparator))
+	}
+}
+
+func newProjectionNode() *projectionNode {
+	return &projectionNode{
+		children: make(map[string]*projectionNode),
+	}
+}
+
+func (n *projectionNode) add(path []string) {
+	if len(path) == 0 {
+		n.all = true
+		return
+	}
+
+	child, ok := n.children[path[0]]
+	if !ok {
+		child = newProjectionNode()
+		n.children[path[0]] = child
+		n.names = append(n.names, path[0])
+	}
+
+	child.add(path[1:])
+}
+
+// index returns the index of the i-th field in the projected fields, or -1 if the field is skipped.
+func (p *Projection) index(i int) int {
+	if p == nil {
+		return i
+	}
+
+	return p.indexes[i]
+}
+
+// child returns the projection of the i-th field.
+func (p *Projection) child(i int) *Projection {
+	if p == nil {
+		return nil
+	}
+
+	return p.children[i]
+}
@@ -0,0 +1,153 @@
+package xarrow
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v10/arrow"
+	"github.com/apache/arrow/go/v10/arrow/array"
+	"github.com/apache/arrow/go/v10/arrow/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectSchema(t *testing.T) {
+	schema := newTestProjectionSchema()
+	tests := []struct {
+		name     string
+		columns  []string
+		expected []string
+		valid    bool
+	}{
+		{
+			name:     "all",
+			columns:  nil,
+			expected: []string{"hash", "number", "receipt", "logs"},
+			valid:    true,
+		},
+		{
+			name:     "top_level",
+			columns:  []string{"number", "hash"},
+			expected: []string{"hash", "number"},
+			valid:    true,
+		},
+		{
+			name:     "nested",
+			columns:  []string{"receipt.status", "logs.address"},
+			expected: []string{"receipt", "logs"},
+			valid:    true,
+		},
+		{
+			name:     "nested_and_parent",
+			columns:  []string{"receipt.status", "receipt"},
+			expected: []string{"receipt"},
+			valid:    true,
+		},
+		{
+			name:    "empty",
+			columns: []string{""},
+			valid:   false,
+		},
+		{
+			name:    "unknown",
+			columns: []string{"unknown"},
+			valid:   false,
+		},
+		{
+			name:    "unknown_nested",
+			columns: []string{"receipt.unknown"},
+			valid:   false,
+		},
+		{
+			name:    "not_a_struct",
+			columns: []string{"hash.unknown"},
+			valid:   false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			actual, _, err := ProjectSchema(schema, test.columns)
+			if !test.valid {
+				require.Error(err)
+				return
+			}
+
+			require.NoError(err)
+			var names []string
+			for _, field := range actual.Fields() {
+				names = append(names, field.Name)
+			}
+			require.Equal(test.expected, names)
+		})
+	}
+}
+
+func TestProjectSchema_Nested(t *testing.T) {
+	require := require.New(t)
+
+	schema, _, err := ProjectSchema(newTestProjectionSchema(), []string{"receipt.status", "logs.address"})
+	require.NoError(err)
+
+	receipt := schema.Field(0).Type.(*arrow.StructType)
+	require.Equal(1, len(receipt.Fields()))
+	require.Equal("status", receipt.Field(0).Name)
+
+	logs := schema.Field(1).Type.(*arrow.ListType).Elem().(*arrow.StructType)
+	require.Equal(1, len(logs.Fields()))
+	require.Equal("address", logs.Field(0).Name)
+}
+
+func TestRecordAppender_Projection(t *testing.T) {
+	require := require.New(t)
+
+	schema, projection, err := ProjectSchema(newTestProjectionSchema(), []string{"number", "receipt.status", "logs.address"})
+	require.NoError(err)
+
+	recordBuilder := NewRecordBuilder(memory.DefaultAllocator, schema, projection)
+	defer recordBuilder.Release()
+	NewRecordAppender(recordBuilder).
+		AppendString("0xabc").
+		AppendUint64(123).
+		AppendStruct(func(sa *StructAppender) {
+			sa.AppendUint64(456).
+				AppendUint64(1)
+		}).
+		AppendList(func(la *ListAppender) {
+			la.AppendStruct(func(sa *StructAppender) {
+				sa.AppendString("0xdef").
+					AppendUint64(7)
+			})
+		}).
+		Build()
+
+	rec := recordBuilder.NewRecord()
+	defer rec.Release()
+	require.Equal(int64(1), rec.NumRows())
+	require.Equal(int64(3), rec.NumCols())
+	require.Equal(uint64(123), rec.Column(0).(*array.Uint64).Value(0))
+
+	receipt := rec.Column(1).(*array.Struct)
+	require.Equal(1, receipt.NumField())
+	require.Equal(uint64(1), receipt.Field(0).(*array.Uint64).Value(0))
+
+	logs := rec.Column(2).(*array.List).ListValues().(*array.Struct)
+	require.Equal(1, logs.Len())
+	require.Equal(1, logs.NumField())
+	require.Equal("0xdef", logs.Field(0).(*array.String).Value(0))
+}
+
+func newTestProjectionSchema() *arrow.Schema {
+	f := NewSchemaFactory()
+	return f.NewSchema(
+		f.NewField("hash", arrow.BinaryTypes.String, "The hash"),
+		f.NewField("number", arrow.PrimitiveTypes.Uint64, "The number"),
+		f.NewField("receipt", f.NewStruct(
+			f.NewField("gas_used", arrow.PrimitiveTypes.Uint64, "The gas used"),
+			f.NewField("status", arrow.PrimitiveTypes.Uint64, "The status"),
+		), "The receipt"),
+		f.NewField("logs", f.NewList(f.NewStruct(
+			f.NewField("address", arrow.BinaryTypes.String, "The address"),
+			f.NewField("log_index", arrow.PrimitiveTypes.Uint64, "The log index"),
+		)), "The logs"),
+	)
+}
@@ -7,12 +7,12 @@ import (
 
 type (
 	RecordAppender struct {
-		recordBuilder *array.RecordBuilder
+		recordBuilder *RecordBuilder
 		index         int
 	}
 )
 
-func NewRecordAppender(recordBuilder *array.RecordBuilder) *RecordAppender {
+func NewRecordAppender(recordBuilder *RecordBuilder) *RecordAppender {
 	return &RecordAppender{
 		recordBuilder: recordBuilder,
 		index:         0,
@@ -24,71 +24,119 @@ func (a *RecordAppender) Build() {
 }
 
 func (a *RecordAppender) AppendString(value string) *RecordAppender {
-	a.next().(*array.StringBuilder).Append(value)
+	if builder, _ := a.next(); builder != nil {
+		builder.(*array.StringBuilder).Append(value)
+	}
 	return a
 }
 
 func (a *RecordAppender) AppendInt32(value int32) *RecordAppender {
-	a.next().(*array.Int32Builder).Append(value)
+	if builder, _ := a.next(); builder != nil {
+		builder.(*array.Int32Builder).Append(value)
+	}
 	return a
 }
 
 func (a *RecordAppender) AppendUint32(value uint32) *RecordAppender {
-	a.next().(*array.Uint32Builder).Append(value)
+	if builder, _ := a.next(); builder != nil {
+		builder.(*array.Uint32Builder).Append(value)
+	}
 	return a
 }
 
 func (a *RecordAppender) AppendInt64(value int64) *RecordAppender {
-	a.next().(*array.Int64Builder).Append(value)
+	if builder, _ := a.next(); builder != nil {
+		builder.(*array.Int64Builder).Append(value)
+	}
 	return a
 }
 
 func (a *RecordAppender) AppendUint64(value uint64) *RecordAppender {
-	a.next().(*array.Uint64Builder).Append(value)
+	if builder, _ := a.next(); builder != nil {
+		builder.(*array.Uint64Builder).Append(value)
+	}
 	return a
 }
 
 func (a *RecordAppender) AppendFloat64(value float64) *RecordAppender {
-	a.next().(*array.Float64Builder).Append(value)
+	if builder, _ := a.next(); builder != nil {
+		builder.(*array.Float64Builder).Append(value)
+	}
 	return a
 }
 
 func (a *RecordAppender) AppendBool(value bool) *RecordAppender {
-	a.next().(*array.BooleanBuilder).Append(value)
+	if builder, _ := a.next(); builder != nil {
+		builder.(*array.BooleanBuilder).Append(value)
+	}
 	return a
 }
 
 func (a *RecordAppender) AppendDecimal128(value decimal128.Num) *RecordAppender {
-	a.next().(*array.Decimal128Builder).Append(value)
+	if builder, _ := a.next(); builder != nil {
+		builder.(*array.Decimal128Builder).Append(value)
+	}
 	return a
 }
 
 func (a *RecordAppender) AppendDecimal128Null() *RecordAppender {
-	a.next().(*array.Decimal128Builder).AppendNull()
+	if builder, _ := a.next(); builder != nil {
+		builder.(*array.Decimal128Builder).AppendNull()
+	}
+	return a
+}
+
+// AppendNull appends a null to the next field, regardless of its type.
+func (a *RecordAppender) AppendNull() *RecordAppender {
+	if builder, _ := a.next(); builder != nil {
+		builder.AppendNull()
+	}
 	return a
 }
 
 func (a *RecordAppender) AppendStruct(cb func(sa *StructAppender)) *RecordAppender {
-	sa := NewStructAppender(a.next().(*array.StructBuilder))
-	cb(sa)
-	sa.build()
+	if builder, projection := a.next(); builder != nil {
+		sa := newStructAppender(builder.(*array.StructBuilder), projection)
+		cb(sa)
+		sa.build()
+	}
 	return a
 }
 
 func (a *RecordAppender) AppendList(cb func(la *ListAppender)) *RecordAppender {
-	la := NewListAppender(a.next().(*array.ListBuilder))
-	cb(la)
-	la.build()
+	if builder, projection := a.next(); builder != nil {
+		la := newListAppender(builder.(*array.ListBuilder), projection)
+		cb(la)
+		la.build()
+	}
 	return a
 }
 
 func (a *RecordAppender) AppendBinary(value []byte) *RecordAppender {
-	a.next().(*array.BinaryBuilder).Append(value)
+	if builder, _ := a.next(); builder != nil {
+		builder.(*array.BinaryBuilder).Append(value)
+	}
 	return a
 }
 
-func (a *RecordAppender) next() array.Builder {
-	builder := a.recordBuilder.Field(a.index)
+func (a *RecordAppender) AppendMap(cb func(ma *MapAppender)) *RecordAppender {
+	if builder, _ := a.next(); builder != nil {
+		ma := NewMapAppender(builder.(*array.MapBuilder))
+		cb(ma)
+		ma.build()
+	}
+	return a
+}
+
+// next returns the builder of the next field and its nested projection,
+// or a nil builder if the field is excluded by the projection.
+func (a *RecordAppender) next() (array.Builder, *Projection) {
+	i := a.index
 	a.index += 1
-	return builder
+	index := a.recordBuilder.projection.index(i)
+	if index < 0 {
+		return nil, nil
+	}
+
+	return a.recordBuilder.Field(index), a.recordBuilder.projection.child(i)
 }
@@ -0,0 +1,25 @@
+package xarrow
+
+import (
+	"github.com/apache/arrow/go/v10/arrow"
+	"github.com/apache/arrow/go/v10/arrow/array"
+	"github.com/apache/arrow/go/v10/arrow/memory"
+)
+
+type (
+	// RecordBuilder builds records of a (possibly projected) schema.
+	// The appenders created from it skip the fields excluded by the projection.
+	RecordBuilder struct {
+		*array.RecordBuilder
+		projection *Projection
+	}
+)
+
+// NewRecordBuilder creates a RecordBuilder for the projected schema returned by ProjectSchema.
+// A nil projection builds every field of the schema.
+func NewRecordBuilder(mem memory.Allocator, schema *arrow.Schema, projection *Projection) *RecordBuilder {
+	return &RecordBuilder{
+		RecordBuilder: array.NewRecordBuilder(mem, schema),
+		projection:    projection,
+	}
+}
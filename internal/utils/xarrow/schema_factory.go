@@ -37,6 +37,10 @@ func (f SchemaFactory) NewList(dt arrow.DataType) *arrow.ListType {
 	return arrow.ListOf(dt)
 }
 
+func (f SchemaFactory) NewMap(key arrow.DataType, item arrow.DataType) *arrow.MapType {
+	return arrow.MapOf(key, item)
+}
+
 func (f SchemaFactory) newMetadata(description string) arrow.Metadata {
 	return arrow.NewMetadata(descriptionKeys, []string{description})
 }
@@ -8,81 +8,124 @@ import (
 type (
 	StructAppender struct {
 		structBuilder *array.StructBuilder
+		projection    *Projection
 		index         int
+		valid         bool
 	}
 
 	StructBuilderFn func(structBuilder *array.StructBuilder, index int)
 )
 
 func NewStructAppender(structBuilder *array.StructBuilder) *StructAppender {
+	return newStructAppender(structBuilder, nil)
+}
+
+func newStructAppender(structBuilder *array.StructBuilder, projection *Projection) *StructAppender {
 	return &StructAppender{
 		structBuilder: structBuilder,
+		projection:    projection,
 		index:         0,
 	}
 }
 
 func (a *StructAppender) AppendString(value string) *StructAppender {
-	a.next().(*array.StringBuilder).Append(value)
+	if builder, _ := a.next(); builder != nil {
+		builder.(*array.StringBuilder).Append(value)
+	}
 	return a
 }
 
 func (a *StructAppender) AppendUint32(value uint32) *StructAppender {
-	a.next().(*array.Uint32Builder).Append(value)
+	if builder, _ := a.next(); builder != nil {
+		builder.(*array.Uint32Builder).Append(value)
+	}
 	return a
 }
 
 func (a *StructAppender) AppendUint64(value uint64) *StructAppender {
-	a.next().(*array.Uint64Builder).Append(value)
+	if builder, _ := a.next(); builder != nil {
+		builder.(*array.Uint64Builder).Append(value)
+	}
 	return a
 }
 
 func (a *StructAppender) AppendFloat64(value float64) *StructAppender {
-	a.next().(*array.Float64Builder).Append(value)
+	if builder, _ := a.next(); builder != nil {
+		builder.(*array.Float64Builder).Append(value)
+	}
 	return a
 }
 
 func (a *StructAppender) AppendBool(value bool) *StructAppender {
-	a.next().(*array.BooleanBuilder).Append(value)
+	if builder, _ := a.next(); builder != nil {
+		builder.(*array.BooleanBuilder).Append(value)
+	}
 	return a
 }
 
 func (a *StructAppender) AppendDecimal128(value decimal128.Num) *StructAppender {
-	a.next().(*array.Decimal128Builder).Append(value)
+	if builder, _ := a.next(); builder != nil {
+		builder.(*array.Decimal128Builder).Append(value)
+	}
 	return a
 }
 
 func (a *StructAppender) AppendDecimal128Null() *StructAppender {
-	a.next().(*array.Decimal128Builder).AppendNull()
+	if builder, _ := a.next(); builder != nil {
+		builder.(*array.Decimal128Builder).AppendNull()
+	}
 	return a
 }
 
 func (a *StructAppender) AppendStruct(cb func(sa *StructAppender)) *StructAppender {
-	sa := NewStructAppender(a.next().(*array.StructBuilder))
-	cb(sa)
-	sa.build()
+	if builder, projection := a.next(); builder != nil {
+		sa := newStructAppender(builder.(*array.StructBuilder), projection)
+		cb(sa)
+		sa.build()
+	}
 	return a
 }
 
 func (a *StructAppender) AppendList(cb func(la *ListAppender)) *StructAppender {
-	la := NewListAppender(a.next().(*array.ListBuilder))
-	cb(la)
-	la.build()
+	if builder, projection := a.next(); builder != nil {
+		la := newListAppender(builder.(*array.ListBuilder), projection)
+		cb(la)
+		la.build()
+	}
 	return a
 }
 
-func (a *StructAppender) next() array.Builder {
-	if a.index == 0 {
-		a.structBuilder.Append(true)
+func (a *StructAppender) AppendMap(cb func(ma *MapAppender)) *StructAppender {
+	if builder, _ := a.next(); builder != nil {
+		ma := NewMapAppender(builder.(*array.MapBuilder))
+		cb(ma)
+		ma.build()
 	}
-	builder := a.structBuilder.FieldBuilder(a.index)
+	return a
+}
+
+// next returns the builder of the next field and its nested projection,
+// or a nil builder if the field is excluded by the projection.
+func (a *StructAppender) next() (array.Builder, *Projection) {
+	i := a.index
 	a.index += 1
-	return builder
+	index := a.projection.index(i)
+	if index < 0 {
+		return nil, nil
+	}
+
+	if !a.valid {
+		a.structBuilder.Append(true)
+		a.valid = true
+	}
+
+	return a.structBuilder.FieldBuilder(index), a.projection.child(i)
 }
 
 func (a *StructAppender) build() {
-	if a.index == 0 {
+	if !a.valid {
 		a.structBuilder.AppendNull()
 	}
-
 	a.index = 0
+	a.valid = false
 }
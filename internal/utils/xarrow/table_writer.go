@@ -6,38 +6,98 @@ import (
 	"github.com/apache/arrow/go/v10/arrow/flight"
 	"github.com/apache/arrow/go/v10/arrow/ipc"
 	"github.com/apache/arrow/go/v10/arrow/memory"
+	"github.com/uber-go/tally/v4"
 	"go.uber.org/zap"
 	"golang.org/x/xerrors"
 )
 
 type (
 	TableWriter interface {
-		RecordBuilder() *array.RecordBuilder
+		RecordBuilder() *RecordBuilder
 		Flush() error
 		Close() error
 	}
 
+	TableWriterOption func(options *tableWriterOptions)
+
+	tableWriterOptions struct {
+		ipcOptions []ipc.Option
+		scope      tally.Scope
+		projection *Projection
+	}
+
 	tableWriterImpl struct {
-		mem           memory.Allocator
-		logger        *zap.Logger
-		writer        *flight.Writer
-		recordBuilder *array.RecordBuilder
+		mem                      memory.Allocator
+		logger                   *zap.Logger
+		writer                   *flight.Writer
+		recordBuilder            *RecordBuilder
+		counterUncompressedBytes tally.Counter
+	}
+
+	// countingStreamWriter counts the body bytes sent over the wire,
+	// i.e. after the IPC writer has compressed the record batch buffers.
+	countingStreamWriter struct {
+		flight.DataStreamWriter
+		counterCompressedBytes tally.Counter
 	}
 
 	RecordBuilderFn func(recordBuilder *array.RecordBuilder) (bool, error)
 )
 
-func NewTableWriter(logger *zap.Logger, tableSchema *arrow.Schema, fwriter flight.DataStreamWriter) (TableWriter, error) {
+// WithLZ4 compresses the record batch buffers with LZ4 Frame.
+func WithLZ4() TableWriterOption {
+	return func(options *tableWriterOptions) {
+		options.ipcOptions = append(options.ipcOptions, ipc.WithLZ4())
+	}
+}
+
+// WithZstd compresses the record batch buffers with ZSTD.
+func WithZstd() TableWriterOption {
+	return func(options *tableWriterOptions) {
+		options.ipcOptions = append(options.ipcOptions, ipc.WithZstd())
+	}
+}
+
+// WithMetrics reports the number of compressed and uncompressed bytes written to the scope.
+func WithMetrics(scope tally.Scope) TableWriterOption {
+	return func(options *tableWriterOptions) {
+		options.scope = scope
+	}
+}
+
+// WithProjection builds the records with the projection returned by ProjectSchema.
+// The table schema passed to NewTableWriter must be the projected schema.
+func WithProjection(projection *Projection) TableWriterOption {
+	return func(options *tableWriterOptions) {
+		options.projection = projection
+	}
+}
+
+func NewTableWriter(logger *zap.Logger, tableSchema *arrow.Schema, fwriter flight.DataStreamWriter, opts ...TableWriterOption) (TableWriter, error) {
+	options := &tableWriterOptions{
+		scope: tally.NoopScope,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
 	mem := memory.DefaultAllocator
+	ipcOptions := append([]ipc.Option{ipc.WithSchema(tableSchema)}, options.ipcOptions...)
+	fwriter = &countingStreamWriter{
+		DataStreamWriter:       fwriter,
+		counterCompressedBytes: options.scope.Counter("compressed_bytes"),
+	}
+
 	return &tableWriterImpl{
-		logger:        logger,
-		mem:           mem,
-		writer:        flight.NewRecordWriter(fwriter, ipc.WithSchema(tableSchema)),
-		recordBuilder: array.NewRecordBuilder(mem, tableSchema),
+		logger:                   logger,
+		mem:                      mem,
+		writer:                   flight.NewRecordWriter(fwriter, ipcOptions...),
+		recordBuilder:            NewRecordBuilder(mem, tableSchema, options.projection),
+		counterUncompressedBytes: options.scope.Counter("uncompressed_bytes"),
 	}, nil
 }
 
-func (t *tableWriterImpl) RecordBuilder() *array.RecordBuilder {
+func (t *tableWriterImpl) RecordBuilder() *RecordBuilder {
 	return t.recordBuilder
 }
 
@@ -47,10 +107,13 @@ func (t *tableWriterImpl) Flush() error {
 		rec.Release()
 	}()
 
-	t.logger.Info("writing record", zap.Int64("rows", rec.NumRows()))
+	uncompressedBytes := RecordSize(rec)
+	t.logger.Info("writing record", zap.Int64("rows", rec.NumRows()), zap.Uint64("uncompressed_bytes", uncompressedBytes))
 	if err := t.writer.Write(rec); err != nil {
 		return xerrors.Errorf("failed to write record: %w", err)
 	}
+
+	t.counterUncompressedBytes.Inc(int64(uncompressedBytes))
 	return nil
 }
 
@@ -59,5 +122,40 @@ func (t *tableWriterImpl) Close() error {
 	if err != nil {
 		return xerrors.Errorf("failed to close flight record writer: %w", err)
 	}
+
+	return nil
+}
+
+func (w *countingStreamWriter) Send(data *flight.FlightData) error {
+	if err := w.DataStreamWriter.Send(data); err != nil {
+		return err
+	}
+
+	w.counterCompressedBytes.Inc(int64(len(data.DataBody)))
 	return nil
 }
+
+// RecordSize returns the size of the buffers of the record, i.e. its uncompressed size.
+func RecordSize(rec arrow.Record) uint64 {
+	size := uint64(0)
+	for _, column := range rec.Columns() {
+		size += arrayDataSize(column.Data())
+	}
+
+	return size
+}
+
+func arrayDataSize(data arrow.ArrayData) uint64 {
+	size := uint64(0)
+	for _, buffer := range data.Buffers() {
+		if buffer != nil {
+			size += uint64(buffer.Len())
+		}
+	}
+
+	for _, child := range data.Children() {
+		size += arrayDataSize(child)
+	}
+
+	return size
+}
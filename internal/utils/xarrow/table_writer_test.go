@@ -0,0 +1,107 @@
+package xarrow
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow/go/v10/arrow"
+	"github.com/apache/arrow/go/v10/arrow/array"
+	"github.com/apache/arrow/go/v10/arrow/flight"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally/v4"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/protobuf/proto"
+)
+
+type (
+	testStreamWriter struct {
+		data []*flight.FlightData
+	}
+
+	testStreamReader struct {
+		data  []*flight.FlightData
+		index int
+	}
+)
+
+func TestTableWriter_Compression(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []TableWriterOption
+	}{
+		{
+			name: "none",
+		},
+		{
+			name: "lz4_frame",
+			opts: []TableWriterOption{WithLZ4()},
+		},
+		{
+			name: "zstd",
+			opts: []TableWriterOption{WithZstd()},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			f := NewSchemaFactory()
+			schema := f.NewSchema(
+				f.NewField("hash", arrow.BinaryTypes.String, "The hash"),
+				f.NewField("number", arrow.PrimitiveTypes.Uint64, "The number"),
+			)
+
+			scope := tally.NewTestScope("", nil)
+			streamWriter := &testStreamWriter{}
+			opts := append(test.opts, WithMetrics(scope))
+			tableWriter, err := NewTableWriter(zaptest.NewLogger(t), schema, streamWriter, opts...)
+			require.NoError(err)
+
+			numRows := 1000
+			hash := strings.Repeat("0", 64)
+			for i := 0; i < numRows; i++ {
+				NewRecordAppender(tableWriter.RecordBuilder()).
+					AppendString(hash).
+					AppendUint64(uint64(i)).
+					Build()
+			}
+			require.NoError(tableWriter.Flush())
+			require.NoError(tableWriter.Close())
+
+			reader, err := flight.NewRecordReader(&testStreamReader{data: streamWriter.data})
+			require.NoError(err)
+			defer reader.Release()
+			require.True(reader.Next())
+			rec := reader.Record()
+			require.Equal(int64(numRows), rec.NumRows())
+			require.Equal(hash, rec.Column(0).(*array.String).Value(numRows-1))
+			require.Equal(uint64(numRows-1), rec.Column(1).(*array.Uint64).Value(numRows-1))
+
+			counters := scope.Snapshot().Counters()
+			uncompressedBytes := counters["uncompressed_bytes+"].Value()
+			compressedBytes := counters["compressed_bytes+"].Value()
+			require.Greater(uncompressedBytes, int64(0))
+			require.Greater(compressedBytes, int64(0))
+			if len(test.opts) > 0 {
+				require.Less(compressedBytes, uncompressedBytes)
+			}
+		})
+	}
+}
+
+func (w *testStreamWriter) Send(data *flight.FlightData) error {
+	// The flight writer reuses the same FlightData across payloads.
+	w.data = append(w.data, proto.Clone(data).(*flight.FlightData))
+	return nil
+}
+
+func (r *testStreamReader) Recv() (*flight.FlightData, error) {
+	if r.index >= len(r.data) {
+		return nil, io.EOF
+	}
+
+	data := r.data[r.index]
+	r.index += 1
+	return data, nil
+}